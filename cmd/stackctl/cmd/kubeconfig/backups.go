@@ -0,0 +1,181 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/kubeconfig"
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/output"
+)
+
+// NewBackupsCmd creates the backups subcommand and its children.
+func NewBackupsCmd() *cobra.Command {
+	return newBackupsCmdFunc()
+}
+
+var newBackupsCmdFunc = func() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backups",
+		Short: "Manage kubeconfig backups",
+		Long: fmt.Sprintf(`List, inspect, restore and prune the backups created before every kubeconfig change.
+
+A backup can be referenced by its number in 'backups list' (1 is the newest),
+its timestamp (20060102_150405.000000), its file name or its full path.
+
+Retention is applied automatically after each backup:
+  %s  keep at most N backups per file (default %d, 0 keeps all)
+  %s  remove backups older than this (e.g. 720h or 30d)
+  %s  store backups in this directory instead of next to the kubeconfig`,
+			kubeconfig.BackupKeepEnvVar, kubeconfig.DefaultBackupKeep,
+			kubeconfig.BackupMaxAgeEnvVar, kubeconfig.BackupDirEnvVar),
+	}
+
+	cmd.AddCommand(NewBackupsListCmd())
+	cmd.AddCommand(newBackupsShowCmd())
+	cmd.AddCommand(newBackupsDiffCmd())
+	cmd.AddCommand(newBackupsRestoreCmd())
+	cmd.AddCommand(newBackupsPruneCmd())
+	return cmd
+}
+
+// NewBackupsListCmd creates the backups list subcommand.
+func NewBackupsListCmd() *cobra.Command {
+	return newBackupsListCmdFunc()
+}
+
+var newBackupsListCmdFunc = func() *cobra.Command {
+	var out output.Options
+	cmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List kubeconfig backups, newest first",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Validate(); err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+			if out.Structured() {
+				backups, err := kubeconfig.ListBackups(kubeconfig.GetPath(), kubeconfig.BackupPolicyFromEnv())
+				if err != nil {
+					return fmt.Errorf("❌ Failed to list backups: %v", err)
+				}
+				return out.Print(cmd.OutOrStdout(), backupsListing(backups))
+			}
+			if err := kubeconfig.PrintBackups(kubeconfig.GetPath()); err != nil {
+				return fmt.Errorf("❌ Failed to list backups: %v", err)
+			}
+			return nil
+		},
+	}
+	output.AddFlags(cmd, &out)
+	return cmd
+}
+
+// backupsListing builds the -o output of backups list.
+func backupsListing(backups []kubeconfig.BackupEntry) output.Listing {
+	listing := output.Listing{
+		Items:   backups,
+		Columns: []string{"#", "CREATED", "SIZE", "PATH"},
+		Names:   []string{},
+	}
+	if backups == nil {
+		listing.Items = []kubeconfig.BackupEntry{}
+	}
+	for i, b := range backups {
+		listing.Rows = append(listing.Rows, []string{strconv.Itoa(i + 1), b.Time.Format(time.RFC3339), strconv.FormatInt(b.Size, 10), b.Path})
+		listing.Names = append(listing.Names, b.Path)
+	}
+	return listing
+}
+
+func newBackupsShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "show [backup]",
+		Short:        "Print the content of a backup",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := kubeconfig.ShowBackup(kubeconfig.GetPath(), args[0]); err != nil {
+				return fmt.Errorf("❌ Failed to show backup: %v", err)
+			}
+			return nil
+		},
+	}
+}
+
+func newBackupsDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "diff [backup]",
+		Short:        "Show the changes between a backup and the current kubeconfig",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := kubeconfig.DiffBackup(kubeconfig.GetPath(), args[0]); err != nil {
+				return fmt.Errorf("❌ Failed to diff backup: %v", err)
+			}
+			return nil
+		},
+	}
+}
+
+func newBackupsRestoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "restore [backup]",
+		Short:        "Restore a backup over the kubeconfig it was taken from",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backup, err := kubeconfig.RestoreBackup(kubeconfig.GetPath(), args[0])
+			if err != nil {
+				return fmt.Errorf("❌ Failed to restore backup: %v", err)
+			}
+			log.Infof("✅ Restored %s from %s", backup.Source, backup.Path)
+			return nil
+		},
+	}
+}
+
+func newBackupsPruneCmd() *cobra.Command {
+	var (
+		keep   int
+		maxAge string
+	)
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove backups outside the retention policy",
+		Long: `Remove the backups outside the retention policy, including the
+config.backup.<timestamp> files of earlier versions that automatic retention
+leaves alone.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy := kubeconfig.BackupPolicyFromEnv()
+			policy.IncludeLegacy = true
+			if cmd.Flags().Changed("keep") {
+				policy.Keep = keep
+			}
+			if maxAge != "" {
+				age, err := kubeconfig.ParseAge(maxAge)
+				if err != nil {
+					return fmt.Errorf("❌ %v", err)
+				}
+				policy.MaxAge = age
+			}
+
+			removed, err := kubeconfig.PruneBackups(kubeconfig.GetPath(), policy)
+			for _, path := range removed {
+				log.Infof("🗑️ Removed %s", path)
+			}
+			if err != nil {
+				return fmt.Errorf("❌ Failed to prune backups: %v", err)
+			}
+			log.Infof("✅ Removed %d backup(s)", len(removed))
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&keep, "keep", kubeconfig.DefaultBackupKeep, "Number of backups to keep per kubeconfig file (0 keeps all)")
+	cmd.Flags().StringVar(&maxAge, "max-age", "", "Remove backups older than this (e.g. 720h or 30d)")
+	return cmd
+}
@@ -1,17 +1,22 @@
 package kubeconfig
 
 import (
-	"encoding/base64"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/eliasmeireles/stackctl/cmd/stackctl/cmd/cmd"
 	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/kubeconfig"
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/sshclient"
 	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/vault/flags"
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/output"
 )
 
 const (
@@ -24,6 +29,13 @@ const (
 	CategoryAddFromVault          = "K8s Config/Add Configuration/From Vault"
 	CategorySaveToVault           = "K8s Config/Save to Vault"
 	CategoryClustersConfiguration = "K8s Config/Clusters configuration"
+	CategoryImportMissing         = "K8s Config/Contexts/" + importMissingChoice
+	CategoryBackups               = "K8s Config/Backups"
+	CategoryInspectContext        = "K8s Config/Inspect Context"
+	CategoryRenameContext         = "K8s Config/Rename Context"
+	CategorySyncVault             = "K8s Config/Sync with Vault"
+	CategoryPrune                 = "K8s Config/Prune"
+	CategoryLint                  = "K8s Config/Lint"
 )
 
 func init() {
@@ -36,6 +48,13 @@ func init() {
 	cmd.Add(cmd.NewDefault(NewAddFromVaultCmd(), CategoryAddFromVault))
 	cmd.Add(cmd.NewDefault(NewSaveToVaultCmd(), CategorySaveToVault))
 	cmd.Add(cmd.NewDefault(NewListRemoteCmd(), CategoryClustersConfiguration))
+	cmd.Add(cmd.NewDefault(newImportMissingCmd(), CategoryImportMissing))
+	cmd.Add(cmd.NewDefault(NewBackupsListCmd(), CategoryBackups))
+	cmd.Add(cmd.NewDefault(NewInspectCmd(), CategoryInspectContext))
+	cmd.Add(cmd.NewDefault(NewRenameCmd(), CategoryRenameContext))
+	cmd.Add(cmd.NewDefault(NewSyncCmd(), CategorySyncVault))
+	cmd.Add(cmd.NewDefault(NewPruneCmd(), CategoryPrune))
+	cmd.Add(cmd.NewDefault(NewLintCmd(), CategoryLint))
 }
 
 // NewCommand creates the main config command and its subcommands.
@@ -52,11 +71,21 @@ func NewCommand() *cobra.Command {
 	configCmd.AddCommand(NewSetNamespaceCmd())
 	configCmd.AddCommand(NewAddCmd())
 	configCmd.AddCommand(NewRemoveCmd())
+	configCmd.AddCommand(NewPruneCmd())
+	configCmd.AddCommand(NewLintCmd())
+	configCmd.AddCommand(NewRenameCmd())
+	configCmd.AddCommand(NewBackupsCmd())
+	configCmd.AddCommand(NewInspectCmd())
 
 	// Add vault commands
 	configCmd.AddCommand(NewAddFromVaultCmd())
 	configCmd.AddCommand(NewSaveToVaultCmd())
 	configCmd.AddCommand(NewListRemoteCmd())
+	configCmd.AddCommand(NewSyncCmd())
+	configCmd.AddCommand(NewRemoteCmd())
+	configCmd.AddCommand(NewExecCmd())
+	configCmd.AddCommand(NewCredentialCmd())
+	configCmd.AddCommand(NewAddDynamicCmd())
 
 	return configCmd
 }
@@ -67,18 +96,49 @@ func NewListContextsCmd() *cobra.Command {
 }
 
 var newListContextsCmdFunc = func() *cobra.Command {
-	return &cobra.Command{
+	var out output.Options
+	cmd := &cobra.Command{
 		Use:          "list-contexts",
 		Short:        "List available contexts",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Validate(); err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
 			kubeconfigPath := kubeconfig.GetPath()
+			if out.Structured() {
+				summaries, err := kubeconfig.ContextSummaries(kubeconfigPath)
+				if err != nil {
+					return fmt.Errorf("❌ Failed to list contexts: %v", err)
+				}
+				return out.Print(cmd.OutOrStdout(), contextsListing(summaries))
+			}
 			if err := kubeconfig.ListContexts(kubeconfigPath); err != nil {
 				return fmt.Errorf("❌ Failed to list contexts: %v", err)
 			}
 			return nil
 		},
 	}
+	output.AddFlags(cmd, &out)
+	return cmd
+}
+
+// contextsListing builds the -o output of list-contexts.
+func contextsListing(summaries []kubeconfig.ContextSummary) output.Listing {
+	listing := output.Listing{
+		Items:   summaries,
+		Columns: []string{"CURRENT", "NAME", "CLUSTER", "USER", "NAMESPACE", "SERVER"},
+		Names:   []string{},
+	}
+	for _, s := range summaries {
+		current := ""
+		if s.Current {
+			current = "*"
+		}
+		listing.Rows = append(listing.Rows, []string{current, s.Name, s.Cluster, s.User, s.Namespace, s.Server})
+		listing.Names = append(listing.Names, s.Name)
+	}
+	return listing
 }
 
 // NewCleanCmd creates the clean subcommand.
@@ -87,18 +147,48 @@ func NewCleanCmd() *cobra.Command {
 }
 
 var newCleanCmdFunc = func() *cobra.Command {
-	return &cobra.Command{
-		Use:          "clean",
-		Short:        "Clean duplicate entries from kubeconfig",
+	var (
+		opts       kubeconfig.CleanOptions
+		onConflict string
+	)
+	cmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Clean duplicate entries from kubeconfig",
+		Long: `Remove clusters, contexts and users defined more than once.
+
+Identical definitions are dropped. Definitions sharing a name but not their
+content are shown side by side and, for each one, you pick the definition to
+keep or rename the others to "<name>-N" so nothing is lost. With --yes the
+conflicts are renamed (or resolved with --on-conflict) without prompting.`,
+		Args:         cobra.NoArgs,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			switch kubeconfig.ConflictStrategy(onConflict) {
+			case "", kubeconfig.ConflictRename, kubeconfig.ConflictKeep, kubeconfig.ConflictPrompt:
+				opts.OnConflict = kubeconfig.ConflictStrategy(onConflict)
+			default:
+				return fmt.Errorf("❌ Error: invalid --on-conflict %q (expected rename, keep or prompt)", onConflict)
+			}
 			kubeconfigPath := kubeconfig.GetPath()
-			if err := kubeconfig.CleanDuplicates(kubeconfigPath); err != nil {
+			if err := CleanDuplicates(kubeconfigPath, opts); err != nil {
 				return fmt.Errorf("❌ Failed to clean kubeconfig: %v", err)
 			}
 			return nil
 		},
 	}
+	cmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Clean without prompting")
+	cmd.Flags().StringVar(&onConflict, "on-conflict", "",
+		"How to resolve names defined with different content: rename, keep (the first) or prompt (default: prompt, rename with --yes)")
+	return cmd
+}
+
+// CleanDuplicates removes the duplicate entries of the kubeconfig at path.
+func CleanDuplicates(path string, opts kubeconfig.CleanOptions) error {
+	return cleanDuplicatesFunc(path, opts)
+}
+
+var cleanDuplicatesFunc = func(path string, opts kubeconfig.CleanOptions) error {
+	return kubeconfig.CleanDuplicates(path, opts)
 }
 
 // NewGetContextCmd creates the get-context subcommand.
@@ -107,7 +197,7 @@ func NewGetContextCmd() *cobra.Command {
 }
 
 var newGetContextCmdFunc = func() *cobra.Command {
-	var encodeFlag bool
+	var encodeFlag, flattenFlag bool
 	cmd := &cobra.Command{
 		Use:          "get-context [context-name]",
 		Short:        "Get configuration for a specific context",
@@ -119,13 +209,14 @@ var newGetContextCmdFunc = func() *cobra.Command {
 			}
 			contextName := args[0]
 			kubeconfigPath := kubeconfig.GetPath()
-			if err := kubeconfig.GetContextConfig(kubeconfigPath, contextName, encodeFlag); err != nil {
+			if err := kubeconfig.GetContextConfig(kubeconfigPath, contextName, encodeFlag, flattenFlag); err != nil {
 				return fmt.Errorf("❌ Failed to get context config: %v", err)
 			}
 			return nil
 		},
 	}
 	cmd.Flags().BoolVar(&encodeFlag, "encode", false, "Encode output in base64")
+	cmd.Flags().BoolVar(&flattenFlag, "flatten", false, "Inline referenced certificate and key files as *-data fields")
 	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) != 0 {
 			return nil, cobra.ShellCompDirectiveNoFileComp
@@ -225,6 +316,13 @@ const (
 	sshUserFlag = "ssh-user"
 )
 
+// readRemoteFile and runRemoteCommand fetch a kubeconfig over SSH; replaced
+// in tests.
+var (
+	readRemoteFile   = sshclient.ReadFile
+	runRemoteCommand = sshclient.Output
+)
+
 var newAddCmdFunc = func() *cobra.Command {
 	var (
 		importFile   string
@@ -232,19 +330,43 @@ var newAddCmdFunc = func() *cobra.Command {
 		sshHost      string
 		remoteFile   string
 		isK3s        bool
+		distroName   string
 		resourceName string
+		server       kubeconfig.ServerRewrite
+		remote       sshclient.Config
+		imports      = importFlags{defaultValidation: kubeconfig.ValidateWarn}
 	)
 	cmd := &cobra.Command{
-		Use:   "add [base64-config]",
+		Use:   "add [base64-config|-]",
 		Short: "Add kubeconfig from various sources (base64, file, SCP, or k3s)",
 		Long: `Add a Kubernetes configuration to your local kubeconfig from multiple sources.
 
 Sources:
   1. Base64 string: Pass the encoded content as the first argument.
   2. File: Use the --file flag to specify a local path.
-  3. SSH Cat: Use --remote-file with --host to vaultFetch from a remote VPS.
-  4. Separate SSH: Use --host and --ssh-user (optional) along with --remote-file or --k3s.
-  5. k3s: Use --k3s and --host to automatically vaultFetch /etc/rancher/k3s/k3s.yaml from a remote VPS.
+  3. Stdin: Pass - as the argument to read the config from stdin.
+  4. SSH Cat: Use --remote-file with --host to vaultFetch from a remote VPS.
+  5. Separate SSH: Use --host and --ssh-user (optional) along with --remote-file or --k3s.
+  6. k3s: Use --k3s and --host to automatically vaultFetch /etc/rancher/k3s/k3s.yaml from a remote VPS.
+  7. Distribution: Use --distro (k3s, rke2, microk8s, kubeadm, k0s, kind) and --host to
+     fetch the admin kubeconfig of that distribution. The context is named
+     <distro>-<host> unless -r is given, and sudo is used unless connecting as root.
+
+Configs are accepted as raw YAML or base64, whichever is given. For --file
+and stdin, files referenced by certificate-authority, client-certificate and
+client-key are inlined as *-data fields so the imported entries stay
+portable; relative paths are resolved against the directory of --file.
+References of remote configs and base64 arguments are kept as they are,
+since they name files on another machine.
+
+Remote files are read with a built-in SSH client: keys come from ssh-agent,
+--identity-file or the default ~/.ssh keys, and the host must be listed in
+known_hosts. --jump-host connects through a bastion and --sudo reads
+root-only files.
+
+Loopback server addresses (127.0.0.1, localhost, ::1, 0.0.0.0) are rewritten to
+--host. Use --server or --server-port to override the address, and
+--tls-server-name when the certificate does not include the new address.
 
 Examples:
   # Add via SSH with specific path
@@ -253,65 +375,120 @@ Examples:
   # Add from a remote k3s installation
   stackctl kubeconfig add --k3s --host 1.2.3.4 --ssh-user root
 
+  # Add from a remote RKE2 or microk8s node
+  stackctl kubeconfig add --distro rke2 --host 10.0.0.7 --ssh-user ubuntu
+  stackctl kubeconfig add --distro microk8s --host 10.0.0.8 --ssh-user ubuntu
+
+  # Through a bastion, reading the root-only k3s config with sudo
+  stackctl kubeconfig add --k3s --host 10.0.0.5 --ssh-user ubuntu --sudo --jump-host bastion.example.com
+
   # Add from a remote file specifying path
   stackctl kubeconfig add --host 1.2.3.4 --ssh-user root --remote-file /root/.kube/config
 
   # Add from a local file
   stackctl kubeconfig add --file ./new-config.yaml
+
+  # Add from stdin, raw YAML or base64
+  kind get kubeconfig --name dev | stackctl kubeconfig add - -r kind-dev
+
+  # Reach the cluster through a DNS name the certificate was not issued for
+  stackctl kubeconfig add --k3s --host k3s.example.com --tls-server-name 127.0.0.1
 `,
-		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var configStr string
+			opts, err := imports.options(resourceName)
+			if err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
 
-			if isK3s || remoteFile != "" {
+			var data []byte
+
+			if isK3s {
+				distroName = "k3s"
+			}
+			var distro *kubeconfig.Distro
+			if distroName != "" {
+				d, err := kubeconfig.LookupDistro(distroName)
+				if err != nil {
+					return fmt.Errorf("❌ %v", err)
+				}
+				distro = &d
+			}
+
+			if distro != nil || remoteFile != "" {
 				if sshHost == "" {
 					return fmt.Errorf("❌ Error: --host is required for remote fetching")
 				}
 
-				targetPath := remoteFile
-				if isK3s {
-					targetPath = "/etc/rancher/k3s/k3s.yaml"
+				remote.Host = sshHost
+				remote.User = sshUser
+				target, err := remote.Target()
+				if err != nil {
+					return fmt.Errorf("❌ Invalid --host: %v", err)
 				}
-
-				hostArg := sshHost
-				if sshUser != "" {
-					hostArg = fmt.Sprintf("%s@%s", sshUser, sshHost)
+				if distro != nil && distro.RequiresRoot && !cmd.Flags().Changed("sudo") {
+					remote.Sudo = target.User != "root"
 				}
 
-				log.Infof("🚀 Fetching config from remote via SSH (%s): %s", hostArg, targetPath)
-
-				sshCmd := exec.Command("ssh", hostArg, fmt.Sprintf("cat %s", targetPath))
-				sshCmd.Stderr = os.Stderr
-				sshCmd.Stdin = os.Stdin
-
-				content, err := sshCmd.Output()
+				var content []byte
+				switch {
+				case remoteFile != "" || distro.Command == "":
+					targetPath := remoteFile
+					if targetPath == "" {
+						targetPath = distro.Path
+					}
+					log.Infof("🚀 Fetching config from remote via SSH (%s): %s", sshHost, targetPath)
+					content, err = readRemoteFile(remote, targetPath)
+				default:
+					log.Infof("🚀 Fetching config from remote via SSH (%s): %s", sshHost, distro.Command)
+					content, err = runRemoteCommand(remote, distro.Command)
+				}
 				if err != nil {
-					return fmt.Errorf("❌ SSH command failed: %v", err)
+					return fmt.Errorf("❌ SSH fetch failed: %v", err)
+				}
+				if distro != nil && opts.Name == "" {
+					opts.Name = distro.ContextName(target.Host)
 				}
-				configStr = base64.StdEncoding.EncodeToString(content)
+				data = content
 			} else if importFile != "" {
 				log.Infof("📂 Reading config from file: %s", importFile)
 				content, err := os.ReadFile(importFile)
 				if err != nil {
 					return fmt.Errorf("❌ Failed to read file: %v", err)
 				}
-				configStr = base64.StdEncoding.EncodeToString(content)
+				data = content
+				opts.Flatten = true
+				opts.BaseDir = filepath.Dir(importFile)
 			} else {
 				if len(args) == 0 {
 					_ = cmd.Help()
 					return fmt.Errorf("❌ Error: Valid base64 config argument, --file or --scp flag required")
 				}
-				configStr = args[0]
+				if args[0] == "-" {
+					log.Info("📥 Reading config from stdin")
+					content, err := io.ReadAll(cmd.InOrStdin())
+					if err != nil {
+						return fmt.Errorf("❌ Failed to read stdin: %v", err)
+					}
+					data = content
+					opts.Flatten = true
+				} else {
+					data = []byte(args[0])
+				}
 			}
 
-			name := ""
-			if resourceName != "" {
-				name = resourceName
+			if sshHost != "" && (distro == nil || distro.RewriteLoopback) {
+				target, err := sshclient.ParseTarget(sshHost, "", 0)
+				if err != nil {
+					return fmt.Errorf("❌ Invalid --host: %v", err)
+				}
+				server.Host = target.Host
 			}
-			if name != "" {
-				log.Infof("Processing add with resource name: %s", name)
+			opts.Server = server
+
+			if opts.Name != "" {
+				log.Infof("Processing add with resource name: %s", opts.Name)
 			}
-			if err := kubeconfig.ProcessConfig(configStr, name); err != nil {
+			if err := kubeconfig.ProcessConfigData(data, opts); err != nil {
 				return fmt.Errorf("❌ %v", err)
 			}
 			return nil
@@ -321,13 +498,29 @@ Examples:
 	cmd.Flags().StringVarP(&importFile, "file", "f", "", "Path to kubeconfig file to add")
 	cmd.Flags().StringVar(&sshHost, "host", "", "Remote VPS host address")
 	cmd.Flags().StringVar(&sshUser, sshUserFlag, "", "SSH user for remote connection")
+	cmd.Flags().IntVar(&remote.Port, "ssh-port", 0, "SSH port of the remote host (default 22)")
+	cmd.Flags().StringVar(&remote.IdentityFile, "identity-file", "", "Private key used for SSH authentication (ssh-agent keys are also tried)")
+	cmd.Flags().StringVar(&remote.KnownHostsFile, "known-hosts", "", "known_hosts file used to verify the remote host (default ~/.ssh/known_hosts)")
+	cmd.Flags().StringVar(&remote.JumpHost, "jump-host", "", "Bastion to connect through, as [user@]host[:port]")
+	cmd.Flags().BoolVar(&remote.Sudo, "sudo", false, "Read the remote file with sudo (e.g. root-only k3s.yaml)")
 	cmd.Flags().StringVar(&remoteFile, "remote-file", "", "Remote path to kubeconfig file")
-	cmd.Flags().BoolVar(&isK3s, "k3s", false, "Fetch default k3s config path (/etc/rancher/k3s/k3s.yaml)")
+	cmd.Flags().BoolVar(&isK3s, "k3s", false, "Fetch default k3s config path (/etc/rancher/k3s/k3s.yaml); same as --distro k3s")
+	cmd.Flags().StringVar(&distroName, "distro", "", "Fetch the admin kubeconfig of a distribution ("+strings.Join(kubeconfig.DistroNames(), ", ")+")")
+	_ = cmd.RegisterFlagCompletionFunc("distro", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return kubeconfig.DistroNames(), cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().StringVar(&server.Server, "server", "", "Override the server URL of the imported clusters")
+	cmd.Flags().IntVar(&server.Port, "server-port", 0, "Override the server port of the imported clusters")
+	cmd.Flags().StringVar(&server.TLSServerName, "tls-server-name", "", "Server name used to verify the cluster certificate")
+	imports.register(cmd)
 
 	// Adding support for TUI execution (run.Command.Execute)
 	// The Execute logic of run.NewDefault calls cmd.Run(cmd, choice)
 	// We need Run to handle when choice[0] is the import mode
 
+	// TUI choices carry their prompt answers as extra args, so the argument
+	// count is checked here rather than by cobra.
+	cmd.Args = cobra.ArbitraryArgs
 	originalRunE := cmd.RunE
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		if len(args) > 0 {
@@ -358,7 +551,9 @@ Examples:
 					return originalRunE(cmd, []string{})
 				}
 				return nil
-			case "From Remote k3s":
+			}
+
+			if distro, ok := distroFromChoice(choice); ok {
 				if len(remainingArgs) >= 2 {
 					sshUser := remainingArgs[1]
 					if sshUser == "" {
@@ -366,12 +561,15 @@ Examples:
 					}
 					_ = cmd.Flags().Set("host", remainingArgs[0])
 					_ = cmd.Flags().Set(sshUserFlag, sshUser)
-					_ = cmd.Flags().Set("k3s", "true")
+					_ = cmd.Flags().Set("distro", distro.Name)
 					return originalRunE(cmd, []string{})
 				}
 				return nil
 			}
 		}
+		if len(args) > 1 {
+			return fmt.Errorf("❌ Error: accepts at most 1 arg(s), received %d", len(args))
+		}
 		return originalRunE(cmd, args)
 	}
 
@@ -384,9 +582,14 @@ func NewRemoveCmd() *cobra.Command {
 }
 
 var newRemoveCmdFunc = func() *cobra.Command {
-	return &cobra.Command{
-		Use:          "remove [context-name]",
-		Short:        "Remove a context and its associated data from kubeconfig",
+	var keepLease bool
+	cmd := &cobra.Command{
+		Use:   "remove [context-name]",
+		Short: "Remove a context and its associated data from kubeconfig",
+		Long: `Remove a context and its associated data from kubeconfig.
+
+A context added with 'add-dynamic' has its Vault lease revoked first, unless
+--keep-lease is set or the lease has already expired.`,
 		Args:         cobra.ExactArgs(1),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -395,6 +598,9 @@ var newRemoveCmdFunc = func() *cobra.Command {
 			}
 			contextName := args[0]
 			kubeconfigPath := kubeconfig.GetPath()
+			if !keepLease {
+				revokeContextLease(kubeconfigPath, contextName)
+			}
 			if err := kubeconfig.RemoveConfig(kubeconfigPath, contextName); err != nil {
 				return fmt.Errorf("❌ Failed to remove config: %v", err)
 			}
@@ -402,6 +608,59 @@ var newRemoveCmdFunc = func() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&keepLease, "keep-lease", false, "Do not revoke the Vault lease of a dynamic credential")
+	flags.SharedFlags(cmd)
+	return cmd
+}
+
+// revokeContextLease revokes the live Vault lease of contextName, if any. A
+// failure only warns so the context can still be removed.
+func revokeContextLease(kubeconfigPath, contextName string) {
+	lease, err := kubeconfig.LeaseOfContext(kubeconfigPath, contextName)
+	if err != nil || lease == nil || lease.Expired(time.Now()) {
+		return
+	}
+	if err := RevokeLease(lease.LeaseID); err != nil {
+		log.Warnf("⚠️  Failed to revoke Vault lease %s, it expires at %s: %v", lease.LeaseID, lease.Expires.Format(time.RFC3339), err)
+	}
+}
+
+// NewRenameCmd creates the rename subcommand.
+func NewRenameCmd() *cobra.Command {
+	return newRenameCmdFunc()
+}
+
+var newRenameCmdFunc = func() *cobra.Command {
+	var opts kubeconfig.RenameOptions
+	cmd := &cobra.Command{
+		Use:   "rename [old-name] [new-name]",
+		Short: "Rename a context and optionally its cluster and user",
+		Long: `Rename a context. With --cluster and --user the cluster and user it
+references are renamed to the new name as well, and every context using them is
+updated. current-context follows the rename and existing names are never
+overwritten.`,
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := kubeconfig.RenameContext(kubeconfig.GetPath(), args[0], args[1], opts); err != nil {
+				return fmt.Errorf("❌ Failed to rename context: %v", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&opts.Cluster, "cluster", false, "Also rename the cluster referenced by the context")
+	cmd.Flags().BoolVar(&opts.User, "user", false, "Also rename the user referenced by the context")
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		contexts, err := kubeconfig.GetContextNames(kubeconfig.GetPath())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return contexts, cobra.ShellCompDirectiveNoFileComp
+	}
+	return cmd
 }
 
 // NewAddFromVaultCmd creates the add-from-vault subcommand.
@@ -410,69 +669,285 @@ func NewAddFromVaultCmd() *cobra.Command {
 }
 
 var newAddFromVaultCmdFunc = func() *cobra.Command {
+	var (
+		imports      = importFlags{defaultValidation: kubeconfig.ValidateSkip}
+		bulk         kubeconfig.BulkImportOptions
+		selectorFlag string
+	)
 	cmd := &cobra.Command{
-		Use:          "add-from-vault [path]",
-		Short:        "Add kubeconfig from Vault",
+		Use:   "add-from-vault [path|name]",
+		Short: "Add kubeconfig from Vault",
+		Long: `Fetch a kubeconfig from Vault and merge it into the local one. A bare
+secret name is looked up under the configured storage layout
+(--vault-mount, --vault-base-path and --vault-field).
+
+--all, --match (a glob on secret names) and --selector (tags) import several
+secrets at once: they are fetched concurrently and merged in a single write,
+with a single backup. --missing skips the secrets already present locally.
+Single-context secrets are renamed after the secret; bundles keep their
+names.
+
+Examples:
+  stackctl kubeconfig add-from-vault home-lab
+  stackctl kubeconfig add-from-vault --match 'prod-*'
+  stackctl kubeconfig add-from-vault --selector env=staging --missing`,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if selectorFlag != "" {
+				selector, err := kubeconfig.ParseSelector(selectorFlag)
+				if err != nil {
+					return fmt.Errorf("❌ %v", err)
+				}
+				bulk.Selector = selector
+			}
+
+			if bulk.All || bulk.Match != "" || len(bulk.Selector) > 0 {
+				if len(args) > 0 {
+					return fmt.Errorf("❌ Error: a vault path cannot be combined with --all, --match or --selector")
+				}
+				opts, err := imports.options("")
+				if err != nil {
+					return fmt.Errorf("❌ %v", err)
+				}
+				bulk.Import = opts
+				return importContexts(bulk)
+			}
+			if bulk.Missing {
+				return fmt.Errorf("❌ Error: --missing requires --all, --match or --selector")
+			}
+
 			if len(args) == 0 {
 				return fmt.Errorf("❌ Error: vault path is required")
 			}
 			dataPath := args[0]
-			VaultGet(dataPath)
+			opts, err := imports.options(deriveResourceName(dataPath))
+			if err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+			if err := VaultGet(dataPath, opts); err != nil {
+				return fmt.Errorf("❌ Failed to fetch kubeconfig from Vault: %v", err)
+			}
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&bulk.All, "all", false, "Import every kubeconfig stored in Vault")
+	cmd.Flags().StringVar(&bulk.Match, "match", "", "Import the secrets whose name matches this glob (e.g. 'prod-*')")
+	cmd.Flags().StringVarP(&selectorFlag, "selector", "l", "", "Import the secrets whose tags match (e.g. env=staging)")
+	cmd.Flags().BoolVar(&bulk.Missing, "missing", false, "Skip the secrets whose contexts already exist locally")
+	imports.register(cmd)
 	flags.SharedFlags(cmd)
+	vaultLayoutFlags(cmd)
 	return cmd
 }
 
+// newImportMissingCmd handles the TUI entry Contexts → Import all missing. It
+// is not part of the CLI, where add-from-vault --all --missing does the same.
+func newImportMissingCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "import-missing",
+		Short:        "Import every stored kubeconfig without a local context",
+		Args:         cobra.ArbitraryArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return importContexts(kubeconfig.BulkImportOptions{All: true, Missing: true})
+		},
+	}
+}
+
+// importContexts runs a bulk add-from-vault and prints its summary.
+func importContexts(opts kubeconfig.BulkImportOptions) error {
+	report, err := ImportContextsFromVault(opts)
+	if err != nil {
+		return fmt.Errorf("❌ Failed to import kubeconfigs from Vault: %v", err)
+	}
+	report.Print(os.Stdout)
+	if failed := report.Count(kubeconfig.ImportFailed); failed > 0 {
+		return fmt.Errorf("❌ %d of %d kubeconfigs could not be imported", failed, len(report.Results))
+	}
+	return nil
+}
+
 // NewSaveToVaultCmd creates the save-to-vault subcommand.
 func NewSaveToVaultCmd() *cobra.Command {
 	return newSaveToVaultCmdFunc()
 }
 
 var newSaveToVaultCmdFunc = func() *cobra.Command {
+	var (
+		tags         []string
+		description  string
+		asExecPlugin bool
+		bulk         kubeconfig.BulkSaveOptions
+	)
 	cmd := &cobra.Command{
-		Use:          "save-to-vault [context-name]",
-		Short:        "LocalContext local context to Vault",
+		Use:   "save-to-vault [context-name]",
+		Short: "LocalContext local context to Vault",
+		Long: `Save a local context to Vault under its name.
+
+--all, --contexts or --selector (a regular expression matched against context
+names) save several contexts at once, each as its own secret, written
+concurrently. --bundle stores them together in a single secret instead.
+Failed writes are reported per context and make the command fail after the
+others are done.
+
+--tag and --description are stored as KV v2 custom metadata, shown by
+'contexts' and usable with --selector. Tags not mentioned are kept; --tag key-
+removes one.
+
+--as-exec-plugin then removes the token or client key from the local user and
+makes kubectl call 'stackctl kubeconfig credential' instead, so the
+credentials only live in Vault.
+
+Examples:
+  stackctl kubeconfig save-to-vault home-lab --tag env=prod --tag owner=platform --description "Home lab k3s"
+  stackctl kubeconfig save-to-vault prod --as-exec-plugin
+  stackctl kubeconfig save-to-vault --all --tag team=platform
+  stackctl kubeconfig save-to-vault --selector '^prod-' --bundle prod-clusters`,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			set, remove, err := kubeconfig.ParseTags(tags)
+			if err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+			saveOpts := kubeconfig.SaveOptions{Tags: set, RemoveTags: remove, Description: description}
+
+			if bulk.All || len(bulk.Contexts) > 0 || bulk.Selector != "" {
+				if len(args) > 0 {
+					return fmt.Errorf("❌ Error: a context name cannot be combined with --all, --contexts or --selector")
+				}
+				if asExecPlugin && bulk.Bundle != "" {
+					return fmt.Errorf("❌ Error: --as-exec-plugin cannot be combined with --bundle")
+				}
+				bulk.Save = saveOpts
+				return saveContexts(bulk, asExecPlugin)
+			}
+			if bulk.Bundle != "" {
+				return fmt.Errorf("❌ Error: --bundle requires --all, --contexts or --selector")
+			}
+
 			if len(args) == 0 {
 				return fmt.Errorf("❌ Error: context name is required")
 			}
 			contextName := args[0]
-			SaveToVault(contextName)
+			if err := SaveToVault(contextName, saveOpts); err != nil {
+				return fmt.Errorf("❌ Failed to save context '%s' to Vault: %v", contextName, err)
+			}
+			if asExecPlugin {
+				if err := UseExecPlugin(contextName); err != nil {
+					return fmt.Errorf("❌ Failed to switch '%s' to the credential plugin: %v", contextName, err)
+				}
+			}
 			return nil
 		},
 	}
+	cmd.Flags().StringArrayVar(&tags, "tag", nil, "Tag the stored kubeconfig (key=value, repeatable; key- removes the tag)")
+	cmd.Flags().StringVar(&description, "description", "", "Description of the stored kubeconfig")
+	cmd.Flags().BoolVar(&asExecPlugin, "as-exec-plugin", false, "Replace the local static credentials with an exec entry that reads them from Vault")
+	cmd.Flags().BoolVar(&bulk.All, "all", false, "Save every local context")
+	cmd.Flags().StringSliceVar(&bulk.Contexts, "contexts", nil, "Save the given contexts (comma separated)")
+	cmd.Flags().StringVar(&bulk.Selector, "selector", "", "Save the contexts whose name matches this regular expression")
+	cmd.Flags().StringVar(&bulk.Bundle, "bundle", "", "Save the selected contexts together as a single secret with this name")
+	cmd.MarkFlagsMutuallyExclusive("all", "contexts", "selector")
+	_ = cmd.RegisterFlagCompletionFunc("contexts", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		contexts, err := kubeconfig.GetContextNames(kubeconfig.GetPath())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return contexts, cobra.ShellCompDirectiveNoFileComp
+	})
 	flags.SharedFlags(cmd)
+	vaultLayoutFlags(cmd)
 	return cmd
 }
 
+// saveContexts runs a bulk save-to-vault and prints its summary.
+func saveContexts(opts kubeconfig.BulkSaveOptions, asExecPlugin bool) error {
+	report, err := SaveContextsToVault(opts)
+	if err != nil {
+		return fmt.Errorf("❌ Failed to save contexts to Vault: %v", err)
+	}
+	report.Print(os.Stdout)
+
+	if asExecPlugin {
+		for _, res := range report.Results {
+			if res.Err != nil {
+				continue
+			}
+			if err := UseExecPlugin(res.Secret); err != nil {
+				return fmt.Errorf("❌ Failed to switch '%s' to the credential plugin: %v", res.Secret, err)
+			}
+		}
+	}
+	if failed := report.Failed(); failed > 0 {
+		return fmt.Errorf("❌ %d of %d secrets could not be saved to Vault", failed, len(report.Results))
+	}
+	return nil
+}
+
 // NewListRemoteCmd creates the contexts subcommand.
 func NewListRemoteCmd() *cobra.Command {
 	return newListRemoteCmdFunc()
 }
 
 var newListRemoteCmdFunc = func() *cobra.Command {
+	var (
+		selectorFlag string
+		out          output.Options
+	)
 	cmd := &cobra.Command{
 		Use:          "contexts",
 		Short:        "List kubeconfig contexts stored in Vault",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			items, err := VaultContexts()
+			selector, err := kubeconfig.ParseSelector(selectorFlag)
+			if err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+			if err := out.Validate(); err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+			remotes, err := VaultRemotes(selector)
 			if err != nil {
 				return err
 			}
+			if out.Structured() {
+				return out.Print(cmd.OutOrStdout(), remotesListing(remotes))
+			}
 
+			if len(remotes) == 0 {
+				fmt.Println("No kubeconfigs found in Vault")
+				return nil
+			}
 			fmt.Println("List kubeconfig contexts stored in Vault:")
-			for _, item := range items {
-				fmt.Printf(" - %s\n", item.FilterValue())
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "NAME\tCONTEXTS\tTAGS\tDESCRIPTION")
+			for _, r := range remotes {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.SecretName, strings.Join(r.ContextNames, ","),
+					kubeconfig.FormatTags(r.Tags), r.Description)
 			}
-			return nil
+			return w.Flush()
 		},
 	}
+	cmd.Flags().StringVarP(&selectorFlag, "selector", "l", "", "Only list kubeconfigs whose tags match (e.g. env=prod,owner!=qa)")
 	flags.SharedFlags(cmd)
+	vaultLayoutFlags(cmd)
+	output.AddFlags(cmd, &out)
 	return cmd
 }
+
+// remotesListing builds the -o output of contexts.
+func remotesListing(remotes []kubeconfig.RemoteKubeconfig) output.Listing {
+	listing := output.Listing{
+		Items:   remotes,
+		Columns: []string{"NAME", "CONTEXTS", "TAGS", "DESCRIPTION"},
+		Names:   []string{},
+	}
+	if remotes == nil {
+		listing.Items = []kubeconfig.RemoteKubeconfig{}
+	}
+	for _, r := range remotes {
+		listing.Rows = append(listing.Rows, []string{r.SecretName, strings.Join(r.ContextNames, ","), kubeconfig.FormatTags(r.Tags), r.Description})
+		listing.Names = append(listing.Names, r.SecretName)
+	}
+	return listing
+}
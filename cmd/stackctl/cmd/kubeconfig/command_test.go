@@ -1,7 +1,13 @@
 package kubeconfig
 
 import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/spf13/cobra"
@@ -10,6 +16,8 @@ import (
 
 	"github.com/eliasmeireles/stackctl/cmd/stackctl/cmd/cmd"
 	featureKubeconfig "github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/kubeconfig"
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/sshclient"
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/vault"
 )
 
 func TestSetContextCategory(t *testing.T) {
@@ -74,8 +82,9 @@ func TestNewCommand(t *testing.T) {
 
 		expectedSubs := []string{
 			"list-contexts", "clean", "get-context", "set-context",
-			"set-namespace", "add", "remove",
-			"add-from-vault", "save-to-vault", "contexts",
+			"set-namespace", "add", "remove", "prune", "lint",
+			"add-from-vault", "save-to-vault", "contexts", "backups", "inspect", "rename",
+			"sync", "remote", "exec", "credential", "add-dynamic",
 		}
 
 		for _, expected := range expectedSubs {
@@ -191,11 +200,13 @@ func TestExecuteVaultFunctions(t *testing.T) {
 
 		called := make(map[string]bool)
 
-		executeSaveToVaultFunc = func(contextName string) {
+		executeSaveToVaultFunc = func(contextName string, opts featureKubeconfig.SaveOptions) error {
 			called["save"] = true
+			return nil
 		}
-		get = func(dataPath string) {
+		get = func(dataPath string, opts featureKubeconfig.ImportOptions) error {
 			called["from"] = true
+			return nil
 		}
 
 		defer func() {
@@ -203,8 +214,8 @@ func TestExecuteVaultFunctions(t *testing.T) {
 			get = origFrom
 		}()
 
-		SaveToVault("test-context")
-		VaultGet("test/path")
+		assert.NoError(t, SaveToVault("test-context", featureKubeconfig.SaveOptions{}))
+		assert.NoError(t, VaultGet("test/path", featureKubeconfig.ImportOptions{}))
 
 		assert.True(t, called["save"])
 		assert.True(t, called["from"])
@@ -219,12 +230,24 @@ func TestVaultEdgeCases(t *testing.T) {
 			ContextNames: []string{"ctx1", "ctx2"},
 		}
 
-		name, details := vaultFetch(r)()
+		name, details := vaultFetch(r, featureKubeconfig.DefaultKubeconfigSecretKey)()
 		assert.Equal(t, "test-secret", name)
 		assert.Contains(t, details, "ctx1")
 		assert.Contains(t, details, "ctx2")
 	})
 
+	t.Run("vaultFetch must show tags and description", func(t *testing.T) {
+		r := featureKubeconfig.RemoteKubeconfig{
+			SecretName:  "prod",
+			Tags:        map[string]string{"env": "prod", "owner": "platform"},
+			Description: "Production cluster",
+		}
+
+		_, details := vaultFetch(r, featureKubeconfig.DefaultKubeconfigSecretKey)()
+		assert.Contains(t, details, "env=prod,owner=platform")
+		assert.Contains(t, details, "Production cluster")
+	})
+
 	t.Run("deriveResourceName must return last path segment", func(t *testing.T) {
 		assert.Equal(t, "secret", deriveResourceName("path/to/secret"))
 		assert.Equal(t, "secret", deriveResourceName("path/to/secret/"))
@@ -232,3 +255,677 @@ func TestVaultEdgeCases(t *testing.T) {
 	})
 
 }
+
+func TestAddRemoteFetch(t *testing.T) {
+	t.Run("must read the remote file with the ssh flags", func(t *testing.T) {
+		t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "config"))
+
+		origRead := readRemoteFile
+		defer func() { readRemoteFile = origRead }()
+
+		var gotCfg sshclient.Config
+		var gotPath string
+		readRemoteFile = func(cfg sshclient.Config, path string) ([]byte, error) {
+			gotCfg, gotPath = cfg, path
+			return []byte("apiVersion: v1\nkind: Config\n"), nil
+		}
+
+		addCmd := NewAddCmd()
+		addCmd.SetArgs([]string{
+			"--k3s", "--host", "10.0.0.5", "--ssh-user", "ubuntu", "--ssh-port", "2222",
+			"--identity-file", "/keys/id", "--jump-host", "bastion", "--sudo",
+			"--no-validate", "--dry-run",
+		})
+		require.NoError(t, addCmd.Execute())
+
+		assert.Equal(t, "/etc/rancher/k3s/k3s.yaml", gotPath)
+		assert.Equal(t, sshclient.Config{
+			Host: "10.0.0.5", User: "ubuntu", Port: 2222,
+			IdentityFile: "/keys/id", JumpHost: "bastion", Sudo: true,
+		}, gotCfg)
+	})
+}
+
+func TestAddDistro(t *testing.T) {
+	t.Run("must run the distro command with sudo for non-root users", func(t *testing.T) {
+		t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "config"))
+
+		origRun := runRemoteCommand
+		defer func() { runRemoteCommand = origRun }()
+
+		var gotCfg sshclient.Config
+		var gotCommand string
+		runRemoteCommand = func(cfg sshclient.Config, command string) ([]byte, error) {
+			gotCfg, gotCommand = cfg, command
+			return []byte("apiVersion: v1\nkind: Config\n"), nil
+		}
+
+		addCmd := NewAddCmd()
+		addCmd.SetArgs([]string{"--distro", "microk8s", "--host", "10.0.0.8", "--ssh-user", "ubuntu", "--no-validate", "--dry-run"})
+		require.NoError(t, addCmd.Execute())
+
+		assert.Equal(t, "microk8s config", gotCommand)
+		assert.True(t, gotCfg.Sudo)
+	})
+
+	t.Run("must map generated TUI entries to the distro flag", func(t *testing.T) {
+		t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "config"))
+
+		origRead := readRemoteFile
+		defer func() { readRemoteFile = origRead }()
+
+		var gotCfg sshclient.Config
+		var gotPath string
+		readRemoteFile = func(cfg sshclient.Config, path string) ([]byte, error) {
+			gotCfg, gotPath = cfg, path
+			return []byte("apiVersion: v1\nkind: Config\n"), nil
+		}
+
+		addCmd := NewAddCmd()
+		addCmd.SetArgs([]string{"From Remote RKE2", "10.0.0.7", "", "--no-validate", "--dry-run"})
+		require.NoError(t, addCmd.Execute())
+
+		assert.Equal(t, "/etc/rancher/rke2/rke2.yaml", gotPath)
+		assert.Equal(t, "root", gotCfg.User)
+		assert.False(t, gotCfg.Sudo)
+	})
+
+	t.Run("must reject unknown distros", func(t *testing.T) {
+		addCmd := NewAddCmd()
+		addCmd.SetArgs([]string{"--distro", "openshift", "--host", "10.0.0.9"})
+		addCmd.SilenceErrors = true
+		assert.Error(t, addCmd.Execute())
+	})
+}
+
+func TestAddStdin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	t.Setenv("KUBECONFIG", path)
+
+	addCmd := NewAddCmd()
+	addCmd.SetIn(strings.NewReader(`apiVersion: v1
+kind: Config
+clusters:
+- name: default
+  cluster:
+    server: https://dev:6443
+contexts:
+- name: default
+  context:
+    cluster: default
+    user: default
+users:
+- name: default
+  user:
+    token: t
+current-context: default
+`))
+	addCmd.SetArgs([]string{"-", "-r", "dev", "--no-validate"})
+	require.NoError(t, addCmd.Execute())
+
+	names, err := featureKubeconfig.GetContextNames(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev"}, names)
+}
+
+func TestSyncDirection(t *testing.T) {
+	orig := syncFunc
+	defer func() { syncFunc = orig }()
+
+	var got featureKubeconfig.SyncDirection
+	syncFunc = func(opts featureKubeconfig.SyncOptions) (*featureKubeconfig.SyncReport, error) {
+		got = opts.Direction
+		return &featureKubeconfig.SyncReport{Direction: opts.Direction}, nil
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want featureKubeconfig.SyncDirection
+	}{
+		{"report by default", nil, featureKubeconfig.SyncReportOnly},
+		{"pull flag", []string{"--pull"}, featureKubeconfig.SyncPull},
+		{"push flag", []string{"--push"}, featureKubeconfig.SyncPush},
+		{"both flag", []string{"--both"}, featureKubeconfig.SyncBoth},
+		{"TUI choice", []string{syncChoicePush}, featureKubeconfig.SyncPush},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = "unset"
+			c := NewSyncCmd()
+			c.SetArgs(tt.args)
+			require.NoError(t, c.Execute())
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("rejects more than one direction", func(t *testing.T) {
+		c := NewSyncCmd()
+		c.SetArgs([]string{"--pull", "--push"})
+		assert.Error(t, c.Execute())
+	})
+
+	t.Run("fails when a context fails to sync", func(t *testing.T) {
+		syncFunc = func(opts featureKubeconfig.SyncOptions) (*featureKubeconfig.SyncReport, error) {
+			return &featureKubeconfig.SyncReport{Entries: []featureKubeconfig.SyncEntry{
+				{Name: "prod", Status: featureKubeconfig.SyncLocalOnly, Action: featureKubeconfig.SyncFailed},
+			}}, nil
+		}
+		c := NewSyncCmd()
+		c.SetArgs([]string{"--push"})
+		assert.ErrorContains(t, c.Execute(), "1 context(s) failed to sync")
+	})
+}
+
+func TestRemoteCmd(t *testing.T) {
+	orig := vaultService
+	defer func() { vaultService = orig }()
+
+	called := false
+	vaultService = func() (*featureKubeconfig.VaultKubeconfigService, error) {
+		called = true
+		return nil, errors.New("no vault")
+	}
+
+	t.Run("has history, diff and restore", func(t *testing.T) {
+		subCommands := make(map[string]bool)
+		for _, c := range NewRemoteCmd().Commands() {
+			subCommands[c.Name()] = true
+		}
+		assert.True(t, subCommands["history"])
+		assert.True(t, subCommands["diff"])
+		assert.True(t, subCommands["restore"])
+	})
+
+	t.Run("validates versions before connecting", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"diff", "prod", "v1", "latest"},
+			{"restore", "prod", "--version", "0"},
+		} {
+			called = false
+			c := NewRemoteCmd()
+			c.SetArgs(args)
+			assert.ErrorContains(t, c.Execute(), "invalid version")
+			assert.False(t, called, "must not connect to Vault for %v", args)
+		}
+	})
+
+	t.Run("restore requires --version", func(t *testing.T) {
+		c := NewRemoteCmd()
+		c.SetArgs([]string{"restore", "prod"})
+		assert.ErrorContains(t, c.Execute(), "version")
+	})
+
+	t.Run("reports Vault errors", func(t *testing.T) {
+		c := NewRemoteCmd()
+		c.SetArgs([]string{"history", "prod"})
+		assert.ErrorContains(t, c.Execute(), "no vault")
+	})
+}
+
+func TestSaveToVaultTags(t *testing.T) {
+	orig := executeSaveToVaultFunc
+	defer func() { executeSaveToVaultFunc = orig }()
+
+	var got featureKubeconfig.SaveOptions
+	executeSaveToVaultFunc = func(contextName string, opts featureKubeconfig.SaveOptions) error {
+		got = opts
+		return nil
+	}
+
+	c := NewSaveToVaultCmd()
+	c.SetArgs([]string{"prod", "--tag", "env=prod", "--tag", "owner=platform", "--tag", "legacy-", "--description", "Production"})
+	require.NoError(t, c.Execute())
+	assert.Equal(t, map[string]string{"env": "prod", "owner": "platform"}, got.Tags)
+	assert.Equal(t, []string{"legacy"}, got.RemoveTags)
+	assert.Equal(t, "Production", got.Description)
+
+	c = NewSaveToVaultCmd()
+	c.SetArgs([]string{"prod", "--tag", "env"})
+	assert.ErrorContains(t, c.Execute(), "invalid tag")
+}
+
+func TestListRemoteSelector(t *testing.T) {
+	orig := remotesFunc
+	defer func() { remotesFunc = orig }()
+
+	remotes := []featureKubeconfig.RemoteKubeconfig{
+		{SecretName: "prod", Tags: map[string]string{"env": "prod"}},
+		{SecretName: "staging", Tags: map[string]string{"env": "staging"}},
+	}
+	var got []featureKubeconfig.RemoteKubeconfig
+	remotesFunc = func(selector featureKubeconfig.Selector) ([]featureKubeconfig.RemoteKubeconfig, error) {
+		got = featureKubeconfig.FilterRemotes(remotes, selector)
+		return got, nil
+	}
+
+	c := NewListRemoteCmd()
+	c.SetArgs([]string{"--selector", "env=prod"})
+	require.NoError(t, c.Execute())
+	require.Len(t, got, 1)
+	assert.Equal(t, "prod", got[0].SecretName)
+
+	c = NewListRemoteCmd()
+	c.SetArgs([]string{"--selector", "=prod"})
+	assert.ErrorContains(t, c.Execute(), "invalid selector")
+}
+
+func TestVaultLayoutFlags(t *testing.T) {
+	t.Setenv(featureKubeconfig.ConfigFileEnvVar, filepath.Join(t.TempDir(), "config.yaml"))
+	t.Setenv(featureKubeconfig.VaultMountEnvVar, "kv")
+	t.Setenv(featureKubeconfig.VaultBasePathEnvVar, "")
+	t.Setenv(featureKubeconfig.VaultFieldEnvVar, "")
+	defer func() { layoutFlags = featureKubeconfig.VaultLayout{} }()
+
+	t.Run("every Vault command accepts the layout flags", func(t *testing.T) {
+		commands := []*cobra.Command{NewAddFromVaultCmd(), NewSaveToVaultCmd(), NewListRemoteCmd(), NewSyncCmd()}
+		commands = append(commands, NewRemoteCmd().Commands()...)
+		for _, c := range commands {
+			for _, name := range []string{"vault-mount", "vault-base-path", "vault-field"} {
+				assert.NotNil(t, c.Flags().Lookup(name), "%s must have --%s", c.Name(), name)
+			}
+		}
+	})
+
+	t.Run("flags override the environment", func(t *testing.T) {
+		c := NewSyncCmd()
+		require.NoError(t, c.ParseFlags([]string{"--vault-base-path", "clusters", "--vault-field", "kubeconfig"}))
+
+		layout, err := vaultLayout()
+		require.NoError(t, err)
+		assert.Equal(t, featureKubeconfig.VaultLayout{Mount: "kv", BasePath: "clusters", Field: "kubeconfig"}, layout)
+		assert.Equal(t, "kv/data/clusters", layout.DataPath())
+	})
+}
+
+func TestExecCmd(t *testing.T) {
+	orig := ephemeralConfigFunc
+	defer func() { ephemeralConfigFunc = orig }()
+
+	var gotName, gotContext string
+	var gotVault bool
+	ephemeralConfigFunc = func(name, contextName string, fromVault bool) (*featureKubeconfig.Config, error) {
+		gotName, gotContext, gotVault = name, contextName, fromVault
+		return &featureKubeconfig.Config{APIVersion: "v1", Kind: "Config"}, nil
+	}
+
+	t.Run("requires the command after --", func(t *testing.T) {
+		c := NewExecCmd()
+		c.SetArgs([]string{"prod", "kubectl"})
+		assert.ErrorContains(t, c.Execute(), "usage")
+	})
+
+	t.Run("passes the exit code on", func(t *testing.T) {
+		c := NewExecCmd()
+		c.SetArgs([]string{"--vault", "--context", "admin", "prod", "--", "sh", "-c", "exit 4"})
+		err := c.Execute()
+
+		var exit interface{ ExitCode() int }
+		require.ErrorAs(t, err, &exit)
+		assert.Equal(t, 4, exit.ExitCode())
+		assert.True(t, c.SilenceErrors)
+		assert.Equal(t, "prod", gotName)
+		assert.Equal(t, "admin", gotContext)
+		assert.True(t, gotVault)
+	})
+
+	t.Run("reports load errors", func(t *testing.T) {
+		ephemeralConfigFunc = func(string, string, bool) (*featureKubeconfig.Config, error) {
+			return nil, errors.New("no vault")
+		}
+		c := NewExecCmd()
+		c.SetArgs([]string{"prod", "--", "true"})
+		assert.ErrorContains(t, c.Execute(), "Failed to load 'prod'")
+	})
+}
+
+func TestCredentialCmd(t *testing.T) {
+	origCred, origSave, origPlugin := credentialFunc, executeSaveToVaultFunc, useExecPluginFunc
+	defer func() { credentialFunc, executeSaveToVaultFunc, useExecPluginFunc = origCred, origSave, origPlugin }()
+
+	t.Run("requires --vault-path", func(t *testing.T) {
+		c := NewCredentialCmd()
+		c.SetArgs([]string{})
+		assert.ErrorContains(t, c.Execute(), "--vault-path is required")
+	})
+
+	t.Run("passes the options on", func(t *testing.T) {
+		var got featureKubeconfig.CredentialOptions
+		credentialFunc = func(opts featureKubeconfig.CredentialOptions) (*featureKubeconfig.ExecCredential, error) {
+			got = opts
+			return &featureKubeconfig.ExecCredential{APIVersion: featureKubeconfig.ExecCredentialAPIVersion, Kind: "ExecCredential"}, nil
+		}
+		c := NewCredentialCmd()
+		c.SetArgs([]string{"--vault-path", "prod", "--ttl", "5m", "--no-cache"})
+		require.NoError(t, c.Execute())
+		assert.Equal(t, "prod", got.SecretName)
+		assert.Equal(t, 5*time.Minute, got.TTL)
+		assert.True(t, got.NoCache)
+	})
+
+	t.Run("save-to-vault --as-exec-plugin rewrites the user after saving", func(t *testing.T) {
+		var steps []string
+		executeSaveToVaultFunc = func(string, featureKubeconfig.SaveOptions) error {
+			steps = append(steps, "save")
+			return nil
+		}
+		useExecPluginFunc = func(contextName string) error {
+			steps = append(steps, "plugin:"+contextName)
+			return nil
+		}
+		c := NewSaveToVaultCmd()
+		c.SetArgs([]string{"prod", "--as-exec-plugin"})
+		require.NoError(t, c.Execute())
+		assert.Equal(t, []string{"save", "plugin:prod"}, steps)
+
+		steps = nil
+		executeSaveToVaultFunc = func(string, featureKubeconfig.SaveOptions) error { return errors.New("denied") }
+		c = NewSaveToVaultCmd()
+		c.SetArgs([]string{"prod", "--as-exec-plugin"})
+		assert.ErrorContains(t, c.Execute(), "denied")
+		assert.Empty(t, steps, "the user must not be rewritten when the save fails")
+	})
+}
+
+func TestAddDynamicCmd(t *testing.T) {
+	origAdd, origRevoke := addDynamicFunc, revokeLeaseFunc
+	defer func() { addDynamicFunc, revokeLeaseFunc = origAdd, origRevoke }()
+
+	t.Run("requires --role and --namespace", func(t *testing.T) {
+		c := NewAddDynamicCmd()
+		c.SetArgs([]string{"--role", "dev"})
+		assert.ErrorContains(t, c.Execute(), "--role and --namespace are required")
+	})
+
+	t.Run("passes the options on", func(t *testing.T) {
+		var got featureKubeconfig.DynamicOptions
+		addDynamicFunc = func(opts featureKubeconfig.DynamicOptions) (*featureKubeconfig.DynamicLease, error) {
+			got = opts
+			return &featureKubeconfig.DynamicLease{LeaseID: "kubernetes/creds/dev/1"}, nil
+		}
+		c := NewAddDynamicCmd()
+		c.SetArgs([]string{"--role", "dev", "--namespace", "team-a", "--ttl", "1h", "--cluster-role-binding"})
+		require.NoError(t, c.Execute())
+		assert.Equal(t, featureKubeconfig.DynamicOptions{
+			Mount: vault.DefaultKubernetesMount, Role: "dev", Namespace: "team-a",
+			TTL: time.Hour, ClusterRoleBinding: true,
+		}, got)
+	})
+
+	t.Run("remove revokes the lease of a dynamic context", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config")
+		t.Setenv("KUBECONFIG", path)
+		t.Setenv(featureKubeconfig.BackupDirEnvVar, t.TempDir())
+		require.NoError(t, featureKubeconfig.Save(path, dynamicConfig("dev-team-a", "kubernetes/creds/dev/1", time.Now().Add(time.Hour))))
+
+		var revoked []string
+		revokeLeaseFunc = func(leaseID string) error {
+			revoked = append(revoked, leaseID)
+			return errors.New("vault unreachable")
+		}
+		c := NewRemoveCmd()
+		c.SetArgs([]string{"dev-team-a"})
+		require.NoError(t, c.Execute(), "a failed revoke must not block the removal")
+		assert.Equal(t, []string{"kubernetes/creds/dev/1"}, revoked)
+
+		names, err := featureKubeconfig.GetContextNames(path)
+		require.NoError(t, err)
+		assert.Empty(t, names)
+	})
+
+	t.Run("remove skips expired leases and --keep-lease", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config")
+		t.Setenv("KUBECONFIG", path)
+		t.Setenv(featureKubeconfig.BackupDirEnvVar, t.TempDir())
+		revokeLeaseFunc = func(leaseID string) error {
+			t.Errorf("unexpected revoke of %s", leaseID)
+			return nil
+		}
+
+		require.NoError(t, featureKubeconfig.Save(path, dynamicConfig("old", "kubernetes/creds/dev/1", time.Now().Add(-time.Hour))))
+		c := NewRemoveCmd()
+		c.SetArgs([]string{"old"})
+		require.NoError(t, c.Execute())
+
+		require.NoError(t, featureKubeconfig.Save(path, dynamicConfig("kept", "kubernetes/creds/dev/2", time.Now().Add(time.Hour))))
+		c = NewRemoveCmd()
+		c.SetArgs([]string{"kept", "--keep-lease"})
+		require.NoError(t, c.Execute())
+	})
+}
+
+// dynamicConfig builds a one-context kubeconfig whose user carries a lease.
+func dynamicConfig(name, leaseID string, expires time.Time) *featureKubeconfig.Config {
+	return &featureKubeconfig.Config{
+		APIVersion: "v1",
+		Kind:       "Config",
+		Clusters:   []featureKubeconfig.Cluster{{Name: name, Cluster: featureKubeconfig.ClusterConfig{Server: "https://k8s:6443"}}},
+		Contexts:   []featureKubeconfig.Context{{Name: name, Context: featureKubeconfig.ContextConfig{Cluster: name, User: name}}},
+		Users: []featureKubeconfig.User{{Name: name, User: featureKubeconfig.UserConfig{
+			Token: "token",
+			Extensions: []featureKubeconfig.NamedExtension{{
+				Name:      featureKubeconfig.LeaseExtensionName,
+				Extension: map[string]interface{}{"lease_id": leaseID, "expires": expires.UTC().Format(time.RFC3339)},
+			}},
+		}}},
+	}
+}
+
+func TestCleanCmd(t *testing.T) {
+	origClean := cleanDuplicatesFunc
+	defer func() { cleanDuplicatesFunc = origClean }()
+
+	var got featureKubeconfig.CleanOptions
+	cleanDuplicatesFunc = func(path string, opts featureKubeconfig.CleanOptions) error {
+		got = opts
+		return nil
+	}
+
+	c := NewCleanCmd()
+	c.SetArgs([]string{"--yes", "--on-conflict", "keep"})
+	require.NoError(t, c.Execute())
+	assert.Equal(t, featureKubeconfig.CleanOptions{Yes: true, OnConflict: featureKubeconfig.ConflictKeep}, got)
+
+	c = NewCleanCmd()
+	c.SetArgs([]string{"--on-conflict", "replace"})
+	assert.ErrorContains(t, c.Execute(), "invalid --on-conflict")
+}
+
+func TestPruneCmd(t *testing.T) {
+	origPrune := pruneFunc
+	defer func() { pruneFunc = origPrune }()
+
+	var got featureKubeconfig.PruneOptions
+	pruneFunc = func(path string, opts featureKubeconfig.PruneOptions) (*featureKubeconfig.PruneReport, error) {
+		got = opts
+		return &featureKubeconfig.PruneReport{DryRun: opts.DryRun}, nil
+	}
+
+	c := NewPruneCmd()
+	c.SetArgs([]string{"--unreachable", "--timeout", "3s", "--expired-certs", "--dry-run"})
+	require.NoError(t, c.Execute())
+	assert.Equal(t, featureKubeconfig.PruneOptions{Unreachable: true, Timeout: 3 * time.Second, ExpiredCerts: true, DryRun: true}, got)
+
+	pruneFunc = func(string, featureKubeconfig.PruneOptions) (*featureKubeconfig.PruneReport, error) {
+		return nil, errors.New("locked")
+	}
+	c = NewPruneCmd()
+	c.SetArgs([]string{})
+	assert.ErrorContains(t, c.Execute(), "locked")
+}
+
+func TestListOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	t.Setenv("KUBECONFIG", path)
+	require.NoError(t, featureKubeconfig.Save(path, &featureKubeconfig.Config{
+		APIVersion:     "v1",
+		Kind:           "Config",
+		CurrentContext: "dev",
+		Clusters:       []featureKubeconfig.Cluster{{Name: "dev", Cluster: featureKubeconfig.ClusterConfig{Server: "https://dev:6443"}}},
+		Users:          []featureKubeconfig.User{{Name: "dev", User: featureKubeconfig.UserConfig{Token: "secret"}}},
+		Contexts: []featureKubeconfig.Context{
+			{Name: "dev", Context: featureKubeconfig.ContextConfig{Cluster: "dev", User: "dev", Namespace: "apps"}},
+		},
+	}))
+
+	var out bytes.Buffer
+	c := NewListContextsCmd()
+	c.SetOut(&out)
+	c.SetArgs([]string{"-o", "json"})
+	require.NoError(t, c.Execute())
+	assert.JSONEq(t, `[{"name":"dev","cluster":"dev","user":"dev","namespace":"apps","server":"https://dev:6443","current":true}]`, out.String())
+
+	origRemotes := remotesFunc
+	defer func() { remotesFunc = origRemotes }()
+	remotesFunc = func(featureKubeconfig.Selector) ([]featureKubeconfig.RemoteKubeconfig, error) {
+		return []featureKubeconfig.RemoteKubeconfig{{SecretName: "prod"}, {SecretName: "staging"}}, nil
+	}
+
+	out.Reset()
+	c = NewListRemoteCmd()
+	c.SetOut(&out)
+	c.SetArgs([]string{"-o", "name"})
+	require.NoError(t, c.Execute())
+	assert.Equal(t, "prod\nstaging\n", out.String())
+
+	c = NewListRemoteCmd()
+	c.SetArgs([]string{"-o", "xml"})
+	assert.ErrorContains(t, c.Execute(), "invalid output format")
+}
+
+func TestLintCmd(t *testing.T) {
+	origLint := lintFileFunc
+	defer func() { lintFileFunc = origLint }()
+
+	var got string
+	lintFileFunc = func(path string) (*featureKubeconfig.LintReport, error) {
+		got = path
+		return &featureKubeconfig.LintReport{Findings: []featureKubeconfig.LintFinding{{
+			Severity: featureKubeconfig.SeverityWarning, Check: featureKubeconfig.CheckInsecureSkipTLSVerify,
+			Kind: "cluster", Name: "dev", Message: "TLS verification is disabled",
+		}}}, nil
+	}
+
+	var out bytes.Buffer
+	c := NewLintCmd()
+	c.SetOut(&out)
+	c.SetArgs([]string{"--file", "/tmp/kubeconfig", "-o", "json"})
+	require.NoError(t, c.Execute())
+	assert.Equal(t, "/tmp/kubeconfig", got)
+	assert.JSONEq(t, `[{"severity":"warning","check":"insecure-skip-tls-verify","kind":"cluster","name":"dev","message":"TLS verification is disabled"}]`, out.String())
+
+	c = NewLintCmd()
+	c.SetOut(&out)
+	c.SetArgs([]string{"--file", "/tmp/kubeconfig", "--strict", "-o", "name"})
+	assert.ErrorContains(t, c.Execute(), "1 warning(s)")
+}
+
+func TestSaveToVaultBulk(t *testing.T) {
+	origSave := saveContextsFunc
+	defer func() { saveContextsFunc = origSave }()
+
+	var got featureKubeconfig.BulkSaveOptions
+	saveContextsFunc = func(opts featureKubeconfig.BulkSaveOptions) (*featureKubeconfig.BulkSaveReport, error) {
+		got = opts
+		return &featureKubeconfig.BulkSaveReport{Results: []featureKubeconfig.SaveResult{
+			{Secret: "a", Contexts: []string{"a"}},
+			{Secret: "b", Contexts: []string{"b"}, Err: errors.New("permission denied")},
+		}}, nil
+	}
+
+	c := NewSaveToVaultCmd()
+	c.SetArgs([]string{"--contexts", "a,b", "--tag", "env=prod"})
+	assert.ErrorContains(t, c.Execute(), "1 of 2 secrets could not be saved")
+	assert.Equal(t, []string{"a", "b"}, got.Contexts)
+	assert.Equal(t, map[string]string{"env": "prod"}, got.Save.Tags)
+
+	c = NewSaveToVaultCmd()
+	c.SetArgs([]string{"--selector", "^prod", "--bundle", "prod", "--as-exec-plugin"})
+	assert.ErrorContains(t, c.Execute(), "cannot be combined with --bundle")
+
+	c = NewSaveToVaultCmd()
+	c.SetArgs([]string{"--bundle", "prod"})
+	assert.ErrorContains(t, c.Execute(), "--bundle requires")
+
+	c = NewSaveToVaultCmd()
+	c.SetArgs([]string{"--all", "--selector", "^prod"})
+	assert.Error(t, c.Execute())
+}
+
+func TestAddFromVaultBulk(t *testing.T) {
+	origImport := importContextsFunc
+	defer func() { importContextsFunc = origImport }()
+
+	var got featureKubeconfig.BulkImportOptions
+	importContextsFunc = func(opts featureKubeconfig.BulkImportOptions) (*featureKubeconfig.BulkImportReport, error) {
+		got = opts
+		return &featureKubeconfig.BulkImportReport{Results: []featureKubeconfig.ImportResult{
+			{Secret: "prod-eu", Status: featureKubeconfig.ImportImported},
+			{Secret: "prod-us", Status: featureKubeconfig.ImportSkipped, Reason: "already present locally"},
+		}}, nil
+	}
+
+	c := NewAddFromVaultCmd()
+	c.SetArgs([]string{"--match", "prod-*", "-l", "env=prod", "--missing", "--dry-run"})
+	require.NoError(t, c.Execute())
+	assert.Equal(t, "prod-*", got.Match)
+	assert.True(t, got.Missing)
+	assert.True(t, got.Import.DryRun)
+	assert.Len(t, got.Selector, 1)
+
+	got = featureKubeconfig.BulkImportOptions{}
+	c = newImportMissingCmd()
+	c.SetArgs([]string{importMissingChoice})
+	require.NoError(t, c.Execute())
+	assert.True(t, got.All)
+	assert.True(t, got.Missing)
+
+	origGet := get
+	defer func() { get = origGet }()
+	var fetched string
+	get = func(dataPath string, opts featureKubeconfig.ImportOptions) error {
+		fetched = dataPath
+		return nil
+	}
+	got = featureKubeconfig.BulkImportOptions{}
+	c = NewAddFromVaultCmd()
+	c.SetArgs([]string{importMissingChoice})
+	require.NoError(t, c.Execute())
+	assert.Equal(t, importMissingChoice, fetched, "the TUI label is a plain secret name on the CLI")
+	assert.False(t, got.All)
+
+	c = NewAddFromVaultCmd()
+	c.SetArgs([]string{"home-lab", "--all"})
+	assert.ErrorContains(t, c.Execute(), "cannot be combined")
+
+	c = NewAddFromVaultCmd()
+	c.SetArgs([]string{"home-lab", "--missing"})
+	assert.ErrorContains(t, c.Execute(), "--missing requires")
+
+	importContextsFunc = func(featureKubeconfig.BulkImportOptions) (*featureKubeconfig.BulkImportReport, error) {
+		return &featureKubeconfig.BulkImportReport{Results: []featureKubeconfig.ImportResult{
+			{Secret: "prod-eu", Status: featureKubeconfig.ImportFailed, Reason: "permission denied"},
+		}}, nil
+	}
+	c = NewAddFromVaultCmd()
+	c.SetArgs([]string{"--all"})
+	assert.ErrorContains(t, c.Execute(), "1 of 1 kubeconfigs could not be imported")
+}
+
+func TestBackupsPruneRemovesLegacyBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	t.Setenv("KUBECONFIG", path)
+	for i := 1; i <= 3; i++ {
+		name := path + ".backup." + time.Now().Add(-time.Duration(i)*time.Hour).Format("20060102_150405")
+		require.NoError(t, os.WriteFile(name, []byte("apiVersion: v1\nkind: Config\n"), 0600))
+	}
+
+	c := newBackupsPruneCmd()
+	c.SetArgs([]string{"--keep", "1"})
+	require.NoError(t, c.Execute())
+
+	backups, err := featureKubeconfig.ListBackups(path, featureKubeconfig.BackupPolicy{})
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
@@ -0,0 +1,60 @@
+package kubeconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/kubeconfig"
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/vault/flags"
+)
+
+// NewCredentialCmd creates the credential subcommand.
+func NewCredentialCmd() *cobra.Command {
+	return newCredentialCmdFunc()
+}
+
+var newCredentialCmdFunc = func() *cobra.Command {
+	var opts kubeconfig.CredentialOptions
+	cmd := &cobra.Command{
+		Use:   "credential",
+		Short: "Print an ExecCredential read from Vault (kubectl exec credential plugin)",
+		Long: `Read the token or client certificate of a kubeconfig stored in Vault and
+print it as a client.authentication.k8s.io/v1 ExecCredential for kubectl.
+
+Credentials are cached on disk, encrypted, until they expire (--ttl, capped by
+the certificate expiry). Users are normally pointed at this command with
+'save-to-vault --as-exec-plugin':
+
+  users:
+  - name: prod
+    user:
+      exec:
+        apiVersion: client.authentication.k8s.io/v1
+        command: stackctl
+        args: [kubeconfig, credential, --vault-path, secret/data/resources/kubeconfig/prod]`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.SecretName == "" {
+				return fmt.Errorf("❌ Error: --vault-path is required")
+			}
+			cred, err := VaultCredential(opts)
+			if err != nil {
+				return fmt.Errorf("❌ Failed to read credential from Vault: %v", err)
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(cred)
+		},
+	}
+	cmd.Flags().StringVar(&opts.SecretName, "vault-path", "", "Secret name or full data path of the stored kubeconfig")
+	cmd.Flags().StringVar(&opts.Context, "context", "", "Context to use when the secret holds several")
+	cmd.Flags().DurationVar(&opts.TTL, "ttl", kubeconfig.DefaultCredentialTTL, "How long kubectl may reuse the credential")
+	cmd.Flags().BoolVar(&opts.NoCache, "no-cache", false, "Always read the credential from Vault")
+	flags.SharedFlags(cmd)
+	vaultLayoutFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,65 @@
+package kubeconfig
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/kubeconfig"
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/vault"
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/vault/flags"
+)
+
+// NewAddDynamicCmd creates the add-dynamic subcommand.
+func NewAddDynamicCmd() *cobra.Command {
+	return newAddDynamicCmdFunc()
+}
+
+var newAddDynamicCmdFunc = func() *cobra.Command {
+	var (
+		opts   kubeconfig.DynamicOptions
+		caFile string
+	)
+	cmd := &cobra.Command{
+		Use:   "add-dynamic",
+		Short: "Add a context using a short-lived token from the Vault kubernetes secrets engine",
+		Long: `Request a service-account token from <mount>/creds/<role> and write a context
+using it (named <role>-<namespace> unless --name is set).
+
+The cluster address and CA are read from <mount>/config unless --server and
+--ca-file are given. The Vault lease is recorded on the user: 'inspect' shows
+when it expires and 'remove' revokes it. Configure the engine and its roles
+with the 'kubernetes' section of 'stackctl vault apply'.`,
+		Example:      `  stackctl kubeconfig add-dynamic --role dev --namespace team-a --ttl 1h`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Role == "" || opts.Namespace == "" {
+				return fmt.Errorf("❌ Error: --role and --namespace are required")
+			}
+			if caFile != "" {
+				data, err := os.ReadFile(caFile)
+				if err != nil {
+					return fmt.Errorf("❌ Failed to read CA file: %v", err)
+				}
+				opts.CAData = base64.StdEncoding.EncodeToString(data)
+			}
+			if _, err := AddDynamic(opts); err != nil {
+				return fmt.Errorf("❌ Failed to add dynamic credentials: %v", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opts.Role, "role", "", "Role of the kubernetes secrets engine")
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "", "Kubernetes namespace the token is issued for")
+	cmd.Flags().DurationVar(&opts.TTL, "ttl", 0, "Token TTL (default: the role's token_default_ttl)")
+	cmd.Flags().StringVar(&opts.Mount, "mount", vault.DefaultKubernetesMount, "Mount of the kubernetes secrets engine")
+	cmd.Flags().BoolVar(&opts.ClusterRoleBinding, "cluster-role-binding", false, "Bind the role cluster-wide")
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Name of the context, cluster and user")
+	cmd.Flags().StringVar(&opts.Server, "server", "", "Cluster address (default: kubernetes_host of the engine)")
+	cmd.Flags().StringVar(&caFile, "ca-file", "", "Cluster CA certificate (default: kubernetes_ca_cert of the engine)")
+	flags.SharedFlags(cmd)
+	return cmd
+}
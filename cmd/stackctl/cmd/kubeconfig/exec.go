@@ -0,0 +1,74 @@
+package kubeconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/kubeconfig"
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/vault/flags"
+)
+
+// NewExecCmd creates the exec subcommand.
+func NewExecCmd() *cobra.Command {
+	return newExecCmdFunc()
+}
+
+var newExecCmdFunc = func() *cobra.Command {
+	var (
+		contextName string
+		fromVault   bool
+	)
+	cmd := &cobra.Command{
+		Use:   "exec [vault-name|context] -- [command] [args...]",
+		Short: "Run a command against a single context without changing the local kubeconfig",
+		Long: `Write a single-context kubeconfig to a private temporary file, run the
+command with KUBECONFIG pointing at it and wipe the file when it exits. The
+local kubeconfig is never modified.
+
+The name is a local context if one exists, otherwise a kubeconfig stored in
+Vault; --vault always reads from Vault. Signals are forwarded to the command
+and its exit code is returned.
+
+Examples:
+  stackctl kubeconfig exec prod -- kubectl get pods -A
+  stackctl kubeconfig exec --vault home-lab --context admin -- k9s`,
+		Args:         cobra.MinimumNArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.ArgsLenAtDash() != 1 {
+				return fmt.Errorf("❌ Error: usage: exec [vault-name|context] -- [command] [args...]")
+			}
+			name := args[0]
+
+			config, err := EphemeralConfig(name, contextName, fromVault)
+			if err != nil {
+				return fmt.Errorf("❌ Failed to load '%s': %v", name, err)
+			}
+
+			err = kubeconfig.Exec(config, kubeconfig.ExecOptions{
+				Args:   args[1:],
+				Stdin:  os.Stdin,
+				Stdout: os.Stdout,
+				Stderr: os.Stderr,
+			})
+			var exitErr *kubeconfig.ExitError
+			if errors.As(err, &exitErr) {
+				// The command already reported its failure; pass the code on.
+				cmd.SilenceErrors = true
+				return exitErr
+			}
+			if err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&contextName, "context", "", "Context to use when the Vault secret holds several")
+	cmd.Flags().BoolVar(&fromVault, "vault", false, "Read the kubeconfig from Vault even if a local context has the same name")
+	flags.SharedFlags(cmd)
+	vaultLayoutFlags(cmd)
+	return cmd
+}
@@ -1,12 +1,22 @@
 package kubeconfig
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/kubeconfig"
 	vaultpkg "github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/vault/flags"
 )
 
 var (
 	// VaultFlags holds Vault authentication flags for kubeconfig commands
 	VaultFlags vaultpkg.VaultFlags
+
+	// layoutFlags holds the Vault storage layout set on the command line.
+	layoutFlags kubeconfig.VaultLayout
 )
 
 // resolveVaultFlags merges flag values with env vars (flags take precedence)
@@ -18,3 +28,80 @@ func resolveVaultFlags() {
 var resolveVaultFlagsFunc = func() {
 	vaultpkg.Resolve()
 }
+
+// vaultLayoutFlags adds --vault-mount, --vault-base-path and --vault-field to cmd.
+func vaultLayoutFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&layoutFlags.Mount, "vault-mount", "",
+		fmt.Sprintf("KV v2 mount holding the kubeconfigs (env: %s, default: %s)", kubeconfig.VaultMountEnvVar, kubeconfig.DefaultVaultMount))
+	cmd.Flags().StringVar(&layoutFlags.BasePath, "vault-base-path", "",
+		fmt.Sprintf("Path of the kubeconfigs inside the mount (env: %s, default: %s)", kubeconfig.VaultBasePathEnvVar, kubeconfig.DefaultVaultBasePath))
+	cmd.Flags().StringVar(&layoutFlags.Field, "vault-field", "",
+		fmt.Sprintf("Secret field holding the kubeconfig, matched case-insensitively (env: %s, default: %s)", kubeconfig.VaultFieldEnvVar, kubeconfig.DefaultKubeconfigSecretKey))
+}
+
+// vaultLayout resolves the storage layout: flags over env over the config
+// file over the defaults.
+func vaultLayout() (kubeconfig.VaultLayout, error) {
+	layout, err := kubeconfig.LoadVaultLayout()
+	if err != nil {
+		return layout, err
+	}
+	return layout.Merge(layoutFlags), nil
+}
+
+// importFlags holds the flags shared by commands that merge a kubeconfig
+// into the local one.
+type importFlags struct {
+	onConflict      string
+	dryRun          bool
+	validate        bool
+	noValidate      bool
+	validateTimeout time.Duration
+
+	// defaultValidation applies when neither --validate nor --no-validate is set.
+	defaultValidation kubeconfig.ValidationMode
+}
+
+// register adds --on-conflict and --dry-run to cmd.
+func (f *importFlags) register(cmd *cobra.Command) {
+	names := make([]string, 0, len(kubeconfig.ConflictStrategies))
+	for _, s := range kubeconfig.ConflictStrategies {
+		names = append(names, string(s))
+	}
+	cmd.Flags().StringVar(&f.onConflict, "on-conflict", string(kubeconfig.ConflictReplace),
+		fmt.Sprintf("How to handle entries that already exist with different content (%s)", strings.Join(names, "|")))
+	cmd.Flags().BoolVar(&f.dryRun, "dry-run", false, "Print the changes that would be made without writing the kubeconfig")
+	cmd.Flags().BoolVar(&f.validate, "validate", false, "Probe the imported clusters and abort the import if one is unreachable")
+	cmd.Flags().BoolVar(&f.noValidate, "no-validate", false, "Skip probing the imported clusters")
+	cmd.Flags().DurationVar(&f.validateTimeout, "validate-timeout", kubeconfig.DefaultValidateTimeout, "Timeout for each cluster probe")
+	_ = cmd.RegisterFlagCompletionFunc("on-conflict", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return names, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// options builds the import options for the given resource name.
+func (f *importFlags) options(name string) (kubeconfig.ImportOptions, error) {
+	strategy, err := kubeconfig.ParseConflictStrategy(f.onConflict)
+	if err != nil {
+		return kubeconfig.ImportOptions{}, err
+	}
+	if f.validate && f.noValidate {
+		return kubeconfig.ImportOptions{}, fmt.Errorf("--validate and --no-validate are mutually exclusive")
+	}
+
+	validation := f.defaultValidation
+	switch {
+	case f.validate:
+		validation = kubeconfig.ValidateStrict
+	case f.noValidate:
+		validation = kubeconfig.ValidateSkip
+	}
+
+	return kubeconfig.ImportOptions{
+		Name:            name,
+		OnConflict:      strategy,
+		DryRun:          f.dryRun,
+		Validation:      validation,
+		ValidateTimeout: f.validateTimeout,
+	}, nil
+}
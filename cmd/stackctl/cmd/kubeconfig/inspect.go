@@ -0,0 +1,52 @@
+package kubeconfig
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/kubeconfig"
+)
+
+// NewInspectCmd creates the inspect subcommand.
+func NewInspectCmd() *cobra.Command {
+	return newInspectCmdFunc()
+}
+
+var newInspectCmdFunc = func() *cobra.Command {
+	var warnDays int
+	cmd := &cobra.Command{
+		Use:   "inspect [context-name]",
+		Short: "Show server, TLS, auth and certificate expiry details of contexts",
+		Long: `Inspect one context, or every context when none is given, and report the
+server URL, TLS settings, authentication mechanism and the subject, issuer,
+expiry and SHA-256 fingerprint of the CA and client certificates.
+
+Exits with a non-zero status when a certificate has expired or expires
+within --warn-days.`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			contextName := ""
+			if len(args) > 0 {
+				contextName = args[0]
+			}
+			if err := kubeconfig.Inspect(kubeconfig.GetPath(), contextName, warnDays); err != nil {
+				return fmt.Errorf("❌ Failed to inspect kubeconfig: %v", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&warnDays, "warn-days", kubeconfig.DefaultWarnDays, "Fail when a certificate expires within this many days")
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		contexts, err := kubeconfig.GetContextNames(kubeconfig.GetPath())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return contexts, cobra.ShellCompDirectiveNoFileComp
+	}
+	return cmd
+}
@@ -0,0 +1,97 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/kubeconfig"
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/output"
+)
+
+// NewLintCmd creates the lint subcommand.
+func NewLintCmd() *cobra.Command {
+	return newLintCmdFunc()
+}
+
+var newLintCmdFunc = func() *cobra.Command {
+	var (
+		file   string
+		strict bool
+		out    output.Options
+	)
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check kubeconfig for broken references, invalid data and risky settings",
+		Long: `Check a kubeconfig and report each problem with a severity:
+
+  error    contexts referencing missing clusters or users, invalid base64
+           in *-data fields, unparsable PEM, unreadable certificate files
+           and a current-context that does not exist
+  warning  insecure-skip-tls-verify, the same server under different
+           cluster names and a missing current-context
+  info     contexts without a namespace
+
+Exits with a non-zero status when errors are found, or warnings too with
+--strict, so it can run in CI. The same checks run after every import and
+log their warnings.`,
+		Example: `  stackctl kubeconfig lint
+  stackctl kubeconfig lint --file ./ci/kubeconfig --strict -o json`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Validate(); err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+			if file == "" {
+				file = kubeconfig.GetPath()
+			}
+			report, err := LintFile(file)
+			if err != nil {
+				return fmt.Errorf("❌ Failed to lint kubeconfig: %v", err)
+			}
+			if out.Structured() {
+				if err := out.Print(cmd.OutOrStdout(), lintListing(report)); err != nil {
+					return err
+				}
+			} else {
+				report.Print(os.Stdout)
+			}
+			if err := report.Check(strict); err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Kubeconfig file to lint (default: the active kubeconfig)")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Exit with a non-zero status on warnings too")
+	output.AddFlags(cmd, &out)
+	return cmd
+}
+
+// lintListing builds the -o output of lint.
+func lintListing(report *kubeconfig.LintReport) output.Listing {
+	listing := output.Listing{
+		Items:   report.Findings,
+		Columns: []string{"SEVERITY", "CHECK", "KIND", "NAME", "MESSAGE"},
+		Names:   []string{},
+	}
+	if report.Findings == nil {
+		listing.Items = []kubeconfig.LintFinding{}
+	}
+	for _, f := range report.Findings {
+		listing.Rows = append(listing.Rows, []string{string(f.Severity), f.Check, f.Kind, f.Name, f.Message})
+		listing.Names = append(listing.Names, f.Kind+"/"+f.Name)
+	}
+	return listing
+}
+
+// LintFile lints the kubeconfig at path.
+func LintFile(path string) (*kubeconfig.LintReport, error) {
+	return lintFileFunc(path)
+}
+
+var lintFileFunc = func(path string) (*kubeconfig.LintReport, error) {
+	return kubeconfig.LintFile(path)
+}
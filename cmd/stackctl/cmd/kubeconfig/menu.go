@@ -1,6 +1,9 @@
 package kubeconfig
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 
@@ -9,13 +12,13 @@ import (
 )
 
 var (
-	addConfigItems = []list.Item{
+	addConfigItems = append(append([]list.Item{
 		ui.CreatePromptItem("From Base64", "Import from a base64 string", "Base64 String", nil),
 		ui.CreatePromptItem("From Local File", "Import from a local yaml file", "File Path", nil),
 		ui.CreateMultiPromptItem("From Remote (SSH)", "Fetch config from a remote VPS", []string{"Host (IP/DNS)", "SSH User (default: root)", "Remote Path"}, nil),
-		ui.CreateMultiPromptItem("From Remote k3s", "Fetch default k3s config from VPS", []string{"Host (IP/DNS)", "SSH User (default: root)"}, nil),
+	}, distroItems()...),
 		ui.CreateDynamicSubMenu("From Vault", "Import kubeconfig from Vault", VaultList),
-	}
+	)
 
 	ctxItems = getContextItems()
 
@@ -25,13 +28,78 @@ var (
 		ui.CreateSubMenu("Set Current Context", "Switch to another context", ctxItems),
 		ui.CreateItem("Clean Duplicates", "Remove duplicate entries", ui.HoopAction),
 		ui.CreateSubMenu("Remove Context", "Delete a context from config", ctxItems),
+		ui.CreateDynamicSubMenu("Rename Context", "Rename a context", RenameContextItems),
+		ui.CreateSubMenu("Inspect Context", "Show certificates, auth and TLS details", ctxItems),
+		ui.CreateItem("Backups", "List kubeconfig backups", ui.HoopAction),
 		ui.CreateDynamicSubMenu("Save to Vault", "Save local context to Vault", LocalContext),
 		ui.CreateDynamicSubMenu("Contexts", "List kubeconfig contexts stored in Vault", VaultContexts),
+		ui.CreateSubMenu("Sync with Vault", "Compare and reconcile local contexts with Vault", syncItems),
+	}
+
+	syncItems = []list.Item{
+		ui.CreateItem(syncChoiceReport, "Show local-only, remote-only and diverged contexts", ui.HoopAction),
+		ui.CreateItem(syncChoicePull, "Import remote-only and diverged contexts", ui.HoopAction),
+		ui.CreateItem(syncChoicePush, "Upload local-only and diverged contexts", ui.HoopAction),
+		ui.CreateItem(syncChoiceBoth, "Copy missing contexts both ways", ui.HoopAction),
 	}
 
 	Menu = ui.CreateSubMenu("K8s Config", "Manage Kubernetes configurations", configItems)
 )
 
+// distroRemotePrefix prefixes the TUI entries generated from the distro
+// registry, e.g. "From Remote k3s".
+const distroRemotePrefix = "From Remote "
+
+func distroItems() []list.Item {
+	items := make([]list.Item, 0, len(kubeconfig.Distros))
+	for _, d := range kubeconfig.Distros {
+		items = append(items, ui.CreateMultiPromptItem(
+			distroRemotePrefix+d.Title,
+			fmt.Sprintf("Fetch the default %s config from a VPS", d.Title),
+			[]string{"Host (IP/DNS)", "SSH User (default: root)"},
+			nil,
+		))
+	}
+	return items
+}
+
+// distroFromChoice returns the distro of a generated "From Remote" entry.
+func distroFromChoice(choice string) (kubeconfig.Distro, bool) {
+	title, ok := strings.CutPrefix(choice, distroRemotePrefix)
+	if !ok {
+		return kubeconfig.Distro{}, false
+	}
+	for _, d := range kubeconfig.Distros {
+		if d.Title == title {
+			return d, true
+		}
+	}
+	return kubeconfig.Distro{}, false
+}
+
+// Entries of the "Sync with Vault" submenu.
+const (
+	syncChoiceReport = "Show Drift"
+	syncChoicePull   = "Pull from Vault"
+	syncChoicePush   = "Push to Vault"
+	syncChoiceBoth   = "Both Ways"
+)
+
+// syncDirectionFromChoice maps a "Sync with Vault" entry to its direction.
+func syncDirectionFromChoice(choice string) (kubeconfig.SyncDirection, bool) {
+	switch choice {
+	case syncChoiceReport:
+		return kubeconfig.SyncReportOnly, true
+	case syncChoicePull:
+		return kubeconfig.SyncPull, true
+	case syncChoicePush:
+		return kubeconfig.SyncPush, true
+	case syncChoiceBoth:
+		return kubeconfig.SyncBoth, true
+	}
+	return "", false
+}
+
 func getContextItems() []list.Item {
 	names, err := kubeconfig.GetContextNames(kubeconfig.GetPath())
 	if err != nil {
@@ -48,3 +116,18 @@ func getContextItems() []list.Item {
 	}
 	return items
 }
+
+// RenameContextItems lists the local contexts, each prompting for the new
+// name. The TUI runs "rename <context> <new-name>".
+func RenameContextItems() ([]list.Item, error) {
+	names, err := kubeconfig.GetContextNames(kubeconfig.GetPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load contexts: %w", err)
+	}
+
+	items := make([]list.Item, 0, len(names))
+	for _, name := range names {
+		items = append(items, ui.CreatePromptItem(name, "Rename this context", "New name", nil))
+	}
+	return items, nil
+}
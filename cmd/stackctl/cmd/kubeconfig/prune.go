@@ -0,0 +1,59 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/kubeconfig"
+)
+
+// NewPruneCmd creates the prune subcommand.
+func NewPruneCmd() *cobra.Command {
+	return newPruneCmdFunc()
+}
+
+var newPruneCmdFunc = func() *cobra.Command {
+	var opts kubeconfig.PruneOptions
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove orphaned clusters and users, unreachable contexts and expired credentials",
+		Long: `Remove entries that are no longer useful from kubeconfig:
+
+  --orphans        clusters and users no context references (the default)
+  --unreachable    contexts whose cluster does not answer within --timeout
+  --expired-certs  contexts whose CA or client certificate has expired
+
+Clusters are probed concurrently. Clusters and users only used by removed
+contexts are removed with them. A backup is taken before writing; --dry-run
+only reports.`,
+		Example: `  stackctl kubeconfig prune --dry-run
+  stackctl kubeconfig prune --unreachable --timeout 5s --expired-certs`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := Prune(kubeconfig.GetPath(), opts)
+			if err != nil {
+				return fmt.Errorf("❌ Failed to prune kubeconfig: %v", err)
+			}
+			report.Print(os.Stdout)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&opts.Orphans, "orphans", false, "Remove clusters and users no context references")
+	cmd.Flags().BoolVar(&opts.Unreachable, "unreachable", false, "Remove contexts whose cluster does not answer")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", kubeconfig.DefaultPruneTimeout, "Timeout for each cluster probe")
+	cmd.Flags().BoolVar(&opts.ExpiredCerts, "expired-certs", false, "Remove contexts whose CA or client certificate has expired")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Report what would be removed without writing the kubeconfig")
+	return cmd
+}
+
+// Prune removes the entries of the kubeconfig at path selected by opts.
+func Prune(path string, opts kubeconfig.PruneOptions) (*kubeconfig.PruneReport, error) {
+	return pruneFunc(path, opts)
+}
+
+var pruneFunc = func(path string, opts kubeconfig.PruneOptions) (*kubeconfig.PruneReport, error) {
+	return kubeconfig.Prune(path, opts)
+}
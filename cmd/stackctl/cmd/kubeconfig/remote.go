@@ -0,0 +1,163 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/kubeconfig"
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/vault/flags"
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/output"
+)
+
+// NewRemoteCmd creates the remote subcommand and its children.
+func NewRemoteCmd() *cobra.Command {
+	return newRemoteCmdFunc()
+}
+
+var newRemoteCmdFunc = func() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remote",
+		Short: "Inspect and restore the versions of kubeconfigs stored in Vault",
+		Long: `Every save-to-vault or sync --push writes a new KV v2 version of the secret.
+These commands list those versions, compare two of them and restore an
+earlier one. A secret is referenced by its name, as shown by 'contexts', and
+a version by its number (3 or v3).`,
+	}
+
+	cmd.AddCommand(newRemoteHistoryCmd())
+	cmd.AddCommand(newRemoteDiffCmd())
+	cmd.AddCommand(newRemoteRestoreCmd())
+	return cmd
+}
+
+func newRemoteHistoryCmd() *cobra.Command {
+	var out output.Options
+	cmd := &cobra.Command{
+		Use:          "history [name]",
+		Short:        "List the versions of a kubeconfig stored in Vault",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Validate(); err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+			svc, err := vaultService()
+			if err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+			versions, err := svc.History(args[0])
+			if err != nil {
+				return fmt.Errorf("❌ Failed to read history: %v", err)
+			}
+			if out.Structured() {
+				return out.Print(cmd.OutOrStdout(), historyListing(versions))
+			}
+			return kubeconfig.PrintHistory(os.Stdout, args[0], versions)
+		},
+	}
+	flags.SharedFlags(cmd)
+	vaultLayoutFlags(cmd)
+	output.AddFlags(cmd, &out)
+	return cmd
+}
+
+// historyListing builds the -o output of remote history.
+func historyListing(versions []kubeconfig.SecretVersion) output.Listing {
+	listing := output.Listing{
+		Items:   versions,
+		Columns: []string{"VERSION", "CREATED", "DELETED", "DESTROYED", "CURRENT"},
+		Names:   []string{},
+	}
+	if versions == nil {
+		listing.Items = []kubeconfig.SecretVersion{}
+	}
+	for _, v := range versions {
+		deleted := ""
+		if !v.DeletionTime.IsZero() {
+			deleted = v.DeletionTime.Format(time.RFC3339)
+		}
+		listing.Rows = append(listing.Rows, []string{strconv.Itoa(v.Version), v.CreatedTime.Format(time.RFC3339), deleted,
+			strconv.FormatBool(v.Destroyed), strconv.FormatBool(v.Current)})
+		listing.Names = append(listing.Names, strconv.Itoa(v.Version))
+	}
+	return listing
+}
+
+func newRemoteDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "diff [name] [from-version] [to-version]",
+		Short:        "Show the changes between two versions, with credentials redacted",
+		Args:         cobra.ExactArgs(3),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, err := kubeconfig.ParseVersion(args[1])
+			if err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+			to, err := kubeconfig.ParseVersion(args[2])
+			if err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+
+			svc, err := vaultService()
+			if err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+			lines, err := svc.DiffVersions(args[0], from, to)
+			if err != nil {
+				return fmt.Errorf("❌ Failed to diff versions: %v", err)
+			}
+
+			if !kubeconfig.HasDiff(lines) {
+				fmt.Printf("✅ Versions %d and %d of '%s' are identical\n", from, to, args[0])
+				return nil
+			}
+			fmt.Printf("--- %s v%d\n+++ %s v%d\n", args[0], from, args[0], to)
+			for _, line := range kubeconfig.CompactDiff(lines, 3) {
+				fmt.Println(line)
+			}
+			return nil
+		},
+	}
+	flags.SharedFlags(cmd)
+	vaultLayoutFlags(cmd)
+	return cmd
+}
+
+func newRemoteRestoreCmd() *cobra.Command {
+	var version string
+	cmd := &cobra.Command{
+		Use:   "restore [name]",
+		Short: "Restore an earlier version of a kubeconfig stored in Vault",
+		Long: `Write the content of an earlier version as the new current version, like
+'vault kv rollback'. The versions in between are kept.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := kubeconfig.ParseVersion(version)
+			if err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+
+			svc, err := vaultService()
+			if err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+			newVersion, err := svc.RestoreVersion(args[0], n)
+			if err != nil {
+				return fmt.Errorf("❌ Failed to restore version: %v", err)
+			}
+			fmt.Printf("✅ Restored version %d of '%s' as version %d\n", n, args[0], newVersion)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&version, "version", "", "Version to restore (e.g. 3 or v3)")
+	_ = cmd.MarkFlagRequired("version")
+	flags.SharedFlags(cmd)
+	vaultLayoutFlags(cmd)
+	return cmd
+}
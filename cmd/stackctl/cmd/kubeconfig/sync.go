@@ -0,0 +1,84 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/kubeconfig"
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/vault/flags"
+)
+
+// NewSyncCmd creates the sync subcommand.
+func NewSyncCmd() *cobra.Command {
+	return newSyncCmdFunc()
+}
+
+var newSyncCmdFunc = func() *cobra.Command {
+	var (
+		pull, push, both bool
+		selectorFlag     string
+	)
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Compare local contexts with the kubeconfigs stored in Vault and reconcile them",
+		Long: `Compare every local context with the secret of the same name in Vault and
+report it as local-only, remote-only, identical or diverged. Contents are
+compared by hash, ignoring the names given to clusters and users on import.
+
+Without a flag nothing is changed. Reconcile with:
+  --push  upload local-only contexts and overwrite diverged secrets
+  --pull  import remote-only secrets and overwrite diverged local contexts
+  --both  upload local-only and import remote-only; diverged ones are left alone
+
+--selector limits the sync to the secrets whose tags match; local-only
+contexts are then not listed. Secrets holding more than one context are
+skipped.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			direction := kubeconfig.SyncReportOnly
+			switch {
+			case pull:
+				direction = kubeconfig.SyncPull
+			case push:
+				direction = kubeconfig.SyncPush
+			case both:
+				direction = kubeconfig.SyncBoth
+			}
+			// The TUI passes the selected entry as the only argument.
+			if len(args) > 0 {
+				d, ok := syncDirectionFromChoice(args[0])
+				if !ok || len(args) > 1 {
+					return fmt.Errorf("❌ Error: unexpected arguments %v", args)
+				}
+				direction = d
+			}
+
+			selector, err := kubeconfig.ParseSelector(selectorFlag)
+			if err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+
+			report, err := VaultSync(kubeconfig.SyncOptions{Direction: direction, Selector: selector})
+			if err != nil {
+				return fmt.Errorf("❌ Failed to sync kubeconfig with Vault: %v", err)
+			}
+
+			fmt.Printf("🔄 %s ↔ Vault\n", kubeconfig.GetPath())
+			report.Print(os.Stdout)
+			if failed := report.CountAction(kubeconfig.SyncFailed); failed > 0 {
+				return fmt.Errorf("❌ %d context(s) failed to sync", failed)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&pull, "pull", false, "Import remote-only and diverged contexts from Vault")
+	cmd.Flags().BoolVar(&push, "push", false, "Upload local-only and diverged contexts to Vault")
+	cmd.Flags().BoolVar(&both, "both", false, "Copy missing contexts both ways, leaving diverged ones alone")
+	cmd.Flags().StringVarP(&selectorFlag, "selector", "l", "", "Only sync the secrets whose tags match (e.g. env=prod)")
+	cmd.MarkFlagsMutuallyExclusive("pull", "push", "both")
+	flags.SharedFlags(cmd)
+	vaultLayoutFlags(cmd)
+	return cmd
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/kubeconfig"
 	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/vault"
+	vaultflags "github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/vault/flags"
 	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/ui"
 )
 
@@ -61,14 +62,10 @@ var vaultSaveToRemoteProviderFunc = func() ([]list.Item, error) {
 }
 
 var vaultContexts = func() ([]list.Item, error) {
-	resolveVaultFlags()
-	client, err := vault.ApiClient.EnvVaultClient()
-
+	svc, err := vaultService()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Vault client: %w", err)
+		return nil, err
 	}
-
-	svc := kubeconfig.NewVaultKubeconfigService(client)
 	remotes, err := svc.ListRemoteKubeconfigs()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list kubeconfigs: %w", err)
@@ -80,17 +77,24 @@ var vaultContexts = func() ([]list.Item, error) {
 		}, nil
 	}
 
-	items := make([]list.Item, 0, len(remotes))
+	items := make([]list.Item, 0, len(remotes)+1)
+	items = append(items, ui.CreateItem(importMissingChoice, "Import every stored kubeconfig without a local context", ui.HoopAction))
 	for _, r := range remotes {
 		contextList := strings.Join(r.ContextNames, ", ")
 		desc := fmt.Sprintf("Contexts: %s", contextList)
 		if len(r.ContextNames) == 0 {
 			desc = "No contexts found"
 		}
+		if len(r.Tags) > 0 {
+			desc += fmt.Sprintf(" [%s]", kubeconfig.FormatTags(r.Tags))
+		}
+		if r.Description != "" {
+			desc = r.Description + " · " + desc
+		}
 		items = append(items, ui.CreateDetailItem(
 			r.SecretName,
 			desc,
-			vaultFetch(r),
+			vaultFetch(r, svc.SecretKey()),
 		))
 	}
 	return items, nil
@@ -98,12 +102,19 @@ var vaultContexts = func() ([]list.Item, error) {
 
 // vaultFetch returns a fetcher that displays the details
 // of a remote kubeconfig stored in Vault.
-func vaultFetch(r kubeconfig.RemoteKubeconfig) func() (string, string) {
+func vaultFetch(r kubeconfig.RemoteKubeconfig, field string) func() (string, string) {
 	return func() (string, string) {
 		var sb strings.Builder
 		_, _ = fmt.Fprintf(&sb, "  Secret: %s\n", r.SecretName)
 		_, _ = fmt.Fprintf(&sb, "  Path:   %s\n", r.DataPath)
-		_, _ = fmt.Fprintf(&sb, "  Key:    %s\n\n", kubeconfig.DefaultKubeconfigSecretKey)
+		_, _ = fmt.Fprintf(&sb, "  Key:    %s\n", field)
+		if r.Description != "" {
+			_, _ = fmt.Fprintf(&sb, "  About:  %s\n", r.Description)
+		}
+		if len(r.Tags) > 0 {
+			_, _ = fmt.Fprintf(&sb, "  Tags:   %s\n", kubeconfig.FormatTags(r.Tags))
+		}
+		sb.WriteString("\n")
 
 		if len(r.ContextNames) == 0 {
 			sb.WriteString("  No contexts found in this kubeconfig")
@@ -128,14 +139,10 @@ var vaultFromVaultProviderFunc = func() ([]list.Item, error) {
 }
 
 var vaultList = func() ([]list.Item, error) {
-	resolveVaultFlags()
-	client, err := vault.ApiClient.EnvVaultClient()
-
+	svc, err := vaultService()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Vault client: %w", err)
+		return nil, err
 	}
-
-	svc := kubeconfig.NewVaultKubeconfigService(client)
 	remotes, err := svc.ListRemoteKubeconfigs()
 	if err != nil {
 		log.Errorf("❌ Failed to Clusters configuration kubeconfigs: %v", err)
@@ -167,67 +174,214 @@ var vaultList = func() ([]list.Item, error) {
 	return items, nil
 }
 
-// SaveToVault saves a local kubeconfig context to Vault.
-// It uses the context name as the default secret name.
-func SaveToVault(contextName string) {
-	executeSaveToVaultFunc(contextName)
+// SaveToVault saves a local kubeconfig context to Vault, tagging it with
+// opts. It uses the context name as the default secret name.
+func SaveToVault(contextName string, opts kubeconfig.SaveOptions) error {
+	return executeSaveToVaultFunc(contextName, opts)
 }
 
-var executeSaveToVaultFunc = func(contextName string) {
-	saveToVault(contextName)
+var executeSaveToVaultFunc = func(contextName string, opts kubeconfig.SaveOptions) error {
+	return saveToVault(contextName, opts)
 }
 
-var saveToVault = func(contextName string) {
-	resolveVaultFlags()
-	client, err := vault.ApiClient.EnvVaultClient()
-
+var saveToVault = func(contextName string, opts kubeconfig.SaveOptions) error {
+	svc, err := vaultService()
 	if err != nil {
-		fmt.Printf("Failed to create Vault client: %v", err)
-		return
+		return err
 	}
-
-	svc := kubeconfig.NewVaultKubeconfigService(client)
 	kubeconfigPath := kubeconfig.GetPath()
 
-	if err := svc.SaveContextToVault(kubeconfigPath, contextName, contextName); err != nil {
-		fmt.Printf("❌ Failed to save context '%s' to Vault: %v\n", contextName, err)
-		return
+	if err := svc.SaveContextToVaultWithOptions(kubeconfigPath, contextName, contextName, opts); err != nil {
+		return err
 	}
 
 	fmt.Printf("✅ Context '%s' saved to Vault\n", contextName)
+	return nil
 }
 
-// VaultGet fetches a kubeconfig from Vault and merges it into the local config.
-func VaultGet(dataPath string) {
-	get(dataPath)
+// SaveContextsToVault saves the local contexts selected by opts to Vault.
+func SaveContextsToVault(opts kubeconfig.BulkSaveOptions) (*kubeconfig.BulkSaveReport, error) {
+	return saveContextsFunc(opts)
 }
 
-var get = func(dataPath string) {
-	vaultGet(dataPath)
+var saveContextsFunc = func(opts kubeconfig.BulkSaveOptions) (*kubeconfig.BulkSaveReport, error) {
+	svc, err := vaultService()
+	if err != nil {
+		return nil, err
+	}
+	return svc.SaveContexts(kubeconfig.GetPath(), opts)
 }
 
-var vaultGet = func(dataPath string) {
-	resolveVaultFlags()
-	client, err := vault.ApiClient.EnvVaultClient()
+// importMissingChoice is the "Contexts" entry importing every stored
+// kubeconfig that is not present locally.
+const importMissingChoice = "Import all missing"
 
+// ImportContextsFromVault imports the stored kubeconfigs selected by opts.
+func ImportContextsFromVault(opts kubeconfig.BulkImportOptions) (*kubeconfig.BulkImportReport, error) {
+	return importContextsFunc(opts)
+}
+
+var importContextsFunc = func(opts kubeconfig.BulkImportOptions) (*kubeconfig.BulkImportReport, error) {
+	svc, err := vaultService()
+	if err != nil {
+		return nil, err
+	}
+	return svc.ImportContexts(kubeconfig.GetPath(), opts)
+}
+
+// UseExecPlugin points the local user of contextName at the credential
+// plugin for the secret it was saved to.
+func UseExecPlugin(contextName string) error {
+	return useExecPluginFunc(contextName)
+}
+
+var useExecPluginFunc = func(contextName string) error {
+	svc, err := vaultService()
+	if err != nil {
+		return err
+	}
+	exec := kubeconfig.CredentialExecConfig(svc.SecretPath(contextName), svc.SecretKey(), vaultflags.Flags.Addr)
+	return kubeconfig.UseExecPlugin(kubeconfig.GetPath(), contextName, exec)
+}
+
+// VaultCredential reads the ExecCredential for a stored kubeconfig.
+func VaultCredential(opts kubeconfig.CredentialOptions) (*kubeconfig.ExecCredential, error) {
+	return credentialFunc(opts)
+}
+
+var credentialFunc = func(opts kubeconfig.CredentialOptions) (*kubeconfig.ExecCredential, error) {
+	svc, err := vaultService()
+	if err != nil {
+		return nil, err
+	}
+	return svc.Credential(opts)
+}
+
+// AddDynamic issues a token from the Vault kubernetes secrets engine and
+// writes a context using it.
+func AddDynamic(opts kubeconfig.DynamicOptions) (*kubeconfig.DynamicLease, error) {
+	return addDynamicFunc(opts)
+}
+
+var addDynamicFunc = func(opts kubeconfig.DynamicOptions) (*kubeconfig.DynamicLease, error) {
+	svc, err := vaultService()
 	if err != nil {
-		fmt.Printf("Failed to create Vault client: %v", err)
-		return
+		return nil, err
 	}
+	return svc.AddDynamic(kubeconfig.GetPath(), opts)
+}
 
-	svc := kubeconfig.NewVaultKubeconfigService(client)
+// RevokeLease revokes the Vault lease of a dynamic credential.
+func RevokeLease(leaseID string) error {
+	return revokeLeaseFunc(leaseID)
+}
+
+var revokeLeaseFunc = func(leaseID string) error {
+	svc, err := vaultService()
+	if err != nil {
+		return err
+	}
+	return svc.RevokeLease(leaseID)
+}
+
+// VaultGet fetches a kubeconfig from Vault and merges it into the local config.
+func VaultGet(dataPath string, opts kubeconfig.ImportOptions) error {
+	return get(dataPath, opts)
+}
+
+var get = func(dataPath string, opts kubeconfig.ImportOptions) error {
+	return vaultGet(dataPath, opts)
+}
+
+var vaultGet = func(dataPath string, opts kubeconfig.ImportOptions) error {
+	svc, err := vaultService()
+	if err != nil {
+		return err
+	}
+	// A bare secret name is looked up under the configured layout.
+	dataPath = svc.SecretPath(dataPath)
 	kubeconfigPath := kubeconfig.GetPath()
 
-	// Derive resource name from the data path (last segment)
-	name := deriveResourceName(dataPath)
+	if err := svc.FetchKubeconfigFromVaultWithOptions(dataPath, kubeconfigPath, opts); err != nil {
+		return err
+	}
+
+	if !opts.DryRun {
+		fmt.Printf("✅ Kubeconfig from '%s' merged into %s\n", dataPath, kubeconfigPath)
+	}
+	return nil
+}
+
+// VaultSync compares the local kubeconfig with Vault and reconciles it as
+// opts says.
+func VaultSync(opts kubeconfig.SyncOptions) (*kubeconfig.SyncReport, error) {
+	return syncFunc(opts)
+}
+
+var syncFunc = func(opts kubeconfig.SyncOptions) (*kubeconfig.SyncReport, error) {
+	return vaultSync(opts)
+}
+
+var vaultSync = func(opts kubeconfig.SyncOptions) (*kubeconfig.SyncReport, error) {
+	svc, err := vaultService()
+	if err != nil {
+		return nil, err
+	}
+	return svc.Sync(kubeconfig.GetPath(), opts)
+}
+
+// VaultRemotes lists the kubeconfigs stored in Vault whose tags match selector.
+func VaultRemotes(selector kubeconfig.Selector) ([]kubeconfig.RemoteKubeconfig, error) {
+	return remotesFunc(selector)
+}
 
-	if err := svc.FetchKubeconfigFromVault(dataPath, kubeconfigPath, name); err != nil {
-		log.Errorf("❌ Failed to vaultFetch kubeconfig from Vault: %v", err)
-		fmt.Printf("❌ Failed to vaultFetch kubeconfig from Vault: %v\n", err)
-		return
+var remotesFunc = func(selector kubeconfig.Selector) ([]kubeconfig.RemoteKubeconfig, error) {
+	svc, err := vaultService()
+	if err != nil {
+		return nil, err
+	}
+	remotes, err := svc.ListRemoteKubeconfigs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list kubeconfigs: %w", err)
+	}
+	return kubeconfig.FilterRemotes(remotes, selector), nil
+}
+
+// EphemeralConfig returns the single-context kubeconfig for exec: the local
+// context name, unless fromVault is set or no such context exists, in which
+// case the Vault secret name.
+func EphemeralConfig(name, contextName string, fromVault bool) (*kubeconfig.Config, error) {
+	return ephemeralConfigFunc(name, contextName, fromVault)
+}
+
+var ephemeralConfigFunc = func(name, contextName string, fromVault bool) (*kubeconfig.Config, error) {
+	if !fromVault && contextName == "" {
+		if config, err := kubeconfig.LocalContextConfig(kubeconfig.GetPath(), name); err == nil {
+			return config, nil
+		}
+	}
+	svc, err := vaultService()
+	if err != nil {
+		return nil, err
+	}
+	return svc.RemoteContextConfig(name, contextName)
+}
+
+// vaultService builds the kubeconfig service from the Vault flags and env,
+// using the configured storage layout.
+var vaultService = func() (*kubeconfig.VaultKubeconfigService, error) {
+	resolveVaultFlags()
+	layout, err := vaultLayout()
+	if err != nil {
+		return nil, err
+	}
+	client, err := vault.ApiClient.EnvVaultClient()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Vault client: %w", err)
 	}
 
-	fmt.Printf("✅ Kubeconfig from '%s' merged into %s\n", dataPath, kubeconfigPath)
+	return kubeconfig.NewVaultKubeconfigService(client, layout.Options()...), nil
 }
 
 // deriveResourceName extracts the last path segment as resource name.
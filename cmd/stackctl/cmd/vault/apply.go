@@ -25,7 +25,7 @@ var NewApplyCmdFunc = func() *cobra.Command {
 		Long: `Read a YAML configuration file and apply all Vault operations declaratively.
 
 Supports: secrets, policies, auth methods, secrets engines, and roles.
-Execution order: engines -> auth -> policies -> roles -> secrets.
+Execution order: engines -> auth -> policies -> roles -> kubernetes -> secrets.
 See example/vault-config.yaml for the full reference of all supported fields.
 
 Examples:
@@ -5,6 +5,8 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/output"
 )
 
 func NewAuthCmd() *cobra.Command {
@@ -29,22 +31,32 @@ func NewAuthListCmd() *cobra.Command {
 }
 
 var NewAuthListCmdFunc = func() *cobra.Command {
-	return &cobra.Command{
+	var out output.Options
+	cmd := &cobra.Command{
 		Use:          "list",
 		Short:        "List enabled auth methods",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Validate(); err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
 			auths, err := AuthMethodClient.List()
 			if err != nil {
 				return fmt.Errorf("❌ %v", err)
 			}
 
-			for path, auth := range auths {
-				fmt.Printf("%-30s type=%-12s description=%s\n", path, auth.Type, auth.Description)
+			mounts := sortedMounts(auths, authMountEntry)
+			if out.Structured() {
+				return out.Print(cmd.OutOrStdout(), mountsListing(mounts))
+			}
+			for _, m := range mounts {
+				fmt.Printf("%-30s type=%-12s description=%s\n", m.Path, m.Type, m.Description)
 			}
 			return nil
 		},
 	}
+	output.AddFlags(cmd, &out)
+	return cmd
 }
 
 func NewAuthEnableCmd() *cobra.Command {
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/eliasmeireles/stackctl/cmd/stackctl/cmd/cmd"
+	featureKubeconfig "github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/kubeconfig"
 )
 
 func TestVaultCommand(t *testing.T) {
@@ -65,11 +66,13 @@ func TestFetchInternalFunctions(t *testing.T) {
 
 		called := make(map[string]bool)
 
-		runExportEnvFunc = func(client *envvault.Client, secretPath string, githubEnv bool) {
+		runExportEnvFunc = func(client *envvault.Client, secretPath string, githubEnv bool) error {
 			called["export"] = true
+			return nil
 		}
-		runAsKubeconfigFunc = func(client *envvault.Client, secretPath, field, resourceName string) {
+		runAsKubeconfigFunc = func(client *envvault.Client, secretPath, field string, opts featureKubeconfig.ImportOptions) error {
 			called["asKubeconfig"] = true
+			return nil
 		}
 		deriveResourceNameFunc = func(path string) string {
 			called["derive"] = true
@@ -82,8 +85,8 @@ func TestFetchInternalFunctions(t *testing.T) {
 			deriveResourceNameFunc = origDerive
 		}()
 
-		runExportEnv(nil, "path", false)
-		runAsKubeconfig(nil, "path", "field", "name")
+		assert.NoError(t, runExportEnv(nil, "path", false))
+		assert.NoError(t, runAsKubeconfig(nil, "path", "field", featureKubeconfig.ImportOptions{Name: "name"}))
 		deriveResourceName("path/name")
 
 		assert.True(t, called["export"])
@@ -92,6 +95,24 @@ func TestFetchInternalFunctions(t *testing.T) {
 	})
 }
 
+func TestFetchCommandErrors(t *testing.T) {
+	t.Setenv("VAULT_SECRET_PATH", "")
+
+	t.Run("must fail without a secret path", func(t *testing.T) {
+		fetch := NewFetchCommand()
+		fetch.SetArgs([]string{})
+		err := fetch.Execute()
+		assert.ErrorContains(t, err, "--secret-path")
+	})
+
+	t.Run("must reject --validate with --no-validate", func(t *testing.T) {
+		fetch := NewFetchCommand()
+		fetch.SetArgs([]string{"--secret-path", "secret/data/kubeconfig/dev", "--validate", "--no-validate"})
+		err := fetch.Execute()
+		assert.ErrorContains(t, err, "mutually exclusive")
+	})
+}
+
 func TestNewCommandInitialization(t *testing.T) {
 	t.Run("must call NewCommandFunc", func(t *testing.T) {
 		orig := NewCommandFunc
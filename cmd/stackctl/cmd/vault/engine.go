@@ -5,6 +5,8 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/output"
 )
 
 func NewEngineCmd() *cobra.Command {
@@ -29,22 +31,32 @@ func NewEngineListCmd() *cobra.Command {
 }
 
 var NewEngineListCmdFunc = func() *cobra.Command {
-	return &cobra.Command{
+	var out output.Options
+	cmd := &cobra.Command{
 		Use:          "list",
 		Short:        "List enabled secrets engines",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			mounts, err := EngineClient.List()
+			if err := out.Validate(); err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
+			engines, err := EngineClient.List()
 			if err != nil {
 				return fmt.Errorf("❌ %v", err)
 			}
 
-			for path, mount := range mounts {
-				fmt.Printf("%-30s type=%-12s description=%s\n", path, mount.Type, mount.Description)
+			mounts := sortedMounts(engines, engineMountEntry)
+			if out.Structured() {
+				return out.Print(cmd.OutOrStdout(), mountsListing(mounts))
+			}
+			for _, m := range mounts {
+				fmt.Printf("%-30s type=%-12s description=%s\n", m.Path, m.Type, m.Description)
 			}
 			return nil
 		},
 	}
+	output.AddFlags(cmd, &out)
+	return cmd
 }
 
 func NewEngineEnableCmd() *cobra.Command {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -29,6 +30,11 @@ var NewFetchCommandFunc = func() *cobra.Command {
 		vaultAsKubeconfig bool
 		vaultGitHubEnv    bool
 		resourceName      string
+		onConflict        string
+		dryRun            bool
+		validate          bool
+		noValidate        bool
+		validateTimeout   time.Duration
 	)
 
 	cmd := &cobra.Command{
@@ -64,7 +70,8 @@ Examples:
   stackctl vault fetch --export-env --github-env \
     --addr http://vault:8200 --token s.xxx \
     --secret-path secret/data/ci/app-config`,
-		Run: func(cmd *cobra.Command, args []string) {
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			flags.Resolve()
 
 			if vaultSecretPath == "" {
@@ -74,18 +81,24 @@ Examples:
 				vaultSecretField = os.Getenv("VAULT_SECRET_FIELD")
 			}
 			if vaultSecretField == "" {
-				vaultSecretField = "kubeconfig"
+				// Same field as the kubeconfig commands; the lookup ignores case.
+				layout, err := featureKubeconfig.LoadVaultLayout()
+				if err != nil {
+					return fmt.Errorf("❌ %v", err)
+				}
+				vaultSecretField = layout.Field
 			}
 
 			if vaultSecretPath == "" {
-				log.Error("❌ --secret-path or VAULT_SECRET_PATH is required")
-				return
+				return fmt.Errorf("❌ --secret-path or VAULT_SECRET_PATH is required")
+			}
+			if validate && noValidate {
+				return fmt.Errorf("❌ --validate and --no-validate are mutually exclusive")
 			}
 
 			evClient, err := vaultpkg.ApiClient.EnvVaultClient()
 			if err != nil {
-				log.Errorf("❌ Failed to create Vault client: %v", err)
-				return
+				return fmt.Errorf("❌ Failed to create Vault client: %v", err)
 			}
 			vaultClient := evClient
 
@@ -94,38 +107,65 @@ Examples:
 			}
 
 			if vaultExportEnv {
-				runExportEnv(vaultClient, vaultSecretPath, vaultGitHubEnv)
+				if err := runExportEnv(vaultClient, vaultSecretPath, vaultGitHubEnv); err != nil {
+					return fmt.Errorf("❌ %v", err)
+				}
 			}
 
 			if vaultAsKubeconfig {
-				runAsKubeconfig(vaultClient, vaultSecretPath, vaultSecretField, resourceName)
+				strategy, err := featureKubeconfig.ParseConflictStrategy(onConflict)
+				if err != nil {
+					return fmt.Errorf("❌ %v", err)
+				}
+				name := resourceName
+				if name == "" {
+					name = deriveResourceName(vaultSecretPath)
+				}
+				opts := featureKubeconfig.ImportOptions{
+					Name:            name,
+					OnConflict:      strategy,
+					DryRun:          dryRun,
+					Validation:      featureKubeconfig.ValidateSkip,
+					ValidateTimeout: validateTimeout,
+				}
+				if validate {
+					opts.Validation = featureKubeconfig.ValidateStrict
+				}
+				if err := runAsKubeconfig(vaultClient, vaultSecretPath, vaultSecretField, opts); err != nil {
+					return fmt.Errorf("❌ %v", err)
+				}
 			}
+			return nil
 		},
 	}
 
 	cmd.Flags().StringVar(&vaultSecretPath, "secret-path", "", "Vault KV v2 secret path (env: VAULT_SECRET_PATH)")
-	cmd.Flags().StringVar(&vaultSecretField, "secret-field", "", "Field name for kubeconfig (default: kubeconfig, env: VAULT_SECRET_FIELD)")
+	cmd.Flags().StringVar(&vaultSecretField, "secret-field", "", "Field name for kubeconfig, matched case-insensitively (default: KUBECONFIG, env: VAULT_SECRET_FIELD or STACK_CTL_KUBECONFIG_VAULT_FIELD)")
 	cmd.Flags().BoolVar(&vaultExportEnv, "export-env", false, "Export all secret fields as environment variables")
 	cmd.Flags().BoolVar(&vaultAsKubeconfig, "as-kubeconfig", false, "Treat secret field as base64 kubeconfig and merge (default if no mode set)")
 	cmd.Flags().BoolVar(&vaultGitHubEnv, "github-env", false, "Write exported env vars to GITHUB_ENV for subsequent CI steps")
 	cmd.Flags().StringVarP(&resourceName, "resource-name", "r", "", "Resource name for the kubeconfig context")
+	cmd.Flags().StringVar(&onConflict, "on-conflict", string(featureKubeconfig.ConflictReplace), "How to handle kubeconfig entries that already exist (replace|keep|rename|fail|prompt)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the kubeconfig changes that would be made without writing them")
+	cmd.Flags().BoolVar(&validate, "validate", false, "Probe the fetched clusters and abort the merge if one is unreachable")
+	cmd.Flags().BoolVar(&noValidate, "no-validate", false, "Skip probing the fetched clusters (default)")
+	cmd.Flags().DurationVar(&validateTimeout, "validate-timeout", featureKubeconfig.DefaultValidateTimeout, "Timeout for each cluster probe")
 
 	return cmd
 }
 
 // runExportEnv reads all fields from the Vault secret and exports them as env vars.
-func runExportEnv(client *envvault.Client, secretPath string, githubEnv bool) {
-	runExportEnvFunc(client, secretPath, githubEnv)
+func runExportEnv(client *envvault.Client, secretPath string, githubEnv bool) error {
+	return runExportEnvFunc(client, secretPath, githubEnv)
 }
 
 // runExportEnvFunc is a function variable for exporting environment variables from Vault.
-var runExportEnvFunc = func(client *envvault.Client, secretPath string, githubEnv bool) {
+var runExportEnvFunc = func(client *envvault.Client, secretPath string, githubEnv bool) error {
 	log.Infof("🔍 Reading secret from Vault: %s", secretPath)
 
 	data, err := client.ReadSecret(secretPath)
 	if err != nil {
-		log.Errorf("❌ Failed to read secret from Vault: %v", err)
-		return
+		return fmt.Errorf("failed to read secret from Vault: %w", err)
 	}
 
 	for key, value := range data {
@@ -136,6 +176,7 @@ var runExportEnvFunc = func(client *envvault.Client, secretPath string, githubEn
 		}
 		log.Infof("✅ Exported %s", key)
 	}
+	return nil
 }
 
 // writeGitHubEnv writes environment variables to GITHUB_ENV file.
@@ -164,36 +205,23 @@ var writeGitHubEnvFunc = func(name, value string) {
 }
 
 // runAsKubeconfig reads a kubeconfig from Vault and merges it into the local config.
-func runAsKubeconfig(client *envvault.Client, secretPath, field, resourceName string) {
-	runAsKubeconfigFunc(client, secretPath, field, resourceName)
+func runAsKubeconfig(client *envvault.Client, secretPath, field string, opts featureKubeconfig.ImportOptions) error {
+	return runAsKubeconfigFunc(client, secretPath, field, opts)
 }
 
 // runAsKubeconfigFunc is a function variable for merging kubeconfig from Vault.
-var runAsKubeconfigFunc = func(client *envvault.Client, secretPath, field, resourceName string) {
-	data, err := client.ReadSecret(secretPath)
-	if err != nil {
-		log.Errorf("❌ Failed to read secret: %v", err)
-	}
-
-	kubeconfigBase64, ok := data[field].(string)
-	if !ok {
-		log.Errorf("❌ Field %q not found or not a string in %s", field, secretPath)
-	}
-
+var runAsKubeconfigFunc = func(client *envvault.Client, secretPath, field string, opts featureKubeconfig.ImportOptions) error {
 	kubeconfigPath := featureKubeconfig.GetPath()
-	name := resourceName
-	if name == "" {
-		name = deriveResourceName(secretPath)
-	}
 
-	svc := featureKubeconfig.NewVaultKubeconfigService(nil)
-	if err := svc.FetchKubeconfigFromVault(secretPath, kubeconfigPath, name); err != nil {
-		log.Errorf("❌ Failed to merge kubeconfig: %v", err)
-		return
+	svc := featureKubeconfig.NewVaultKubeconfigService(client, featureKubeconfig.WithSecretKey(field))
+	if err := svc.FetchKubeconfigFromVaultWithOptions(secretPath, kubeconfigPath, opts); err != nil {
+		return fmt.Errorf("failed to merge kubeconfig: %w", err)
 	}
 
-	log.Infof("✅ Kubeconfig from %s[%s] merged into %s", secretPath, field, kubeconfigPath)
-	_ = kubeconfigBase64 // Use the variable to avoid unused error if needed, although it is used above.
+	if !opts.DryRun {
+		log.Infof("✅ Kubeconfig from %s[%s] merged into %s", secretPath, field, kubeconfigPath)
+	}
+	return nil
 }
 
 // deriveResourceName extracts the resource name from the secret path.
@@ -0,0 +1,131 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/output"
+)
+
+// SecretKey is the -o schema of secret list.
+type SecretKey struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Folder is set for keys holding other secrets.
+	Folder bool `json:"folder"`
+}
+
+// PolicyEntry is the -o schema of policy list and policy get.
+type PolicyEntry struct {
+	Name  string `json:"name"`
+	Rules string `json:"rules,omitempty"`
+}
+
+// MountEntry is the -o schema of auth list and engine list.
+type MountEntry struct {
+	Path        string            `json:"path"`
+	Type        string            `json:"type"`
+	Description string            `json:"description"`
+	Accessor    string            `json:"accessor"`
+	Options     map[string]string `json:"options,omitempty"`
+}
+
+// RoleEntry is the -o schema of role list.
+type RoleEntry struct {
+	Name      string `json:"name"`
+	AuthMount string `json:"authMount"`
+}
+
+func secretKeysListing(listPath string, keys []string) output.Listing {
+	items := make([]SecretKey, 0, len(keys))
+	listing := output.Listing{Columns: []string{"NAME", "PATH", "FOLDER"}, Names: []string{}}
+	for _, key := range keys {
+		item := SecretKey{
+			Name:   strings.TrimSuffix(key, "/"),
+			Path:   strings.TrimRight(listPath, "/") + "/" + key,
+			Folder: strings.HasSuffix(key, "/"),
+		}
+		items = append(items, item)
+		listing.Rows = append(listing.Rows, []string{item.Name, item.Path, fmt.Sprint(item.Folder)})
+		listing.Names = append(listing.Names, item.Name)
+	}
+	listing.Items = items
+	return listing
+}
+
+func policiesListing(policies []PolicyEntry) output.Listing {
+	listing := output.Listing{Items: policies, Columns: []string{"NAME"}, Names: []string{}}
+	for _, p := range policies {
+		listing.Rows = append(listing.Rows, []string{p.Name})
+		listing.Names = append(listing.Names, p.Name)
+	}
+	return listing
+}
+
+// sortedMounts returns the mounts of an auth or engine listing ordered by path.
+func sortedMounts[T any](mounts map[string]T, entry func(path string, mount T) MountEntry) []MountEntry {
+	items := make([]MountEntry, 0, len(mounts))
+	for path, mount := range mounts {
+		items = append(items, entry(path, mount))
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Path < items[j].Path })
+	return items
+}
+
+func authMountEntry(path string, auth *api.AuthMount) MountEntry {
+	return MountEntry{Path: path, Type: auth.Type, Description: auth.Description, Accessor: auth.Accessor, Options: auth.Options}
+}
+
+func engineMountEntry(path string, mount *api.MountOutput) MountEntry {
+	return MountEntry{Path: path, Type: mount.Type, Description: mount.Description, Accessor: mount.Accessor, Options: mount.Options}
+}
+
+func mountsListing(mounts []MountEntry) output.Listing {
+	listing := output.Listing{Items: mounts, Columns: []string{"PATH", "TYPE", "ACCESSOR", "DESCRIPTION"}, Names: []string{}}
+	for _, m := range mounts {
+		listing.Rows = append(listing.Rows, []string{m.Path, m.Type, m.Accessor, m.Description})
+		listing.Names = append(listing.Names, m.Path)
+	}
+	return listing
+}
+
+func rolesListing(authMount string, roles []string) output.Listing {
+	items := make([]RoleEntry, 0, len(roles))
+	listing := output.Listing{Columns: []string{"NAME", "AUTH MOUNT"}, Names: []string{}}
+	for _, role := range roles {
+		items = append(items, RoleEntry{Name: role, AuthMount: authMount})
+		listing.Rows = append(listing.Rows, []string{role, authMount})
+		listing.Names = append(listing.Names, role)
+	}
+	listing.Items = items
+	return listing
+}
+
+// fieldsListing prints the fields of a secret or role: the map itself for
+// json, yaml and go-template, one KEY/VALUE row per field for the table and
+// the field names for -o name.
+func fieldsListing(data map[string]interface{}) output.Listing {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	listing := output.Listing{Items: data, Columns: []string{"KEY", "VALUE"}, Names: keys}
+	for _, key := range keys {
+		value, ok := data[key].(string)
+		if !ok {
+			raw, _ := json.Marshal(data[key])
+			value = string(raw)
+		}
+		listing.Rows = append(listing.Rows, []string{key, value})
+	}
+	return listing
+}
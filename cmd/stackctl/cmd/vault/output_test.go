@@ -0,0 +1,91 @@
+package vault
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/cmd/vault/client"
+)
+
+// fakeRole serves a fixed role list and role.
+type fakeRole struct {
+	client.Role
+	roles []string
+	data  map[string]interface{}
+}
+
+func (f *fakeRole) List(string) ([]string, error) { return f.roles, nil }
+
+func (f *fakeRole) Get(string, string) (map[string]interface{}, error) { return f.data, nil }
+
+func TestRoleCmdOutput(t *testing.T) {
+	orig := RoleClient
+	defer func() { RoleClient = orig }()
+	RoleClient = &fakeRole{
+		roles: []string{"ci", "deploy"},
+		data:  map[string]interface{}{"policies": []interface{}{"ci-read"}, "ttl": "1h"},
+	}
+
+	var out bytes.Buffer
+	c := NewRoleListCmd()
+	c.SetOut(&out)
+	c.SetArgs([]string{"auth/kubernetes", "-o", "json"})
+	require.NoError(t, c.Execute())
+	assert.JSONEq(t, `[{"name":"ci","authMount":"auth/kubernetes"},{"name":"deploy","authMount":"auth/kubernetes"}]`, out.String())
+
+	out.Reset()
+	c = NewRoleListCmd()
+	c.SetOut(&out)
+	c.SetArgs([]string{"auth/kubernetes", "-o", "name"})
+	require.NoError(t, c.Execute())
+	assert.Equal(t, "ci\ndeploy\n", out.String())
+
+	out.Reset()
+	c = NewRoleGetCmd()
+	c.SetOut(&out)
+	c.SetArgs([]string{"auth/kubernetes", "ci", "-o", "table"})
+	require.NoError(t, c.Execute())
+	assert.Equal(t, "KEY       VALUE\npolicies  [\"ci-read\"]\nttl       1h\n", out.String())
+
+	out.Reset()
+	c = NewRoleGetCmd()
+	c.SetOut(&out)
+	c.SetArgs([]string{"auth/kubernetes", "ci", "-o", "go-template={{.ttl}}"})
+	require.NoError(t, c.Execute())
+	assert.Equal(t, "1h", out.String())
+
+	c = NewRoleListCmd()
+	c.SetArgs([]string{"auth/kubernetes", "-o", "xml"})
+	assert.ErrorContains(t, c.Execute(), "invalid output format")
+}
+
+func TestListings(t *testing.T) {
+	t.Run("secret keys mark folders", func(t *testing.T) {
+		listing := secretKeysListing("secret/metadata/apps/", []string{"db", "team/"})
+		assert.Equal(t, []SecretKey{
+			{Name: "db", Path: "secret/metadata/apps/db"},
+			{Name: "team", Path: "secret/metadata/apps/team/", Folder: true},
+		}, listing.Items)
+		assert.Equal(t, []string{"db", "team"}, listing.Names)
+	})
+
+	t.Run("mounts are sorted by path", func(t *testing.T) {
+		mounts := sortedMounts(map[string]*api.MountOutput{
+			"sys/":    {Type: "system"},
+			"secret/": {Type: "kv", Options: map[string]string{"version": "2"}},
+		}, engineMountEntry)
+		require.Len(t, mounts, 2)
+		assert.Equal(t, "secret/", mounts[0].Path)
+		assert.Equal(t, "2", mounts[0].Options["version"])
+		assert.Equal(t, []string{"secret/", "sys/"}, mountsListing(mounts).Names)
+	})
+
+	t.Run("empty listings encode as empty lists", func(t *testing.T) {
+		assert.Equal(t, []RoleEntry{}, rolesListing("auth/approle", nil).Items)
+		assert.Equal(t, map[string]interface{}{}, fieldsListing(nil).Items)
+	})
+}
@@ -6,6 +6,8 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/output"
 )
 
 func NewPolicyCmd() *cobra.Command {
@@ -31,15 +33,26 @@ func NewPolicyListCmd() *cobra.Command {
 }
 
 var NewPolicyListCmdFunc = func() *cobra.Command {
-	return &cobra.Command{
+	var out output.Options
+	cmd := &cobra.Command{
 		Use:          "list",
 		Short:        "List all policies",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Validate(); err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
 			policies, err := PolicyClient.List()
 			if err != nil {
 				return fmt.Errorf("❌ %v", err)
 			}
+			if out.Structured() {
+				entries := make([]PolicyEntry, 0, len(policies))
+				for _, p := range policies {
+					entries = append(entries, PolicyEntry{Name: p})
+				}
+				return out.Print(cmd.OutOrStdout(), policiesListing(entries))
+			}
 
 			for _, p := range policies {
 				fmt.Println(p)
@@ -49,6 +62,8 @@ var NewPolicyListCmdFunc = func() *cobra.Command {
 			return nil
 		},
 	}
+	output.AddFlags(cmd, &out)
+	return cmd
 }
 
 func NewPolicyGetCmd() *cobra.Command {
@@ -56,21 +71,31 @@ func NewPolicyGetCmd() *cobra.Command {
 }
 
 var NewPolicyGetCmdFunc = func() *cobra.Command {
+	var out output.Options
 	cmd := &cobra.Command{
 		Use:          "get <name>",
 		Short:        "Read a policy",
 		Args:         cobra.ExactArgs(1),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Validate(); err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
 			policy, err := PolicyClient.Get(args[0])
 			if err != nil {
 				return fmt.Errorf("❌ %v", err)
 			}
+			if out.Structured() {
+				listing := policiesListing([]PolicyEntry{{Name: args[0], Rules: policy}})
+				listing.Items = PolicyEntry{Name: args[0], Rules: policy}
+				return out.Print(cmd.OutOrStdout(), listing)
+			}
 
 			fmt.Println(policy)
 			return nil
 		},
 	}
+	output.AddFlags(cmd, &out)
 
 	// Adding support for TUI execution (run.Command.Execute)
 	originalRunE := cmd.RunE
@@ -1,11 +1,12 @@
 package vault
 
 import (
-	"encoding/json"
 	"fmt"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/output"
 )
 
 // NewRoleCmd creates the role subcommand.
@@ -35,7 +36,8 @@ func NewRoleListCmd() *cobra.Command {
 
 // NewRoleListCmdFunc is a function variable for creating the role list command.
 var NewRoleListCmdFunc = func() *cobra.Command {
-	return &cobra.Command{
+	var out output.Options
+	cmd := &cobra.Command{
 		Use:   "list <auth-mount>",
 		Short: "List roles for an auth method",
 		Long: `List roles configured under an auth method mount.
@@ -47,10 +49,16 @@ Examples:
 		Args:         cobra.ExactArgs(1),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Validate(); err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
 			roles, err := RoleClient.List(args[0])
 			if err != nil {
 				return fmt.Errorf("❌ %v", err)
 			}
+			if out.Structured() {
+				return out.Print(cmd.OutOrStdout(), rolesListing(args[0], roles))
+			}
 
 			if len(roles) == 0 {
 				log.Info("No roles found.")
@@ -65,6 +73,8 @@ Examples:
 			return nil
 		},
 	}
+	output.AddFlags(cmd, &out)
+	return cmd
 }
 
 // NewRoleGetCmd creates the role get subcommand.
@@ -74,7 +84,8 @@ func NewRoleGetCmd() *cobra.Command {
 
 // NewRoleGetCmdFunc is a function variable for creating the role get command.
 var NewRoleGetCmdFunc = func() *cobra.Command {
-	return &cobra.Command{
+	var out output.Options
+	cmd := &cobra.Command{
 		Use:   "get <auth-mount> <role-name>",
 		Short: "Read a role configuration",
 		Long: `Read the configuration of a role under an auth method.
@@ -85,20 +96,22 @@ Examples:
 		Args:         cobra.ExactArgs(2),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Validate(); err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
 			data, err := RoleClient.Get(args[0], args[1])
 			if err != nil {
 				return fmt.Errorf("❌ %v", err)
 			}
 
-			output, err := json.MarshalIndent(data, "", "  ")
-			if err != nil {
-				return fmt.Errorf("❌ Failed to format output: %v", err)
+			if !out.Structured() {
+				out.Format = output.JSON
 			}
-
-			fmt.Println(string(output))
-			return nil
+			return out.Print(cmd.OutOrStdout(), fieldsListing(data))
 		},
 	}
+	output.AddFlags(cmd, &out)
+	return cmd
 }
 
 // NewRolePutCmd creates the role put subcommand.
@@ -1,7 +1,6 @@
 package vault
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -10,6 +9,7 @@ import (
 
 	vaultpkg "github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/vault"
 	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/vault/flags"
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/output"
 )
 
 const defaultListPath = "secret/metadata/resources/kubeconfig"
@@ -37,7 +37,8 @@ func NewSecretListCmd() *cobra.Command {
 }
 
 var NewSecretListCmdFunc = func() *cobra.Command {
-	return &cobra.Command{
+	var out output.Options
+	cmd := &cobra.Command{
 		Use:   "list [path]",
 		Short: "List secrets at a path (default: secret/metadata/resources/kubeconfig)",
 		Long: `List secret keys under a KV v2 metadata path.
@@ -47,6 +48,9 @@ Examples:
   stackctl vault secret list secret/metadata/ci/kubeconfig`,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Validate(); err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
 			flags.Resolve()
 			client, err := vaultpkg.ApiClient.EnvVaultClient()
 			if err != nil {
@@ -64,6 +68,9 @@ Examples:
 			if err != nil {
 				return fmt.Errorf("❌ Failed to list secrets: %v", err)
 			}
+			if out.Structured() {
+				return out.Print(cmd.OutOrStdout(), secretKeysListing(listPath, keys))
+			}
 
 			if len(keys) == 0 {
 				log.Info("No secrets found.")
@@ -78,6 +85,8 @@ Examples:
 			return nil
 		},
 	}
+	output.AddFlags(cmd, &out)
+	return cmd
 }
 
 func NewSecretGetCmd() *cobra.Command {
@@ -85,19 +94,24 @@ func NewSecretGetCmd() *cobra.Command {
 }
 
 var NewSecretGetCmdFunc = func() *cobra.Command {
+	var out output.Options
 	cmd := &cobra.Command{
 		Use:   "get <path>",
 		Short: "Read a secret from Vault",
 		Long: `Read all fields from a KV v2 secret.
 
-The path should include the 'secret/data/' prefix for KV v2.
+The path should include the 'secret/data/' prefix for KV v2. The fields are
+printed as JSON unless -o selects another format.
 
 Examples:
   stackctl vault secret get secret/data/ci/kubeconfig/home-lab
-  stackctl vault secret get secret/data/ci/app-config`,
+  stackctl vault secret get secret/data/ci/app-config -o yaml`,
 		Args:         cobra.ExactArgs(1),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Validate(); err != nil {
+				return fmt.Errorf("❌ %v", err)
+			}
 			flags.Resolve()
 			client, err := vaultpkg.ApiClient.EnvVaultClient()
 			if err != nil {
@@ -112,15 +126,13 @@ Examples:
 				return fmt.Errorf("❌ Failed to read secret: %v", err)
 			}
 
-			output, err := json.MarshalIndent(data, "", "  ")
-			if err != nil {
-				return fmt.Errorf("❌ Failed to format output: %v", err)
+			if !out.Structured() {
+				out.Format = output.JSON
 			}
-
-			fmt.Println(string(output))
-			return nil
+			return out.Print(cmd.OutOrStdout(), fieldsListing(data))
 		},
 	}
+	output.AddFlags(cmd, &out)
 
 	// Adicionando suporte para execução via TUI (run.Command.Execute)
 	originalRunE := cmd.RunE
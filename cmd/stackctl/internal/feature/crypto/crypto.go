@@ -13,9 +13,26 @@ import (
 // In a real production app, this might be obfuscated or injected at build time.
 const masterKeyHex = "3e6f663162643761386236623136653235336130386638316135393237306364"
 
+// KeySize is the length of the keys returned by NewKey.
+const KeySize = 32
+
+// NewKey returns a random AES-256 key.
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
 // Encrypt encrypts a string using AES-GCM
 func Encrypt(plainText string) (string, error) {
 	key, _ := hex.DecodeString(masterKeyHex)
+	return EncryptWithKey(key, plainText)
+}
+
+// EncryptWithKey encrypts a string using AES-GCM and the given key
+func EncryptWithKey(key []byte, plainText string) (string, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", err
@@ -38,6 +55,11 @@ func Encrypt(plainText string) (string, error) {
 // Decrypt decrypts a hex encoded string using AES-GCM
 func Decrypt(cipherTextHex string) (string, error) {
 	key, _ := hex.DecodeString(masterKeyHex)
+	return DecryptWithKey(key, cipherTextHex)
+}
+
+// DecryptWithKey decrypts a hex encoded string using AES-GCM and the given key
+func DecryptWithKey(key []byte, cipherTextHex string) (string, error) {
 	cipherText, err := hex.DecodeString(cipherTextHex)
 	if err != nil {
 		return "", err
@@ -25,3 +25,21 @@ func TestDecrypt_InvalidHex(t *testing.T) {
 		t.Error("expected error for invalid hex")
 	}
 }
+
+func TestEncryptWithKey(t *testing.T) {
+	key, err := NewKey()
+	if err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+	encrypted, err := EncryptWithKey(key, "secret-message")
+	if err != nil {
+		t.Fatalf("failed to encrypt: %v", err)
+	}
+
+	if decrypted, err := DecryptWithKey(key, encrypted); err != nil || decrypted != "secret-message" {
+		t.Errorf("expected secret-message, got %q (%v)", decrypted, err)
+	}
+	if _, err := Decrypt(encrypted); err == nil {
+		t.Error("expected the default key to fail")
+	}
+}
@@ -0,0 +1,447 @@
+package kubeconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/env"
+)
+
+const (
+	// BackupDirEnvVar stores backups in a dedicated directory instead of
+	// next to the kubeconfig.
+	BackupDirEnvVar = "STACK_CTL_KUBECONFIG_BACKUP_DIR"
+	// BackupKeepEnvVar is the number of backups kept per kubeconfig file.
+	BackupKeepEnvVar = "STACK_CTL_KUBECONFIG_BACKUP_KEEP"
+	// BackupMaxAgeEnvVar is the maximum backup age (e.g. "720h" or "30d").
+	BackupMaxAgeEnvVar = "STACK_CTL_KUBECONFIG_BACKUP_MAX_AGE"
+
+	// DefaultBackupKeep is the number of backups kept when no policy is set.
+	DefaultBackupKeep = 10
+
+	backupInfix = ".backup."
+	// backupTimeFormat has microseconds so that writes within the same
+	// second get distinct backups.
+	backupTimeFormat = "20060102_150405.000000"
+	// legacyBackupTimeFormat names the backups written next to the
+	// kubeconfig by earlier versions. Only an explicit prune removes them.
+	legacyBackupTimeFormat = "20060102_150405"
+)
+
+// BackupPolicy controls where backups are written and how many are retained.
+type BackupPolicy struct {
+	// Dir is the backup directory; empty means next to the kubeconfig file.
+	Dir string
+	// Keep is the number of backups retained per file; 0 keeps all.
+	Keep int
+	// MaxAge removes backups older than this; 0 disables the age limit.
+	MaxAge time.Duration
+	// IncludeLegacy applies Keep and MaxAge to legacy backups too. Automatic
+	// retention leaves them alone; an explicit prune cleans them up.
+	IncludeLegacy bool
+}
+
+// BackupPolicyFromEnv reads the backup policy from the environment.
+// Invalid values are reported and replaced by the defaults.
+func BackupPolicyFromEnv() BackupPolicy {
+	policy := BackupPolicy{Keep: DefaultBackupKeep}
+	if dir, ok := env.Get(BackupDirEnvVar); ok {
+		policy.Dir = dir
+	}
+	if value, ok := env.Get(BackupKeepEnvVar); ok {
+		keep, err := strconv.Atoi(value)
+		if err != nil || keep < 0 {
+			log.Warnf("⚠️  Ignoring invalid %s=%q", BackupKeepEnvVar, value)
+		} else {
+			policy.Keep = keep
+		}
+	}
+	if value, ok := env.Get(BackupMaxAgeEnvVar); ok {
+		maxAge, err := ParseAge(value)
+		if err != nil {
+			log.Warnf("⚠️  Ignoring invalid %s=%q: %v", BackupMaxAgeEnvVar, value, err)
+		} else {
+			policy.MaxAge = maxAge
+		}
+	}
+	return policy
+}
+
+// ParseAge parses a duration, additionally accepting a day suffix ("30d").
+func ParseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", value)
+	}
+	return d, nil
+}
+
+// BackupEntry is a single backup of a kubeconfig file.
+type BackupEntry struct {
+	Path   string    `json:"path"`
+	Source string    `json:"source"`
+	Time   time.Time `json:"time"`
+	Size   int64     `json:"size"`
+	// Legacy marks a backup named by an earlier version, which retention
+	// leaves alone.
+	Legacy bool `json:"legacy,omitempty"`
+}
+
+// dirFor returns the directory that holds the backups of source.
+func (p BackupPolicy) dirFor(source string) string {
+	if p.Dir != "" {
+		return p.Dir
+	}
+	return filepath.Dir(source)
+}
+
+// backupPrefix returns the file name prefix of the backups of source. It
+// holds a hash of the absolute source path, so files with the same name
+// sharing a backup directory keep their backups apart.
+func backupPrefix(source string) string {
+	abs, err := filepath.Abs(source)
+	if err != nil {
+		abs = source
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Base(source) + backupInfix + hex.EncodeToString(sum[:4]) + "."
+}
+
+// Backup creates a timestamped backup of the kubeconfig and applies the
+// retention policy from the environment.
+func Backup(path string) (string, error) {
+	policy := BackupPolicyFromEnv()
+
+	backupPath, err := createBackup(path, policy)
+	if err != nil {
+		return "", err
+	}
+
+	removed, err := PruneBackups(path, policy)
+	if err != nil {
+		log.Warnf("⚠️  Failed to prune old backups: %v", err)
+	} else if len(removed) > 0 {
+		log.Infof("🧹 Removed %d old kubeconfig backup(s)", len(removed))
+	}
+
+	return backupPath, nil
+}
+
+// BackupFiles backs up every existing file of a (possibly multi-file)
+// kubeconfig path and returns the created backup paths.
+func BackupFiles(path string) ([]string, error) {
+	var backups []string
+	for _, p := range SplitPaths(path) {
+		if _, err := os.Stat(p); os.IsNotExist(err) {
+			continue
+		}
+		backupPath, err := Backup(p)
+		if err != nil {
+			return backups, err
+		}
+		backups = append(backups, backupPath)
+	}
+	return backups, nil
+}
+
+func createBackup(path string, policy BackupPolicy) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	dir := policy.dirFor(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	// O_EXCL never overwrites an existing backup; on a clash the timestamp
+	// moves forward so the names still sort by creation.
+	prefix := backupPrefix(path)
+	ts := time.Now()
+	for {
+		backupPath := filepath.Join(dir, prefix+ts.Format(backupTimeFormat))
+		f, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			ts = ts.Add(time.Microsecond)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.Write(data)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return "", err
+		}
+		return backupPath, nil
+	}
+}
+
+// ListBackups returns the backups of every file in a (possibly multi-file)
+// kubeconfig path, newest first.
+func ListBackups(path string, policy BackupPolicy) ([]BackupEntry, error) {
+	var backups []BackupEntry
+	for _, source := range SplitPaths(path) {
+		entries, err := listBackupsOf(source, policy)
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, entries...)
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+	return backups, nil
+}
+
+func listBackupsOf(source string, policy BackupPolicy) ([]BackupEntry, error) {
+	dir := policy.dirFor(source)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	prefix := backupPrefix(source)
+	legacyPrefix := filepath.Base(source) + backupInfix
+	var entries []BackupEntry
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		var (
+			ts     time.Time
+			legacy bool
+		)
+		switch name := f.Name(); {
+		case strings.HasPrefix(name, prefix):
+			ts, err = time.ParseInLocation(backupTimeFormat, strings.TrimPrefix(name, prefix), time.Local)
+		case policy.Dir == "" && strings.HasPrefix(name, legacyPrefix) &&
+			len(name) == len(legacyPrefix)+len(legacyBackupTimeFormat):
+			// Earlier versions only wrote next to the kubeconfig, so a
+			// legacy name can be attributed to source there alone.
+			ts, err = time.ParseInLocation(legacyBackupTimeFormat, strings.TrimPrefix(name, legacyPrefix), time.Local)
+			legacy = true
+		default:
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, BackupEntry{
+			Path:   filepath.Join(dir, f.Name()),
+			Source: source,
+			Time:   ts,
+			Size:   info.Size(),
+			Legacy: legacy,
+		})
+	}
+	return entries, nil
+}
+
+// FindBackup resolves a backup reference: its 1-based position in
+// ListBackups, its timestamp, its file name or its full path.
+func FindBackup(path, ref string, policy BackupPolicy) (*BackupEntry, error) {
+	backups, err := ListBackups(path, policy)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) == 0 {
+		return nil, fmt.Errorf("no backups found")
+	}
+
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(backups) {
+			return nil, fmt.Errorf("backup #%d does not exist (have %d)", n, len(backups))
+		}
+		return &backups[n-1], nil
+	}
+
+	for i := range backups {
+		b := &backups[i]
+		if b.Path == ref || filepath.Base(b.Path) == ref ||
+			b.Time.Format(backupTimeFormat) == ref || b.Time.Format(legacyBackupTimeFormat) == ref {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("backup '%s' not found", ref)
+}
+
+// PruneBackups applies the retention policy to the backups of every file in
+// path and returns the removed backup paths. Legacy backups are only counted
+// and removed with IncludeLegacy.
+func PruneBackups(path string, policy BackupPolicy) ([]string, error) {
+	var removed []string
+	for _, source := range SplitPaths(path) {
+		listed, err := listBackupsOf(source, policy)
+		if err != nil {
+			return removed, err
+		}
+		var entries []BackupEntry
+		for _, entry := range listed {
+			if !entry.Legacy || policy.IncludeLegacy {
+				entries = append(entries, entry)
+			}
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Time.After(entries[j].Time)
+		})
+
+		now := time.Now()
+		for i, entry := range entries {
+			expired := policy.MaxAge > 0 && now.Sub(entry.Time) > policy.MaxAge
+			overflow := policy.Keep > 0 && i >= policy.Keep
+			if !expired && !overflow {
+				continue
+			}
+			if err := os.Remove(entry.Path); err != nil {
+				return removed, fmt.Errorf("failed to remove %s: %w", entry.Path, err)
+			}
+			removed = append(removed, entry.Path)
+		}
+	}
+	return removed, nil
+}
+
+// PrintBackups prints the backups of the kubeconfig as a table.
+func PrintBackups(path string) error {
+	backups, err := ListBackups(path, BackupPolicyFromEnv())
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		fmt.Println("No kubeconfig backups found")
+		return nil
+	}
+
+	fmt.Println("📦 Kubeconfig backups (newest first):")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "#\tCREATED\tAGE\tSIZE\tPATH")
+	for i, b := range backups {
+		age := time.Since(b.Time).Truncate(time.Minute)
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\n", i+1, b.Time.Format("2006-01-02 15:04:05"), age, b.Size, b.Path)
+	}
+	return w.Flush()
+}
+
+// ShowBackup prints the raw content of a backup.
+func ShowBackup(path, ref string) error {
+	backup, err := FindBackup(path, ref, BackupPolicyFromEnv())
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+// DiffBackup prints the differences between a backup and the current file it
+// was taken from. Credentials are masked.
+func DiffBackup(path, ref string) error {
+	backup, err := FindBackup(path, ref, BackupPolicyFromEnv())
+	if err != nil {
+		return err
+	}
+
+	old, err := redactedFileYAML(backup.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+	current, err := redactedFileYAML(backup.Source)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+
+	lines := DiffLines(old, current)
+	if !HasDiff(lines) {
+		fmt.Printf("✅ %s is identical to %s\n", backup.Source, filepath.Base(backup.Path))
+		return nil
+	}
+
+	fmt.Printf("--- %s\n+++ %s\n", backup.Path, backup.Source)
+	for _, line := range CompactDiff(lines, 3) {
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// redactedFileYAML loads a kubeconfig file and renders it with credentials masked.
+func redactedFileYAML(path string) (string, error) {
+	config, err := loadFile(path)
+	if err != nil {
+		return "", err
+	}
+	data, err := yaml.Marshal(RedactConfig(config))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// RestoreBackup replaces the file a backup was taken from with the backup's
+// content. The current file is backed up first so a restore can be undone.
+func RestoreBackup(path, ref string) (*BackupEntry, error) {
+	policy := BackupPolicyFromEnv()
+	backup, err := FindBackup(path, ref, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("backup is not a valid kubeconfig: %w", err)
+	}
+
+	err = withLock(backup.Source, func() error {
+		if _, err := os.Stat(backup.Source); err == nil {
+			current, err := createBackup(backup.Source, policy)
+			if err != nil {
+				return fmt.Errorf("failed to back up current kubeconfig: %w", err)
+			}
+			log.Infof("📦 Backed up current kubeconfig to: %s", current)
+		}
+
+		if err := writeFileAtomic(backup.Source, data, 0600); err != nil {
+			return fmt.Errorf("failed to write kubeconfig: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return backup, nil
+}
@@ -0,0 +1,262 @@
+package kubeconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeBackups creates backups of source in dir aged by the given offsets.
+func writeBackups(t *testing.T, dir, source string, ages ...time.Duration) []string {
+	t.Helper()
+	var paths []string
+	for _, age := range ages {
+		name := backupPrefix(source) + time.Now().Add(-age).Format(backupTimeFormat)
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte("apiVersion: v1\nkind: Config\n"), 0600); err != nil {
+			t.Fatalf("failed to write backup: %v", err)
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+func TestParseAge(t *testing.T) {
+	if d, err := ParseAge("30d"); err != nil || d != 30*24*time.Hour {
+		t.Errorf("expected 30 days, got %v (%v)", d, err)
+	}
+	if d, err := ParseAge("90m"); err != nil || d != 90*time.Minute {
+		t.Errorf("expected 90 minutes, got %v (%v)", d, err)
+	}
+	if _, err := ParseAge("soon"); err == nil {
+		t.Error("expected error for invalid age")
+	}
+}
+
+func TestPruneBackups_KeepAndMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "config")
+	writeBackups(t, dir, source, time.Hour, 2*time.Hour, 3*time.Hour, 48*time.Hour)
+
+	removed, err := PruneBackups(source, BackupPolicy{Keep: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(removed) != 1 {
+		t.Errorf("expected the oldest backup to be removed, got %v", removed)
+	}
+
+	removed, err = PruneBackups(source, BackupPolicy{MaxAge: 90 * time.Minute})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("expected two expired backups removed, got %v", removed)
+	}
+
+	backups, _ := ListBackups(source, BackupPolicy{})
+	if len(backups) != 1 {
+		t.Errorf("expected one backup left, got %d", len(backups))
+	}
+}
+
+func TestBackup_DedicatedDirAndRetention(t *testing.T) {
+	dir := t.TempDir()
+	backupDir := filepath.Join(dir, "backups")
+	source := filepath.Join(dir, "config")
+	if err := os.WriteFile(source, []byte("apiVersion: v1\nkind: Config\n"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := os.MkdirAll(backupDir, 0700); err != nil {
+		t.Fatalf("failed to create backup dir: %v", err)
+	}
+	writeBackups(t, backupDir, source, time.Hour, 2*time.Hour)
+
+	t.Setenv(BackupDirEnvVar, backupDir)
+	t.Setenv(BackupKeepEnvVar, "2")
+
+	backupPath, err := Backup(source)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filepath.Dir(backupPath) != backupDir {
+		t.Errorf("expected backup in %s, got %s", backupDir, backupPath)
+	}
+
+	backups, err := ListBackups(source, BackupPolicyFromEnv())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(backups) != 2 || backups[0].Path != backupPath {
+		t.Errorf("expected newest two backups to be kept, got %+v", backups)
+	}
+	if matches, _ := filepath.Glob(source + backupInfix + "*"); len(matches) != 0 {
+		t.Errorf("expected no backups next to the kubeconfig, got %v", matches)
+	}
+}
+
+func TestFindBackup_References(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "config")
+	paths := writeBackups(t, dir, source, time.Hour, 2*time.Hour)
+
+	byIndex, err := FindBackup(source, "2", BackupPolicy{})
+	if err != nil || byIndex.Path != paths[1] {
+		t.Errorf("expected #2 to be the older backup, got %+v (%v)", byIndex, err)
+	}
+
+	ts := byIndex.Time.Format(backupTimeFormat)
+	byTime, err := FindBackup(source, ts, BackupPolicy{})
+	if err != nil || byTime.Path != paths[1] {
+		t.Errorf("expected lookup by timestamp, got %+v (%v)", byTime, err)
+	}
+
+	if _, err := FindBackup(source, "3", BackupPolicy{}); err == nil {
+		t.Error("expected error for out-of-range backup number")
+	}
+}
+
+func TestRestoreBackup_BacksUpCurrentFile(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "config")
+	t.Setenv(BackupKeepEnvVar, "0")
+
+	old := "apiVersion: v1\nkind: Config\ncurrent-context: old\n"
+	oldBackup := filepath.Join(dir, backupPrefix(source)+time.Now().Add(-time.Hour).Format(backupTimeFormat))
+	if err := os.WriteFile(oldBackup, []byte(old), 0600); err != nil {
+		t.Fatalf("failed to write backup: %v", err)
+	}
+	if err := os.WriteFile(source, []byte("apiVersion: v1\nkind: Config\ncurrent-context: new\n"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if _, err := RestoreBackup(source, filepath.Base(oldBackup)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	restored, _ := os.ReadFile(source)
+	if string(restored) != old {
+		t.Errorf("expected backup content restored, got %q", restored)
+	}
+	backups, _ := ListBackups(source, BackupPolicy{})
+	if len(backups) != 2 {
+		t.Errorf("expected the replaced file to be backed up, got %d backups", len(backups))
+	}
+}
+
+func TestBackup_SharedDirKeepsSourcesApart(t *testing.T) {
+	dir := t.TempDir()
+	backupDir := filepath.Join(dir, "backups")
+	home := filepath.Join(dir, "home", "config")
+	work := filepath.Join(dir, "work", "config")
+	for _, p := range []string{home, work} {
+		if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte("apiVersion: v1\nkind: Config\ncurrent-context: "+p+"\n"), 0600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+	}
+	t.Setenv(BackupDirEnvVar, backupDir)
+	t.Setenv(BackupKeepEnvVar, "1")
+
+	for i := 0; i < 2; i++ {
+		if _, err := BackupFiles(home + string(os.PathListSeparator) + work); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	for _, source := range []string{home, work} {
+		backups, err := ListBackups(source, BackupPolicyFromEnv())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(backups) != 1 || backups[0].Source != source {
+			t.Fatalf("expected one backup of %s, got %+v", source, backups)
+		}
+		data, _ := os.ReadFile(backups[0].Path)
+		if !strings.Contains(string(data), source) {
+			t.Errorf("expected the backup of %s to hold its content, got %q", source, data)
+		}
+	}
+}
+
+func TestBackup_SameSecondDoesNotOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "config")
+	if err := os.WriteFile(source, []byte("apiVersion: v1\nkind: Config\n"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 5; i++ {
+		p, err := createBackup(source, BackupPolicy{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if seen[p] {
+			t.Fatalf("backup %s written twice", p)
+		}
+		seen[p] = true
+	}
+	if backups, _ := ListBackups(source, BackupPolicy{}); len(backups) != 5 {
+		t.Errorf("expected 5 backups, got %d", len(backups))
+	}
+}
+
+func TestPruneBackups_KeepsLegacyBackups(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "config")
+	legacy := source + backupInfix + time.Now().Add(-72*time.Hour).Format(legacyBackupTimeFormat)
+	if err := os.WriteFile(legacy, []byte("apiVersion: v1\nkind: Config\n"), 0600); err != nil {
+		t.Fatalf("failed to write backup: %v", err)
+	}
+	writeBackups(t, dir, source, time.Hour, 2*time.Hour)
+
+	removed, err := PruneBackups(source, BackupPolicy{Keep: 1, MaxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(removed) != 1 {
+		t.Errorf("expected only the older new-style backup removed, got %v", removed)
+	}
+	backups, _ := ListBackups(source, BackupPolicy{})
+	if len(backups) != 2 || !backups[1].Legacy || backups[1].Path != legacy {
+		t.Errorf("expected the legacy backup to be listed and kept, got %+v", backups)
+	}
+}
+
+func TestPruneBackups_IncludeLegacy(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "config")
+	var legacy []string
+	for i := 1; i <= 20; i++ {
+		p := source + backupInfix + time.Now().Add(-time.Duration(i)*time.Hour).Format(legacyBackupTimeFormat)
+		if err := os.WriteFile(p, []byte("apiVersion: v1\nkind: Config\n"), 0600); err != nil {
+			t.Fatalf("failed to write backup: %v", err)
+		}
+		legacy = append(legacy, p)
+	}
+
+	removed, err := PruneBackups(source, BackupPolicy{Keep: 5, IncludeLegacy: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(removed) != 15 {
+		t.Errorf("expected 15 legacy backups removed, got %d", len(removed))
+	}
+	backups, _ := ListBackups(source, BackupPolicy{})
+	if len(backups) != 5 || backups[0].Path != legacy[0] {
+		t.Errorf("expected the 5 newest legacy backups kept, got %+v", backups)
+	}
+
+	removed, err = PruneBackups(source, BackupPolicy{MaxAge: 150 * time.Minute, IncludeLegacy: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(removed) != 3 {
+		t.Errorf("expected 3 expired legacy backups removed, got %v", removed)
+	}
+}
@@ -3,74 +3,389 @@ package kubeconfig
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
-// CleanDuplicates detects and removes duplicate entries from kubeconfig with user confirmation
-func CleanDuplicates(path string) error {
+// Duplicate is a cluster, context or user name defined more than once.
+type Duplicate struct {
+	Kind string // cluster, context or user
+	Name string
+	// Count is the number of definitions of the name.
+	Count int
+	// Variants are the distinct definitions in file order. kubectl uses the
+	// first one.
+	Variants []interface{}
+}
+
+// Identical reports whether every definition has the same content, so the
+// extra ones can be dropped without losing data.
+func (d Duplicate) Identical() bool {
+	return len(d.Variants) == 1
+}
+
+// DuplicateResolution says how a conflicting duplicate is cleaned.
+type DuplicateResolution struct {
+	// Keep is the index of the variant kept under the name.
+	Keep int
+	// Rename keeps every variant, renaming all but the first to a free
+	// "<name>-N" name.
+	Rename bool
+}
+
+// CleanOptions controls CleanDuplicates.
+type CleanOptions struct {
+	// Yes skips the confirmation and prompts; conflicts are resolved with
+	// OnConflict.
+	Yes bool
+	// OnConflict resolves conflicting duplicates: ConflictRename (the default
+	// with Yes), ConflictKeep (keep the first definition) or ConflictPrompt
+	// (ask for each one, the default without Yes).
+	OnConflict ConflictStrategy
+	// In holds the answers to the confirmation and prompts (default stdin).
+	In io.Reader
+}
+
+// CleanReport summarizes what CleanDuplicates did.
+type CleanReport struct {
+	Removed int // definitions dropped
+	Renamed int // definitions kept under a new name
+	// Orphans are the renamed clusters and users no context references
+	// ("cluster/<name>" or "user/<name>").
+	Orphans []string
+}
+
+// FindDuplicates returns the duplicated names of a config, clusters and users
+// first.
+func FindDuplicates(config *Config) []Duplicate {
+	var dups []Duplicate
+	dups = append(dups, findDuplicates("cluster", config.Clusters)...)
+	dups = append(dups, findDuplicates("user", config.Users)...)
+	dups = append(dups, findDuplicates("context", config.Contexts)...)
+	return dups
+}
+
+func findDuplicates[T any](kind string, items []T) []Duplicate {
+	index := make(map[string]int)
+	var dups []Duplicate
+	for _, item := range items {
+		name := entryName(item)
+		i, seen := index[name]
+		if !seen {
+			index[name] = len(dups)
+			dups = append(dups, Duplicate{Kind: kind, Name: name, Count: 1, Variants: []interface{}{item}})
+			continue
+		}
+		dups[i].Count++
+		if !containsVariant(dups[i].Variants, item) {
+			dups[i].Variants = append(dups[i].Variants, item)
+		}
+	}
+
+	out := dups[:0]
+	for _, d := range dups {
+		if d.Count > 1 {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func containsVariant(variants []interface{}, item interface{}) bool {
+	for _, v := range variants {
+		if reflect.DeepEqual(v, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// CleanDuplicates removes duplicate entries from kubeconfig. Identical
+// duplicates are dropped; conflicting ones are shown side by side and
+// resolved as opts says.
+func CleanDuplicates(path string, opts CleanOptions) error {
 	config, err := Load(path)
 	if err != nil {
 		return fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
 
-	// Count duplicates
-	clusterDups := countDuplicates(config.Clusters)
-	contextDups := countDuplicates(config.Contexts)
-	userDups := countDuplicates(config.Users)
-
-	totalDups := clusterDups + contextDups + userDups
-
-	if totalDups == 0 {
+	dups := FindDuplicates(config)
+	if len(dups) == 0 {
 		fmt.Println("✅ No duplicate entries found in kubeconfig")
 		return nil
 	}
+	printDuplicates(os.Stdout, dups)
 
-	// Show duplicates found
-	fmt.Printf("⚠️  Found %d duplicate entries:\n", totalDups)
-	if clusterDups > 0 {
-		fmt.Printf("  - %d duplicate clusters\n", clusterDups)
+	strategy := opts.OnConflict
+	if strategy == "" {
+		strategy = ConflictPrompt
+		if opts.Yes {
+			strategy = ConflictRename
+		}
 	}
-	if contextDups > 0 {
-		fmt.Printf("  - %d duplicate contexts\n", contextDups)
+
+	// One reader for every answer, so piped input is not lost to buffering.
+	in := opts.In
+	if in == nil {
+		in = os.Stdin
 	}
-	if userDups > 0 {
-		fmt.Printf("  - %d duplicate users\n", userDups)
+	reader := bufio.NewReader(in)
+
+	if !opts.Yes {
+		fmt.Print("\n🧹 Do you want to clean these duplicates? (yes/no): ")
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "yes" && response != "y" {
+			fmt.Println("❌ Cleanup cancelled")
+			return nil
+		}
+	} else if strategy == ConflictPrompt {
+		return fmt.Errorf("cannot prompt for conflicts with --yes")
 	}
 
-	// Ask for confirmation
-	fmt.Print("\n🧹 Do you want to clean these duplicates? (yes/no): ")
-	reader := bufio.NewReader(os.Stdin)
-	response, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("failed to read input: %w", err)
+	resolutions := make(map[string]DuplicateResolution)
+	for _, d := range dups {
+		if d.Identical() {
+			continue
+		}
+		var resolution DuplicateResolution
+		switch strategy {
+		case ConflictRename:
+			resolution.Rename = true
+		case ConflictKeep:
+		case ConflictPrompt:
+			if resolution, err = promptDuplicateFunc(reader, d); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported conflict strategy %q", strategy)
+		}
+		resolutions[d.Kind+"/"+d.Name] = resolution
 	}
 
-	response = strings.TrimSpace(strings.ToLower(response))
-	if response != "yes" && response != "y" {
-		fmt.Println("❌ Cleanup cancelled")
+	// Reload under the lock: the file may have changed while waiting for input
+	return withLock(path, func() error {
+		config, err := Load(path)
+		if err != nil {
+			return fmt.Errorf("failed to load kubeconfig: %w", err)
+		}
+		if !reflect.DeepEqual(FindDuplicates(config), dups) {
+			return fmt.Errorf("kubeconfig changed while cleaning, run clean again")
+		}
+
+		// Backup before cleaning
+		backupPaths, err := BackupFiles(path)
+		if err != nil {
+			return fmt.Errorf("failed to create backup: %w", err)
+		}
+		for _, backupPath := range backupPaths {
+			fmt.Printf("📦 Created backup: %s\n", backupPath)
+		}
+
+		cleanedConfig, report := ResolveDuplicates(config, resolutions)
+
+		// Save cleaned config
+		if err := Save(path, cleanedConfig); err != nil {
+			return fmt.Errorf("failed to save cleaned kubeconfig: %w", err)
+		}
+
+		fmt.Printf("✅ Removed %d duplicate entries and renamed %d conflicting ones\n", report.Removed, report.Renamed)
+		fmt.Println("💾 Kubeconfig has been cleaned and saved")
+		if len(report.Orphans) > 0 {
+			fmt.Printf("⚠️  No context uses the renamed %s yet. Point a context at them with "+
+				"'kubectl config set-context <context> --cluster <name> --user <name>' "+
+				"or remove them with 'stackctl kubeconfig prune'\n", strings.Join(report.Orphans, ", "))
+		}
+
 		return nil
+	})
+}
+
+// ResolveDuplicates removes duplicate entries from config. Identical
+// definitions are dropped; conflicting ones follow resolutions, keyed by
+// "<kind>/<name>", and keep the first definition when absent.
+func ResolveDuplicates(config *Config, resolutions map[string]DuplicateResolution) (*Config, CleanReport) {
+	var report CleanReport
+	cleaned := *config
+	cleaned.Clusters = resolveEntries("cluster", config.Clusters, resolutions, &report,
+		func(c *Cluster, name string) { c.Name = name })
+	cleaned.Users = resolveEntries("user", config.Users, resolutions, &report,
+		func(u *User, name string) { u.Name = name })
+	cleaned.Contexts = resolveEntries("context", config.Contexts, resolutions, &report,
+		func(c *Context, name string) { c.Name = name })
+
+	// Contexts keep pointing at the original names, so renamed clusters and
+	// users start out unused.
+	original := make(map[string]bool)
+	for _, c := range config.Clusters {
+		original["cluster/"+c.Name] = true
+	}
+	for _, u := range config.Users {
+		original["user/"+u.Name] = true
+	}
+	used := make(map[string]bool)
+	for _, ctx := range cleaned.Contexts {
+		used["cluster/"+ctx.Context.Cluster] = true
+		used["user/"+ctx.Context.User] = true
+	}
+	for _, c := range cleaned.Clusters {
+		if key := "cluster/" + c.Name; !original[key] && !used[key] {
+			report.Orphans = append(report.Orphans, key)
+		}
+	}
+	for _, u := range cleaned.Users {
+		if key := "user/" + u.Name; !original[key] && !used[key] {
+			report.Orphans = append(report.Orphans, key)
+		}
 	}
+	return &cleaned, report
+}
 
-	// Backup before cleaning
-	backupPath, err := Backup(path)
-	if err != nil {
-		return fmt.Errorf("failed to create backup: %w", err)
+func resolveEntries[T any](kind string, items []T, resolutions map[string]DuplicateResolution,
+	report *CleanReport, rename func(*T, string)) []T {
+	variants := make(map[string][]interface{})
+	for _, d := range findDuplicates(kind, items) {
+		variants[d.Name] = d.Variants
+	}
+	taken := make(map[string]bool)
+	for _, item := range items {
+		taken[entryName(item)] = true
+	}
+
+	out := make([]T, 0, len(items))
+	kept := make(map[string][]interface{})
+	for _, item := range items {
+		name := entryName(item)
+		if _, dup := variants[name]; !dup {
+			out = append(out, item)
+			continue
+		}
+
+		resolution := resolutions[kind+"/"+name]
+		switch {
+		case len(kept[name]) == 0:
+			// The first definition holds the place of the one that is kept.
+			if !resolution.Rename && resolution.Keep > 0 && resolution.Keep < len(variants[name]) {
+				item = variants[name][resolution.Keep].(T)
+			}
+			kept[name] = append(kept[name], item)
+			out = append(out, item)
+		case resolution.Rename && !containsVariant(kept[name], item):
+			kept[name] = append(kept[name], item)
+			newName := uniqueName(name, taken)
+			taken[newName] = true
+			rename(&item, newName)
+			out = append(out, item)
+			report.Renamed++
+		default:
+			report.Removed++
+		}
 	}
-	fmt.Printf("📦 Created backup: %s\n", backupPath)
+	return out
+}
 
-	// Clean duplicates
-	cleanedConfig := Deduplicate(config)
+// printDuplicates lists the duplicates and shows each conflict side by side.
+func printDuplicates(w io.Writer, dups []Duplicate) {
+	identical := 0
+	for _, d := range dups {
+		if d.Identical() {
+			identical++
+		}
+	}
+	_, _ = fmt.Fprintf(w, "⚠️  Found %d duplicated names: %d identical (safe to drop), %d conflicting\n",
+		len(dups), identical, len(dups)-identical)
+	for _, d := range dups {
+		if d.Identical() {
+			_, _ = fmt.Fprintf(w, "  = %-8s %s (%d identical definitions)\n", d.Kind, d.Name, d.Count)
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "  ! %-8s %s (%d definitions, %d different)\n", d.Kind, d.Name, d.Count, len(d.Variants))
+		for i := 1; i < len(d.Variants); i++ {
+			_, _ = fmt.Fprintf(w, "\n    #1 vs #%d\n", i+1)
+			for _, line := range SideBySideLines(entryYAML(d.Variants[0]), entryYAML(d.Variants[i]), 40) {
+				_, _ = fmt.Fprintf(w, "    %s\n", line)
+			}
+		}
+	}
+}
 
-	// Save cleaned config
-	if err := Save(path, cleanedConfig); err != nil {
-		return fmt.Errorf("failed to save cleaned kubeconfig: %w", err)
+// SideBySideLines renders the diff of a and b in two columns of width
+// characters. Changed lines are marked with "|", lines only in a with "<"
+// and lines only in b with ">".
+func SideBySideLines(a, b string, width int) []string {
+	var out []string
+	var removed, added []string
+	flush := func() {
+		for i := 0; i < len(removed) || i < len(added); i++ {
+			left, right, mark := "", "", "|"
+			switch {
+			case i >= len(added):
+				left, mark = removed[i], "<"
+			case i >= len(removed):
+				right, mark = added[i], ">"
+			default:
+				left, right = removed[i], added[i]
+			}
+			out = append(out, sideBySideRow(left, right, mark, width))
+		}
+		removed, added = nil, nil
 	}
+	for _, line := range DiffLines(a, b) {
+		switch {
+		case strings.HasPrefix(line, "- "):
+			removed = append(removed, line[2:])
+		case strings.HasPrefix(line, "+ "):
+			added = append(added, line[2:])
+		default:
+			flush()
+			out = append(out, sideBySideRow(line[2:], line[2:], " ", width))
+		}
+	}
+	flush()
+	return out
+}
+
+func sideBySideRow(left, right, mark string, width int) string {
+	if len(left) > width {
+		left = left[:width-1] + "…"
+	}
+	return strings.TrimRight(fmt.Sprintf("%-*s %s %s", width, left, mark, right), " ")
+}
 
-	fmt.Printf("✅ Cleaned %d duplicate entries from kubeconfig\n", totalDups)
-	fmt.Println("💾 Kubeconfig has been cleaned and saved")
+// promptDuplicateFunc asks how to resolve a conflicting duplicate, reading
+// the answer from in.
+var promptDuplicateFunc = func(in *bufio.Reader, d Duplicate) (DuplicateResolution, error) {
+	return promptDuplicate(in, os.Stdout, d)
+}
 
-	return nil
+func promptDuplicate(reader *bufio.Reader, out io.Writer, d Duplicate) (DuplicateResolution, error) {
+	for {
+		_, _ = fmt.Fprintf(out, "❓ %s '%s': keep [1-%d], re[n]ame the others, [a]bort? ", d.Kind, d.Name, len(d.Variants))
+		response, err := reader.ReadString('\n')
+		if err != nil && response == "" {
+			return DuplicateResolution{}, fmt.Errorf("failed to read input: %w", err)
+		}
+		response = strings.TrimSpace(strings.ToLower(response))
+		switch response {
+		case "n", "rename":
+			return DuplicateResolution{Rename: true}, nil
+		case "a", "abort":
+			return DuplicateResolution{}, fmt.Errorf("cleanup aborted")
+		}
+		if n, convErr := strconv.Atoi(response); convErr == nil && n >= 1 && n <= len(d.Variants) {
+			return DuplicateResolution{Keep: n - 1}, nil
+		}
+		if err != nil {
+			return DuplicateResolution{}, fmt.Errorf("failed to read input: %w", err)
+		}
+	}
 }
 
 // countDuplicates counts duplicate entries in a slice
@@ -0,0 +1,176 @@
+package kubeconfig
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// duplicatedConfig has an identical duplicate cluster and a user defined
+// twice with different tokens.
+func duplicatedConfig() *Config {
+	return &Config{
+		APIVersion: "v1",
+		Kind:       "Config",
+		Clusters: []Cluster{
+			{Name: "prod", Cluster: ClusterConfig{Server: "https://prod:6443"}},
+			{Name: "prod", Cluster: ClusterConfig{Server: "https://prod:6443"}},
+		},
+		Users: []User{
+			{Name: "admin", User: UserConfig{Token: "first"}},
+			{Name: "admin", User: UserConfig{Token: "second"}},
+			{Name: "admin", User: UserConfig{Token: "first"}},
+		},
+		Contexts: []Context{{Name: "prod", Context: ContextConfig{Cluster: "prod", User: "admin"}}},
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	dups := FindDuplicates(duplicatedConfig())
+	if len(dups) != 2 {
+		t.Fatalf("expected 2 duplicated names, got %+v", dups)
+	}
+	if dups[0].Kind != "cluster" || !dups[0].Identical() || dups[0].Count != 2 {
+		t.Errorf("expected an identical cluster duplicate, got %+v", dups[0])
+	}
+	if dups[1].Kind != "user" || dups[1].Identical() || dups[1].Count != 3 || len(dups[1].Variants) != 2 {
+		t.Errorf("expected a conflicting user duplicate with 2 variants, got %+v", dups[1])
+	}
+}
+
+func TestResolveDuplicates(t *testing.T) {
+	t.Run("keeps the first definition by default", func(t *testing.T) {
+		cleaned, report := ResolveDuplicates(duplicatedConfig(), nil)
+		if len(cleaned.Clusters) != 1 || len(cleaned.Users) != 1 || cleaned.Users[0].User.Token != "first" {
+			t.Errorf("unexpected result: %+v", cleaned)
+		}
+		if report.Removed != 3 || report.Renamed != 0 {
+			t.Errorf("unexpected report %+v", report)
+		}
+	})
+
+	t.Run("keeps the chosen definition in place of the first", func(t *testing.T) {
+		cleaned, _ := ResolveDuplicates(duplicatedConfig(), map[string]DuplicateResolution{"user/admin": {Keep: 1}})
+		if len(cleaned.Users) != 1 || cleaned.Users[0].User.Token != "second" {
+			t.Errorf("expected the second definition to be kept, got %+v", cleaned.Users)
+		}
+	})
+
+	t.Run("renames the other definitions", func(t *testing.T) {
+		cleaned, report := ResolveDuplicates(duplicatedConfig(), map[string]DuplicateResolution{"user/admin": {Rename: true}})
+		if len(cleaned.Users) != 2 || cleaned.Users[0].Name != "admin" || cleaned.Users[1].Name != "admin-1" ||
+			cleaned.Users[1].User.Token != "second" {
+			t.Errorf("unexpected users %+v", cleaned.Users)
+		}
+		if report.Removed != 2 || report.Renamed != 1 {
+			t.Errorf("unexpected report %+v", report)
+		}
+		if len(report.Orphans) != 1 || report.Orphans[0] != "user/admin-1" {
+			t.Errorf("expected the renamed user to be reported as unused, got %v", report.Orphans)
+		}
+	})
+}
+
+func TestCleanDuplicates_Yes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	t.Setenv(BackupDirEnvVar, t.TempDir())
+	// Save drops duplicates, so write the file as another tool would.
+	data, err := yaml.Marshal(duplicatedConfig())
+	if err != nil {
+		t.Fatalf("failed to marshal config: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if err := CleanDuplicates(path, CleanOptions{Yes: true, OnConflict: ConflictPrompt}); err == nil {
+		t.Error("expected an error when prompting with --yes")
+	}
+	if err := CleanDuplicates(path, CleanOptions{Yes: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if len(FindDuplicates(config)) != 0 {
+		t.Errorf("expected no duplicates left, got %+v", FindDuplicates(config))
+	}
+	if len(config.Users) != 2 || config.Users[1].Name != "admin-1" {
+		t.Errorf("expected the conflicting user to be renamed, got %+v", config.Users)
+	}
+}
+
+func TestCleanDuplicates_PipedAnswers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	t.Setenv(BackupDirEnvVar, t.TempDir())
+	data, err := yaml.Marshal(duplicatedConfig())
+	if err != nil {
+		t.Fatalf("failed to marshal config: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if err := CleanDuplicates(path, CleanOptions{In: strings.NewReader("y\n2\n")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if len(config.Users) != 1 || config.Users[0].User.Token != "second" {
+		t.Errorf("expected the second user definition to be kept, got %+v", config.Users)
+	}
+}
+
+func TestPrintDuplicates(t *testing.T) {
+	var out bytes.Buffer
+	printDuplicates(&out, FindDuplicates(duplicatedConfig()))
+	text := out.String()
+	if !strings.Contains(text, "1 identical (safe to drop), 1 conflicting") {
+		t.Errorf("expected a summary, got:\n%s", text)
+	}
+	if strings.Contains(text, "first") || strings.Contains(text, "second") {
+		t.Errorf("expected the tokens to be redacted, got:\n%s", text)
+	}
+	if !strings.Contains(text, " | ") {
+		t.Errorf("expected a side-by-side diff, got:\n%s", text)
+	}
+}
+
+func TestSideBySideLines(t *testing.T) {
+	lines := SideBySideLines("a: 1\nb: 2\n", "a: 1\nb: 3\nc: 4\n", 10)
+	expected := []string{
+		"a: 1         a: 1",
+		"b: 2       | b: 3",
+		"           > c: 4",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected diff:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestPromptDuplicate(t *testing.T) {
+	d := FindDuplicates(duplicatedConfig())[1]
+	var out bytes.Buffer
+
+	resolution, err := promptDuplicate(bufio.NewReader(strings.NewReader("9\n2\n")), &out, d)
+	if err != nil || resolution.Keep != 1 || resolution.Rename {
+		t.Errorf("expected to keep the second definition, got %+v (%v)", resolution, err)
+	}
+	resolution, err = promptDuplicate(bufio.NewReader(strings.NewReader("n\n")), &out, d)
+	if err != nil || !resolution.Rename {
+		t.Errorf("expected a rename, got %+v (%v)", resolution, err)
+	}
+	if _, err := promptDuplicate(bufio.NewReader(strings.NewReader("a\n")), &out, d); err == nil {
+		t.Error("expected abort to return an error")
+	}
+}
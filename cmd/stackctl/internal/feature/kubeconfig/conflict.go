@@ -0,0 +1,347 @@
+package kubeconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ConflictStrategy decides what happens when an imported cluster, context or
+// user has the same name as an existing entry but different content.
+type ConflictStrategy string
+
+const (
+	// ConflictReplace overwrites the existing entry (the historical behavior).
+	ConflictReplace ConflictStrategy = "replace"
+	// ConflictKeep keeps the existing entry and drops the imported one.
+	ConflictKeep ConflictStrategy = "keep"
+	// ConflictRename imports the entry under a free name (e.g. "default-1").
+	ConflictRename ConflictStrategy = "rename"
+	// ConflictFail aborts the import without touching the kubeconfig.
+	ConflictFail ConflictStrategy = "fail"
+	// ConflictPrompt asks interactively for each conflict.
+	ConflictPrompt ConflictStrategy = "prompt"
+)
+
+// ConflictStrategies lists the accepted --on-conflict values.
+var ConflictStrategies = []ConflictStrategy{ConflictReplace, ConflictKeep, ConflictRename, ConflictFail, ConflictPrompt}
+
+// ParseConflictStrategy validates a strategy name. An empty value means replace.
+func ParseConflictStrategy(value string) (ConflictStrategy, error) {
+	if value == "" {
+		return ConflictReplace, nil
+	}
+	for _, s := range ConflictStrategies {
+		if strings.EqualFold(value, string(s)) {
+			return s, nil
+		}
+	}
+	names := make([]string, 0, len(ConflictStrategies))
+	for _, s := range ConflictStrategies {
+		names = append(names, string(s))
+	}
+	return "", fmt.Errorf("invalid conflict strategy %q (expected one of: %s)", value, strings.Join(names, ", "))
+}
+
+// ChangeAction describes what a merge does with a single entry.
+type ChangeAction string
+
+const (
+	ActionAdded     ChangeAction = "added"
+	ActionReplaced  ChangeAction = "replaced"
+	ActionRenamed   ChangeAction = "renamed"
+	ActionKept      ChangeAction = "kept"
+	ActionUnchanged ChangeAction = "unchanged"
+	ActionConflict  ChangeAction = "conflict"
+)
+
+// Change is a single entry of a MergeReport.
+type Change struct {
+	Kind    string // cluster, context or user
+	Name    string
+	Action  ChangeAction
+	NewName string // set when Action is ActionRenamed
+
+	before interface{}
+	after  interface{}
+}
+
+// MergeReport lists every change a merge made or would make.
+type MergeReport struct {
+	Changes []Change
+
+	// CurrentContext is set when the merge changes current-context.
+	CurrentContext string
+}
+
+// Count returns the number of changes with the given action.
+func (r *MergeReport) Count(action ChangeAction) int {
+	n := 0
+	for _, c := range r.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Print writes the report as a structured diff.
+func (r *MergeReport) Print(w io.Writer) {
+	if len(r.Changes) == 0 {
+		_, _ = fmt.Fprintln(w, "  (no entries to import)")
+	}
+	for _, c := range r.Changes {
+		switch c.Action {
+		case ActionAdded:
+			_, _ = fmt.Fprintf(w, "  + %-8s %s\n", c.Kind, c.Name)
+		case ActionReplaced:
+			_, _ = fmt.Fprintf(w, "  ~ %-8s %s (replaced)\n", c.Kind, c.Name)
+			for _, line := range DiffLines(entryYAML(c.before), entryYAML(c.after)) {
+				_, _ = fmt.Fprintf(w, "      %s\n", line)
+			}
+		case ActionRenamed:
+			_, _ = fmt.Fprintf(w, "  + %-8s %s (renamed from '%s')\n", c.Kind, c.NewName, c.Name)
+		case ActionKept:
+			_, _ = fmt.Fprintf(w, "  = %-8s %s (kept existing)\n", c.Kind, c.Name)
+		case ActionUnchanged:
+			_, _ = fmt.Fprintf(w, "    %-8s %s (unchanged)\n", c.Kind, c.Name)
+		case ActionConflict:
+			_, _ = fmt.Fprintf(w, "  ! %-8s %s (conflict)\n", c.Kind, c.Name)
+		}
+	}
+	if r.CurrentContext != "" {
+		_, _ = fmt.Fprintf(w, "  ~ current-context → %s\n", r.CurrentContext)
+	}
+	_, _ = fmt.Fprintf(w, "\n%d added, %d replaced, %d renamed, %d kept, %d unchanged, %d conflicts\n",
+		r.Count(ActionAdded), r.Count(ActionReplaced), r.Count(ActionRenamed),
+		r.Count(ActionKept), r.Count(ActionUnchanged), r.Count(ActionConflict))
+}
+
+// ConflictError is returned when the fail strategy finds conflicting entries.
+type ConflictError struct {
+	Conflicts []Change
+}
+
+func (e *ConflictError) Error() string {
+	names := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		names = append(names, fmt.Sprintf("%s '%s'", c.Kind, c.Name))
+	}
+	return fmt.Sprintf("entries already exist with different content: %s", strings.Join(names, ", "))
+}
+
+// MergeOptions controls how MergeWithOptions resolves conflicts.
+type MergeOptions struct {
+	OnConflict ConflictStrategy
+
+	// Prompt resolves a single conflict when OnConflict is ConflictPrompt.
+	// When nil, prompted conflicts are only reported (useful for dry runs).
+	Prompt func(c Change) (ConflictStrategy, error)
+}
+
+// MergeWithOptions merges new into existing, resolving same-named entries with
+// different content according to opts. Identical entries are never treated as
+// conflicts. Clusters and users are merged before contexts so renamed entries
+// are followed by the imported contexts that reference them.
+func MergeWithOptions(existing, new *Config, opts MergeOptions) (*Config, *MergeReport, error) {
+	report := &MergeReport{}
+	if new == nil {
+		return existing, report, nil
+	}
+	if opts.OnConflict == "" {
+		opts.OnConflict = ConflictReplace
+	}
+	if existing == nil {
+		existing = &Config{}
+	}
+
+	merged := &Config{
+		APIVersion:     existing.APIVersion,
+		Kind:           existing.Kind,
+		CurrentContext: existing.CurrentContext,
+		Preferences:    existing.Preferences,
+		Extensions:     existing.Extensions,
+		Extra:          existing.Extra,
+	}
+	if merged.APIVersion == "" {
+		merged.APIVersion = new.APIVersion
+	}
+	if merged.Kind == "" {
+		merged.Kind = new.Kind
+	}
+
+	var conflicts []Change
+	var err error
+
+	clusterRenames := make(map[string]string)
+	merged.Clusters, err = mergeEntries("cluster", existing.Clusters, new.Clusters, opts, report, &conflicts, clusterRenames,
+		func(c *Cluster, name string) { c.Name = name })
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userRenames := make(map[string]string)
+	merged.Users, err = mergeEntries("user", existing.Users, new.Users, opts, report, &conflicts, userRenames,
+		func(u *User, name string) { u.Name = name })
+	if err != nil {
+		return nil, nil, err
+	}
+
+	incomingContexts := make([]Context, 0, len(new.Contexts))
+	for _, ctx := range new.Contexts {
+		if renamed, ok := clusterRenames[ctx.Context.Cluster]; ok {
+			ctx.Context.Cluster = renamed
+		}
+		if renamed, ok := userRenames[ctx.Context.User]; ok {
+			ctx.Context.User = renamed
+		}
+		incomingContexts = append(incomingContexts, ctx)
+	}
+
+	contextRenames := make(map[string]string)
+	merged.Contexts, err = mergeEntries("context", existing.Contexts, incomingContexts, opts, report, &conflicts, contextRenames,
+		func(c *Context, name string) { c.Name = name })
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if merged.CurrentContext == "" && len(incomingContexts) > 0 {
+		first := incomingContexts[0].Name
+		if renamed, ok := contextRenames[first]; ok {
+			first = renamed
+		}
+		merged.CurrentContext = first
+		report.CurrentContext = first
+	}
+
+	if len(conflicts) > 0 && opts.OnConflict == ConflictFail {
+		return nil, report, &ConflictError{Conflicts: conflicts}
+	}
+
+	return Deduplicate(merged), report, nil
+}
+
+// mergeEntries merges one entry list and records each decision in report.
+// Renamed entries are recorded in renames (old name → new name).
+func mergeEntries[T any](kind string, existing, incoming []T, opts MergeOptions, report *MergeReport,
+	conflicts *[]Change, renames map[string]string, rename func(*T, string)) ([]T, error) {
+	merged := append([]T{}, existing...)
+
+	taken := make(map[string]bool)
+	for _, item := range existing {
+		taken[entryName(item)] = true
+	}
+	for _, item := range incoming {
+		taken[entryName(item)] = true
+	}
+
+	for _, item := range incoming {
+		name := entryName(item)
+		idx := -1
+		for i := range merged {
+			if entryName(merged[i]) == name {
+				idx = i
+				break
+			}
+		}
+
+		if idx < 0 {
+			merged = append(merged, item)
+			report.Changes = append(report.Changes, Change{Kind: kind, Name: name, Action: ActionAdded, after: item})
+			continue
+		}
+
+		if reflect.DeepEqual(merged[idx], item) {
+			report.Changes = append(report.Changes, Change{Kind: kind, Name: name, Action: ActionUnchanged})
+			continue
+		}
+
+		change := Change{Kind: kind, Name: name, before: merged[idx], after: item}
+		strategy := opts.OnConflict
+		if strategy == ConflictPrompt {
+			if opts.Prompt == nil {
+				change.Action = ActionConflict
+				report.Changes = append(report.Changes, change)
+				continue
+			}
+			var err error
+			if strategy, err = opts.Prompt(change); err != nil {
+				return nil, err
+			}
+		}
+
+		switch strategy {
+		case ConflictKeep:
+			change.Action = ActionKept
+		case ConflictRename:
+			newName := uniqueName(name, taken)
+			taken[newName] = true
+			renames[name] = newName
+			rename(&item, newName)
+			merged = append(merged, item)
+			change.Action = ActionRenamed
+			change.NewName = newName
+			change.before = nil
+		case ConflictFail:
+			change.Action = ActionConflict
+			*conflicts = append(*conflicts, change)
+		default:
+			merged[idx] = item
+			change.Action = ActionReplaced
+		}
+		report.Changes = append(report.Changes, change)
+	}
+
+	return merged, nil
+}
+
+// uniqueName returns the first free "<name>-N" variant.
+func uniqueName(name string, taken map[string]bool) string {
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+// newConflictPrompt returns a MergeOptions.Prompt asking on stdin. It holds a
+// single reader for every question, so piped answers are not lost to
+// buffering.
+var newConflictPrompt = func() func(c Change) (ConflictStrategy, error) {
+	reader := bufio.NewReader(os.Stdin)
+	return func(c Change) (ConflictStrategy, error) {
+		return promptConflict(reader, os.Stdout, c)
+	}
+}
+
+func promptConflict(reader *bufio.Reader, out io.Writer, c Change) (ConflictStrategy, error) {
+	_, _ = fmt.Fprintf(out, "\n⚠️  %s '%s' already exists with different content:\n", c.Kind, c.Name)
+	for _, line := range DiffLines(entryYAML(c.before), entryYAML(c.after)) {
+		_, _ = fmt.Fprintf(out, "    %s\n", line)
+	}
+
+	for {
+		_, _ = fmt.Fprint(out, "❓ [r]eplace, [k]eep existing, re[n]ame imported, [a]bort? ")
+		response, err := reader.ReadString('\n')
+		if err != nil && response == "" {
+			return "", fmt.Errorf("failed to read input: %w", err)
+		}
+		switch strings.TrimSpace(strings.ToLower(response)) {
+		case "r", "replace":
+			return ConflictReplace, nil
+		case "k", "keep":
+			return ConflictKeep, nil
+		case "n", "rename":
+			return ConflictRename, nil
+		case "a", "abort":
+			return "", fmt.Errorf("import aborted")
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read input: %w", err)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package kubeconfig
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// conflictingConfigs returns an existing config and an import that both ship
+// a user named "default" with different tokens.
+func conflictingConfigs() (*Config, *Config) {
+	existing := &Config{
+		APIVersion:     "v1",
+		Kind:           "Config",
+		CurrentContext: "prod",
+		Clusters:       []Cluster{{Name: "prod", Cluster: ClusterConfig{Server: "https://prod:6443"}}},
+		Contexts:       []Context{{Name: "prod", Context: ContextConfig{Cluster: "prod", User: "default"}}},
+		Users:          []User{{Name: "default", User: UserConfig{Token: "prod-token"}}},
+	}
+	incoming := &Config{
+		APIVersion: "v1",
+		Kind:       "Config",
+		Clusters:   []Cluster{{Name: "dev", Cluster: ClusterConfig{Server: "https://dev:6443"}}},
+		Contexts:   []Context{{Name: "dev", Context: ContextConfig{Cluster: "dev", User: "default"}}},
+		Users:      []User{{Name: "default", User: UserConfig{Token: "dev-token"}}},
+	}
+	return existing, incoming
+}
+
+func findUser(config *Config, name string) *User {
+	for i := range config.Users {
+		if config.Users[i].Name == name {
+			return &config.Users[i]
+		}
+	}
+	return nil
+}
+
+func findContext(config *Config, name string) *Context {
+	for i := range config.Contexts {
+		if config.Contexts[i].Name == name {
+			return &config.Contexts[i]
+		}
+	}
+	return nil
+}
+
+func TestMergeWithOptions_Replace(t *testing.T) {
+	existing, incoming := conflictingConfigs()
+
+	merged, report, err := MergeWithOptions(existing, incoming, MergeOptions{OnConflict: ConflictReplace})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u := findUser(merged, "default"); u == nil || u.User.Token != "dev-token" {
+		t.Errorf("expected user to be replaced, got %+v", u)
+	}
+	if report.Count(ActionReplaced) != 1 || report.Count(ActionAdded) != 2 {
+		t.Errorf("unexpected report: %+v", report.Changes)
+	}
+}
+
+func TestMergeWithOptions_Keep(t *testing.T) {
+	existing, incoming := conflictingConfigs()
+
+	merged, report, err := MergeWithOptions(existing, incoming, MergeOptions{OnConflict: ConflictKeep})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u := findUser(merged, "default"); u == nil || u.User.Token != "prod-token" {
+		t.Errorf("expected existing user to be kept, got %+v", u)
+	}
+	if report.Count(ActionKept) != 1 {
+		t.Errorf("expected one kept entry, got %+v", report.Changes)
+	}
+}
+
+func TestMergeWithOptions_RenameUpdatesReferences(t *testing.T) {
+	existing, incoming := conflictingConfigs()
+
+	merged, report, err := MergeWithOptions(existing, incoming, MergeOptions{OnConflict: ConflictRename})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u := findUser(merged, "default"); u == nil || u.User.Token != "prod-token" {
+		t.Errorf("expected existing user untouched, got %+v", u)
+	}
+	if u := findUser(merged, "default-1"); u == nil || u.User.Token != "dev-token" {
+		t.Errorf("expected imported user as 'default-1', got %+v", u)
+	}
+	if ctx := findContext(merged, "dev"); ctx == nil || ctx.Context.User != "default-1" {
+		t.Errorf("expected imported context to reference renamed user, got %+v", ctx)
+	}
+	if ctx := findContext(merged, "prod"); ctx == nil || ctx.Context.User != "default" {
+		t.Errorf("expected existing context untouched, got %+v", ctx)
+	}
+	if report.Count(ActionRenamed) != 1 {
+		t.Errorf("expected one renamed entry, got %+v", report.Changes)
+	}
+}
+
+func TestMergeWithOptions_Fail(t *testing.T) {
+	existing, incoming := conflictingConfigs()
+
+	_, report, err := MergeWithOptions(existing, incoming, MergeOptions{OnConflict: ConflictFail})
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("expected ConflictError, got %v", err)
+	}
+	if len(conflictErr.Conflicts) != 1 || conflictErr.Conflicts[0].Name != "default" {
+		t.Errorf("unexpected conflicts: %+v", conflictErr.Conflicts)
+	}
+	if report == nil || report.Count(ActionConflict) != 1 {
+		t.Errorf("expected conflict in report, got %+v", report)
+	}
+}
+
+func TestMergeWithOptions_IdenticalEntriesAreNotConflicts(t *testing.T) {
+	existing, _ := conflictingConfigs()
+	incoming := &Config{Users: []User{{Name: "default", User: UserConfig{Token: "prod-token"}}}}
+
+	_, report, err := MergeWithOptions(existing, incoming, MergeOptions{OnConflict: ConflictFail})
+	if err != nil {
+		t.Fatalf("expected identical entry to merge cleanly, got %v", err)
+	}
+	if report.Count(ActionUnchanged) != 1 {
+		t.Errorf("expected one unchanged entry, got %+v", report.Changes)
+	}
+}
+
+func TestMergeWithOptions_Prompt(t *testing.T) {
+	existing, incoming := conflictingConfigs()
+
+	asked := 0
+	opts := MergeOptions{
+		OnConflict: ConflictPrompt,
+		Prompt: func(c Change) (ConflictStrategy, error) {
+			asked++
+			return ConflictKeep, nil
+		},
+	}
+	merged, _, err := MergeWithOptions(existing, incoming, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if asked != 1 {
+		t.Errorf("expected one prompt, got %d", asked)
+	}
+	if u := findUser(merged, "default"); u == nil || u.User.Token != "prod-token" {
+		t.Errorf("expected prompted keep to win, got %+v", u)
+	}
+}
+
+func TestPromptConflict_ParsesAnswers(t *testing.T) {
+	c := Change{Kind: "user", Name: "default"}
+	var out bytes.Buffer
+
+	strategy, err := promptConflict(bufio.NewReader(strings.NewReader("maybe\nn\n")), &out, c)
+	if err != nil || strategy != ConflictRename {
+		t.Errorf("expected rename, got %q (%v)", strategy, err)
+	}
+	if _, err := promptConflict(bufio.NewReader(strings.NewReader("a\n")), &out, c); err == nil {
+		t.Error("expected abort to return an error")
+	}
+}
+
+func TestImportConfig_DryRunDoesNotWrite(t *testing.T) {
+	existing, incoming := conflictingConfigs()
+	configPath := filepath.Join(t.TempDir(), "config")
+	if err := Save(configPath, existing); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+	before, _ := os.ReadFile(configPath)
+
+	report, err := importConfig(configPath, incoming, ImportOptions{OnConflict: ConflictReplace, DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Count(ActionReplaced) != 1 {
+		t.Errorf("expected planned replacement, got %+v", report.Changes)
+	}
+
+	after, _ := os.ReadFile(configPath)
+	if !bytes.Equal(before, after) {
+		t.Error("dry run must not modify the kubeconfig")
+	}
+	matches, _ := filepath.Glob(configPath + ".backup.*")
+	if len(matches) != 0 {
+		t.Errorf("dry run must not create backups, got %v", matches)
+	}
+}
+
+func TestMergeReport_PrintRedactsCredentials(t *testing.T) {
+	existing, incoming := conflictingConfigs()
+	_, report, err := MergeWithOptions(existing, incoming, MergeOptions{OnConflict: ConflictReplace})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out bytes.Buffer
+	report.Print(&out)
+	printed := out.String()
+	if strings.Contains(printed, "prod-token") || strings.Contains(printed, "dev-token") {
+		t.Errorf("report must not print tokens:\n%s", printed)
+	}
+	if !strings.Contains(printed, "~ user     default (replaced)") {
+		t.Errorf("expected replaced user in report:\n%s", printed)
+	}
+}
+
+func TestImportConfig_PromptsOnceOutsideTheLock(t *testing.T) {
+	existing, incoming := conflictingConfigs()
+	incoming.Clusters = append(incoming.Clusters, Cluster{Name: "prod", Cluster: ClusterConfig{Server: "https://prod.example.com:6443"}})
+	configPath := filepath.Join(t.TempDir(), "config")
+	t.Setenv(BackupDirEnvVar, t.TempDir())
+	if err := Save(configPath, existing); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	orig := newConflictPrompt
+	defer func() { newConflictPrompt = orig }()
+	asked := 0
+	newConflictPrompt = func() func(c Change) (ConflictStrategy, error) {
+		// Both answers arrive in one buffered read, as when piped.
+		reader := bufio.NewReader(strings.NewReader("k\nn\n"))
+		return func(c Change) (ConflictStrategy, error) {
+			asked++
+			if _, err := os.Stat(configPath + lockSuffix); err == nil {
+				t.Error("expected the prompt to run before the lock is taken")
+			}
+			return promptConflict(reader, &bytes.Buffer{}, c)
+		}
+	}
+
+	if _, err := importConfig(configPath, incoming, ImportOptions{OnConflict: ConflictPrompt}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if asked != 2 {
+		t.Errorf("expected two prompts, got %d", asked)
+	}
+
+	merged, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("failed to load kubeconfig: %v", err)
+	}
+	for _, c := range merged.Clusters {
+		if c.Name == "prod" && c.Cluster.Server != "https://prod:6443" {
+			t.Errorf("expected the existing cluster to be kept, got %+v", c)
+		}
+	}
+	if u := findUser(merged, "default-1"); u == nil || u.User.Token != "dev-token" {
+		t.Errorf("expected the imported user to be renamed, got %+v", merged.Users)
+	}
+}
@@ -38,8 +38,51 @@ func ListContexts(path string) error {
 	return nil
 }
 
+// ContextSummary is the -o schema of list-contexts.
+type ContextSummary struct {
+	Name      string `json:"name"`
+	Cluster   string `json:"cluster"`
+	User      string `json:"user"`
+	Namespace string `json:"namespace,omitempty"`
+	Server    string `json:"server,omitempty"`
+	Current   bool   `json:"current"`
+}
+
+// ContextSummaries returns a summary of each context in the kubeconfig.
+func ContextSummaries(path string) ([]ContextSummary, error) {
+	config, err := Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	servers := make(map[string]string, len(config.Clusters))
+	for _, c := range config.Clusters {
+		if _, ok := servers[c.Name]; !ok {
+			servers[c.Name] = c.Cluster.Server
+		}
+	}
+	summaries := make([]ContextSummary, 0, len(config.Contexts))
+	for _, ctx := range config.Contexts {
+		summaries = append(summaries, ContextSummary{
+			Name:      ctx.Name,
+			Cluster:   ctx.Context.Cluster,
+			User:      ctx.Context.User,
+			Namespace: ctx.Context.Namespace,
+			Server:    servers[ctx.Context.Cluster],
+			Current:   ctx.Name == config.CurrentContext,
+		})
+	}
+	return summaries, nil
+}
+
 // SetCurrentContext sets the current-context in the kubeconfig
 func SetCurrentContext(path, contextName string) error {
+	return withLock(path, func() error {
+		return setCurrentContext(path, contextName)
+	})
+}
+
+func setCurrentContext(path, contextName string) error {
 	config, err := LoadAndCheckDuplicates(path)
 	if err != nil {
 		return fmt.Errorf("failed to load kubeconfig: %w", err)
@@ -70,6 +113,12 @@ func SetCurrentContext(path, contextName string) error {
 
 // SetNamespace sets the namespace for a context in the kubeconfig
 func SetNamespace(path, contextName, namespace string) error {
+	return withLock(path, func() error {
+		return setNamespace(path, contextName, namespace)
+	})
+}
+
+func setNamespace(path, contextName, namespace string) error {
 	config, err := LoadAndCheckDuplicates(path)
 	if err != nil {
 		return fmt.Errorf("failed to load kubeconfig: %w", err)
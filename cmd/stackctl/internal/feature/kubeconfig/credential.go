@@ -0,0 +1,353 @@
+package kubeconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/env"
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/crypto"
+)
+
+const (
+	// ExecCredentialAPIVersion is the client-go credential plugin API served
+	// by `kubeconfig credential`.
+	ExecCredentialAPIVersion = "client.authentication.k8s.io/v1"
+
+	// CredentialCacheDirEnvVar overrides where issued credentials are cached.
+	CredentialCacheDirEnvVar = "STACK_CTL_CREDENTIAL_CACHE_DIR"
+
+	// CredentialKeyFileEnvVar overrides the file holding the key that
+	// encrypts the credential cache.
+	CredentialKeyFileEnvVar = "STACK_CTL_CREDENTIAL_KEY_FILE"
+
+	// DefaultCredentialTTL is how long kubectl may reuse a credential before
+	// calling the plugin again.
+	DefaultCredentialTTL = time.Hour
+)
+
+// ExecCredential is the object a credential plugin prints for kubectl.
+type ExecCredential struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Status     *ExecCredentialStatus `json:"status,omitempty"`
+}
+
+// ExecCredentialStatus holds a bearer token or a PEM client certificate.
+type ExecCredentialStatus struct {
+	ExpirationTimestamp   *time.Time `json:"expirationTimestamp,omitempty"`
+	Token                 string     `json:"token,omitempty"`
+	ClientCertificateData string     `json:"clientCertificateData,omitempty"`
+	ClientKeyData         string     `json:"clientKeyData,omitempty"`
+}
+
+// CredentialOptions selects the stored kubeconfig a credential is read from.
+type CredentialOptions struct {
+	// SecretName is a secret name under the layout or a full data path.
+	SecretName string
+	// Context picks a context when the secret holds several.
+	Context string
+	// TTL bounds the lifetime of the credential; client certificates also
+	// stop at their own expiry.
+	TTL time.Duration
+	// NoCache skips the on-disk cache.
+	NoCache bool
+}
+
+// Credential returns an ExecCredential built from the user of a kubeconfig
+// stored in Vault. Credentials are cached on disk, encrypted with a per-user
+// key, until they expire.
+func (s *VaultKubeconfigService) Credential(opts CredentialOptions) (*ExecCredential, error) {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultCredentialTTL
+	}
+	now := time.Now()
+	cachePath := s.credentialCachePath(opts)
+
+	if !opts.NoCache && cachePath != "" {
+		if cred, ok := readCachedCredential(cachePath, now); ok {
+			return cred, nil
+		}
+	}
+
+	config, err := s.RemoteContextConfig(opts.SecretName, opts.Context)
+	if err != nil {
+		return nil, err
+	}
+	cred, err := credentialFromConfig(config, opts.TTL, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.NoCache && cachePath != "" {
+		if err := writeCachedCredential(cachePath, cred); err != nil {
+			log.Warnf("⚠️  Failed to cache credential: %v", err)
+		}
+	}
+	return cred, nil
+}
+
+// credentialFromConfig extracts the token or client certificate of the
+// single user in config.
+func credentialFromConfig(config *Config, ttl time.Duration, now time.Time) (*ExecCredential, error) {
+	if len(config.Users) != 1 {
+		return nil, fmt.Errorf("expected a single user, found %d", len(config.Users))
+	}
+	user := config.Users[0].User
+	expires := now.Add(ttl).UTC().Truncate(time.Second)
+	status := &ExecCredentialStatus{Token: user.Token}
+
+	if user.ClientCertificateData != "" || user.ClientKeyData != "" {
+		cert, err := dataOrFile(user.ClientCertificateData, "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid client-certificate-data: %w", err)
+		}
+		key, err := dataOrFile(user.ClientKeyData, "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid client-key-data: %w", err)
+		}
+		if len(cert) == 0 || len(key) == 0 {
+			return nil, fmt.Errorf("user '%s' needs both client-certificate-data and client-key-data", config.Users[0].Name)
+		}
+		status.ClientCertificateData, status.ClientKeyData = string(cert), string(key)
+
+		certs, err := readCertificates(user.ClientCertificateData, "")
+		if err != nil {
+			return nil, err
+		}
+		if notAfter := certs[0].NotAfter.UTC(); notAfter.Before(expires) {
+			expires = notAfter
+		}
+		if !expires.After(now) {
+			return nil, fmt.Errorf("client certificate of user '%s' expired on %s", config.Users[0].Name, certs[0].NotAfter.Format(time.RFC3339))
+		}
+	}
+
+	if status.Token == "" && status.ClientCertificateData == "" {
+		return nil, fmt.Errorf("user '%s' has no token or client certificate data", config.Users[0].Name)
+	}
+	status.ExpirationTimestamp = &expires
+	return &ExecCredential{APIVersion: ExecCredentialAPIVersion, Kind: "ExecCredential", Status: status}, nil
+}
+
+// credentialCachePath returns the cache file for a Vault address, secret
+// and context, or "" when no cache directory is available.
+func (s *VaultKubeconfigService) credentialCachePath(opts CredentialOptions) string {
+	dir, ok := env.Get(CredentialCacheDirEnvVar)
+	if !ok {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(cacheDir, "stackctl", "credentials")
+	}
+
+	addr := ""
+	if client, err := s.client.VaultClient(); err == nil {
+		addr = client.Address()
+	}
+	sum := sha256.Sum256([]byte(addr + "\x00" + s.SecretPath(opts.SecretName) + "\x00" + s.secretKey + "\x00" + opts.Context))
+	return filepath.Join(dir, hex.EncodeToString(sum[:16]))
+}
+
+// readCachedCredential returns the cached credential if it is still valid.
+func readCachedCredential(path string, now time.Time) (*ExecCredential, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	key, err := readCredentialKey()
+	if err != nil {
+		return nil, false
+	}
+	plain, err := crypto.DecryptWithKey(key, string(data))
+	if err != nil {
+		return nil, false
+	}
+	var cred ExecCredential
+	if err := json.Unmarshal([]byte(plain), &cred); err != nil || cred.Status == nil || cred.Status.ExpirationTimestamp == nil {
+		return nil, false
+	}
+	if !cred.Status.ExpirationTimestamp.After(now) {
+		_ = os.Remove(path)
+		return nil, false
+	}
+	return &cred, true
+}
+
+// writeCachedCredential encrypts cred into a 0600 file.
+func writeCachedCredential(path string, cred *ExecCredential) error {
+	data, err := json.Marshal(cred)
+	if err != nil {
+		return err
+	}
+	key, err := credentialKey()
+	if err != nil {
+		return err
+	}
+	encrypted, err := crypto.EncryptWithKey(key, string(data))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, []byte(encrypted), 0600)
+}
+
+// CredentialKeyPath returns the credential cache key file:
+// $STACK_CTL_CREDENTIAL_KEY_FILE or <user config dir>/stackctl/credential.key.
+func CredentialKeyPath() string {
+	if path, ok := env.Get(CredentialKeyFileEnvVar); ok {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "stackctl", "credential.key")
+}
+
+// readCredentialKey reads the credential cache key.
+func readCredentialKey() ([]byte, error) {
+	path := CredentialKeyPath()
+	if path == "" {
+		return nil, fmt.Errorf("no user config directory for the credential key")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != crypto.KeySize {
+		return nil, fmt.Errorf("invalid credential key in %s", path)
+	}
+	return key, nil
+}
+
+// credentialKey returns the credential cache key, creating a random one in a
+// 0600 file the first time.
+func credentialKey() ([]byte, error) {
+	key, err := readCredentialKey()
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return key, err
+	}
+
+	path := CredentialKeyPath()
+	if key, err = crypto.NewKey(); err != nil {
+		return nil, fmt.Errorf("failed to create credential key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create credential key: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		// Another process created it first.
+		return readCredentialKey()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credential key: %w", err)
+	}
+	_, err = file.WriteString(hex.EncodeToString(key) + "\n")
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, fmt.Errorf("failed to write credential key: %w", err)
+	}
+	return key, nil
+}
+
+// CredentialExecConfig returns the user exec entry that has kubectl call
+// `stackctl kubeconfig credential` for the secret at dataPath. vaultAddr is
+// passed to the plugin when set.
+func CredentialExecConfig(dataPath, field, vaultAddr string) *ExecConfig {
+	args := []string{"kubeconfig", "credential", "--vault-path", dataPath}
+	if field != "" && field != DefaultKubeconfigSecretKey {
+		args = append(args, "--vault-field", field)
+	}
+	exec := &ExecConfig{
+		APIVersion:      ExecCredentialAPIVersion,
+		Command:         "stackctl",
+		Args:            args,
+		InstallHint:     "Install stackctl and log in to Vault (vault login) to use this context.",
+		InteractiveMode: "Never",
+	}
+	if vaultAddr != "" {
+		exec.Env = []ExecEnvVar{{Name: "VAULT_ADDR", Value: vaultAddr}}
+	}
+	return exec
+}
+
+// UseExecPlugin replaces the static credentials of the user referenced by
+// contextName with exec, in whichever file of the KUBECONFIG list owns it.
+func UseExecPlugin(path, contextName string, exec *ExecConfig) error {
+	return withLock(path, func() error {
+		return useExecPlugin(path, contextName, exec)
+	})
+}
+
+func useExecPlugin(path, contextName string, exec *ExecConfig) error {
+	config, err := Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	single, err := extractContext(config, contextName)
+	if err != nil {
+		return err
+	}
+	userName := single.Users[0].Name
+
+	files, err := loadFiles(SplitPaths(path))
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	backupPaths, err := BackupFiles(path)
+	if err != nil {
+		log.Warnf("⚠️  Warning: Failed to create backup: %v", err)
+	}
+	for _, backupPath := range backupPaths {
+		log.Infof("📦 Backed up existing kubeconfig to: %s", backupPath)
+	}
+
+	for _, f := range files {
+		if f.config == nil {
+			continue
+		}
+		updated := *f.config
+		updated.Users = append([]User(nil), f.config.Users...)
+		changed := false
+		for i := range updated.Users {
+			if updated.Users[i].Name != userName {
+				continue
+			}
+			user := &updated.Users[i].User
+			user.Token, user.TokenFile = "", ""
+			user.ClientCertificate, user.ClientCertificateData = "", ""
+			user.ClientKey, user.ClientKeyData = "", ""
+			user.Username, user.Password = "", ""
+			user.AuthProvider = nil
+			user.Exec = exec
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		if err := saveFile(f.path, &updated); err != nil {
+			return fmt.Errorf("failed to save kubeconfig: %w", err)
+		}
+	}
+
+	log.Infof("🔌 User '%s' of context '%s' now gets its credentials from Vault", userName, contextName)
+	return nil
+}
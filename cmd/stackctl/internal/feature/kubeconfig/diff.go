@@ -0,0 +1,170 @@
+package kubeconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue replaces a secret with a short fingerprint so a diff still
+// shows that it changed without printing it.
+func redactedValue(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(secret))
+	return "<redacted:" + hex.EncodeToString(sum[:4]) + ">"
+}
+
+// redactUser returns a copy of a user entry with credentials masked.
+func redactUser(user User) User {
+	u := user
+	u.User.Token = redactedValue(u.User.Token)
+	u.User.Password = redactedValue(u.User.Password)
+	u.User.ClientKeyData = redactedValue(u.User.ClientKeyData)
+	if u.User.AuthProvider != nil {
+		provider := *u.User.AuthProvider
+		provider.Config = make(map[string]string, len(user.User.AuthProvider.Config))
+		for k, v := range user.User.AuthProvider.Config {
+			lower := strings.ToLower(k)
+			if strings.Contains(lower, "secret") || strings.Contains(lower, "token") {
+				v = redactedValue(v)
+			}
+			provider.Config[k] = v
+		}
+		u.User.AuthProvider = &provider
+	}
+	return u
+}
+
+// redactEntry masks credentials when the entry is a user.
+func redactEntry(entry interface{}) interface{} {
+	if user, ok := entry.(User); ok {
+		return redactUser(user)
+	}
+	return entry
+}
+
+// RedactConfig returns a copy of the config with user credentials masked.
+func RedactConfig(config *Config) *Config {
+	if config == nil {
+		return nil
+	}
+	redacted := *config
+	redacted.Users = make([]User, 0, len(config.Users))
+	for _, user := range config.Users {
+		redacted.Users = append(redacted.Users, redactUser(user))
+	}
+	return &redacted
+}
+
+// entryYAML renders an entry as YAML for diffing, masking credentials.
+func entryYAML(entry interface{}) string {
+	if entry == nil {
+		return ""
+	}
+	data, err := yaml.Marshal(redactEntry(entry))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// DiffLines returns a line-based diff of two texts. Each line is prefixed
+// with "- " (only in a), "+ " (only in b) or "  " (in both).
+func DiffLines(a, b string) []string {
+	left := splitLines(a)
+	right := splitLines(b)
+
+	// Longest common subsequence table.
+	lcs := make([][]int, len(left)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(right)+1)
+	}
+	for i := len(left) - 1; i >= 0; i-- {
+		for j := len(right) - 1; j >= 0; j-- {
+			if left[i] == right[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(left) && j < len(right) {
+		switch {
+		case left[i] == right[j]:
+			out = append(out, "  "+left[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, "- "+left[i])
+			i++
+		default:
+			out = append(out, "+ "+right[j])
+			j++
+		}
+	}
+	for ; i < len(left); i++ {
+		out = append(out, "- "+left[i])
+	}
+	for ; j < len(right); j++ {
+		out = append(out, "+ "+right[j])
+	}
+	return out
+}
+
+// HasDiff reports whether a diff produced by DiffLines contains changes.
+func HasDiff(lines []string) bool {
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "  ") {
+			return true
+		}
+	}
+	return false
+}
+
+// CompactDiff keeps only changed lines of a DiffLines result plus the given
+// number of unchanged context lines around them. Skipped runs are shown as "...".
+func CompactDiff(lines []string, context int) []string {
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if strings.HasPrefix(line, "  ") {
+			continue
+		}
+		for j := i - context; j <= i+context; j++ {
+			if j >= 0 && j < len(lines) {
+				keep[j] = true
+			}
+		}
+	}
+
+	var out []string
+	skipped := false
+	for i, line := range lines {
+		if !keep[i] {
+			skipped = true
+			continue
+		}
+		if skipped && len(out) > 0 {
+			out = append(out, "  ...")
+		}
+		skipped = false
+		out = append(out, line)
+	}
+	return out
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
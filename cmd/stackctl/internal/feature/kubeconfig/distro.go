@@ -0,0 +1,64 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Distro describes where a Kubernetes distribution keeps the admin
+// kubeconfig on a node and how to make it usable from another machine.
+type Distro struct {
+	// Name is the --distro value.
+	Name string
+	// Title is shown in the TUI.
+	Title string
+	// Path is the kubeconfig file on the node; empty when Command is used.
+	Path string
+	// Command prints the kubeconfig on the node.
+	Command string
+	// RequiresRoot reads the file or runs the command with sudo unless
+	// connecting as root.
+	RequiresRoot bool
+	// RewriteLoopback rewrites the loopback server address to the SSH host.
+	RewriteLoopback bool
+}
+
+// Distros is the registry of supported distributions.
+var Distros = []Distro{
+	{Name: "k3s", Title: "k3s", Path: "/etc/rancher/k3s/k3s.yaml", RequiresRoot: true, RewriteLoopback: true},
+	{Name: "rke2", Title: "RKE2", Path: "/etc/rancher/rke2/rke2.yaml", RequiresRoot: true, RewriteLoopback: true},
+	{Name: "microk8s", Title: "MicroK8s", Command: "microk8s config", RequiresRoot: true, RewriteLoopback: true},
+	{Name: "kubeadm", Title: "kubeadm", Path: "/etc/kubernetes/admin.conf", RequiresRoot: true},
+	{Name: "k0s", Title: "k0s", Command: "k0s kubeconfig admin", RequiresRoot: true, RewriteLoopback: true},
+	// kind publishes the API server on the node's loopback only, so the
+	// address is kept; reach it through an SSH tunnel or --server.
+	{Name: "kind", Title: "kind", Command: "kind get kubeconfig"},
+}
+
+// LookupDistro returns the distribution registered under name.
+func LookupDistro(name string) (Distro, error) {
+	for _, d := range Distros {
+		if strings.EqualFold(d.Name, name) {
+			return d, nil
+		}
+	}
+	return Distro{}, fmt.Errorf("unknown distro '%s' (supported: %s)", name, strings.Join(DistroNames(), ", "))
+}
+
+// DistroNames returns the names of the registered distributions.
+func DistroNames() []string {
+	names := make([]string, 0, len(Distros))
+	for _, d := range Distros {
+		names = append(names, d.Name)
+	}
+	return names
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// ContextName returns the default name of a context imported from host; the
+// distributions name their context generically (e.g. "default").
+func (d Distro) ContextName(host string) string {
+	return d.Name + "-" + strings.Trim(unsafeNameChars.ReplaceAllString(host, "-"), "-")
+}
@@ -0,0 +1,39 @@
+package kubeconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupDistro(t *testing.T) {
+	d, err := LookupDistro("RKE2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Path != "/etc/rancher/rke2/rke2.yaml" || !d.RewriteLoopback {
+		t.Errorf("unexpected rke2 preset: %+v", d)
+	}
+
+	_, err = LookupDistro("openshift")
+	if err == nil || !strings.Contains(err.Error(), "k3s, rke2") {
+		t.Errorf("expected the supported distros to be listed, got %v", err)
+	}
+}
+
+func TestDistros_HaveASource(t *testing.T) {
+	for _, d := range Distros {
+		if (d.Path == "") == (d.Command == "") {
+			t.Errorf("%s: exactly one of Path and Command must be set", d.Name)
+		}
+	}
+}
+
+func TestDistro_ContextName(t *testing.T) {
+	d, _ := LookupDistro("k3s")
+	if got := d.ContextName("node-1.example.com"); got != "k3s-node-1.example.com" {
+		t.Errorf("unexpected name: %s", got)
+	}
+	if got := d.ContextName("fd00::1"); got != "k3s-fd00-1" {
+		t.Errorf("unexpected name: %s", got)
+	}
+}
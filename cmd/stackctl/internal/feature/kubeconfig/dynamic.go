@@ -0,0 +1,249 @@
+package kubeconfig
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/feature/vault"
+)
+
+// LeaseExtensionName is the user extension recording the Vault lease of a
+// dynamic credential.
+const LeaseExtensionName = "stackctl/vault-lease"
+
+// DynamicLease is the Vault lease behind a dynamic service-account token.
+type DynamicLease struct {
+	LeaseID   string    `yaml:"lease_id"`
+	Expires   time.Time `yaml:"expires"`
+	Renewable bool      `yaml:"renewable,omitempty"`
+	VaultAddr string    `yaml:"vault_addr,omitempty"`
+	Role      string    `yaml:"role,omitempty"`
+	Namespace string    `yaml:"namespace,omitempty"`
+}
+
+// Expired reports whether the lease has run out.
+func (l *DynamicLease) Expired(now time.Time) bool {
+	return !l.Expires.After(now)
+}
+
+// DynamicOptions requests a token from the kubernetes secrets engine.
+type DynamicOptions struct {
+	// Mount is the engine mount (default "kubernetes").
+	Mount string
+	Role  string
+	// Namespace is the Kubernetes namespace the token is created in.
+	Namespace string
+	// TTL of the token; 0 uses the role default.
+	TTL time.Duration
+	// ClusterRoleBinding binds the generated role cluster-wide.
+	ClusterRoleBinding bool
+	// Name of the context, cluster and user (default "<role>-<namespace>").
+	Name string
+	// Server and CAData override the host and CA read from <mount>/config.
+	Server string
+	CAData string
+}
+
+// contextName returns the name given to the issued context.
+func (o DynamicOptions) contextName() string {
+	if o.Name != "" {
+		return o.Name
+	}
+	return o.Role + "-" + o.Namespace
+}
+
+// AddDynamic requests a short-lived service-account token from the Vault
+// kubernetes secrets engine and writes a context using it. The lease is
+// recorded on the user so `remove` can revoke it; a context it replaces has
+// its own lease revoked.
+func (s *VaultKubeconfigService) AddDynamic(path string, opts DynamicOptions) (*DynamicLease, error) {
+	if opts.Role == "" || opts.Namespace == "" {
+		return nil, fmt.Errorf("role and namespace are required")
+	}
+	if opts.Mount == "" {
+		opts.Mount = vault.DefaultKubernetesMount
+	}
+	name := opts.contextName()
+	if err := ValidateContextName(name); err != nil {
+		return nil, err
+	}
+
+	config, lease, err := s.issueDynamic(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, _ := LeaseOfContext(path, name)
+	if _, err := importConfig(path, config, ImportOptions{OnConflict: ConflictReplace}); err != nil {
+		if revokeErr := s.RevokeLease(lease.LeaseID); revokeErr != nil {
+			log.Warnf("⚠️  Failed to revoke unused lease %s: %v", lease.LeaseID, revokeErr)
+		}
+		return nil, err
+	}
+
+	if previous != nil && previous.LeaseID != lease.LeaseID && !previous.Expired(time.Now()) {
+		if err := s.RevokeLease(previous.LeaseID); err != nil {
+			log.Warnf("⚠️  Failed to revoke the replaced lease %s: %v", previous.LeaseID, err)
+		}
+	}
+	log.Infof("✅ Context '%s' uses a token for role '%s' in '%s', valid until %s", name, opts.Role, opts.Namespace, lease.Expires.Format(time.RFC3339))
+	return lease, nil
+}
+
+// issueDynamic requests the token and builds the single-context config.
+func (s *VaultKubeconfigService) issueDynamic(opts DynamicOptions) (*Config, *DynamicLease, error) {
+	client, err := s.client.VaultClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	mount := strings.Trim(opts.Mount, "/")
+
+	server, caData := opts.Server, opts.CAData
+	if server == "" {
+		host, caCert, err := readKubernetesEngineConfig(client, mount)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w; pass --server to set the cluster address", err)
+		}
+		server = host
+		if caData == "" && caCert != "" {
+			caData = base64.StdEncoding.EncodeToString([]byte(caCert))
+		}
+	}
+
+	data := map[string]interface{}{"kubernetes_namespace": opts.Namespace}
+	if opts.TTL > 0 {
+		data["ttl"] = opts.TTL.String()
+	}
+	if opts.ClusterRoleBinding {
+		data["cluster_role_binding"] = true
+	}
+
+	credsPath := mount + "/creds/" + opts.Role
+	log.Infof("🔑 Requesting a service-account token from %s (namespace: %s)", credsPath, opts.Namespace)
+	secret, err := client.Logical().Write(credsPath, data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to request credentials from %s: %w", credsPath, err)
+	}
+	if secret == nil {
+		return nil, nil, fmt.Errorf("no credentials returned by %s", credsPath)
+	}
+	token, _ := secret.Data["service_account_token"].(string)
+	if token == "" {
+		return nil, nil, fmt.Errorf("no service_account_token returned by %s", credsPath)
+	}
+
+	lease := &DynamicLease{
+		LeaseID:   secret.LeaseID,
+		Expires:   time.Now().Add(time.Duration(secret.LeaseDuration) * time.Second).UTC().Truncate(time.Second),
+		Renewable: secret.Renewable,
+		VaultAddr: client.Address(),
+		Role:      opts.Role,
+		Namespace: opts.Namespace,
+	}
+
+	name := opts.contextName()
+	user := UserConfig{Token: token}
+	if err := setLease(&user, lease); err != nil {
+		return nil, nil, err
+	}
+	config := &Config{
+		APIVersion:     "v1",
+		Kind:           "Config",
+		Clusters:       []Cluster{{Name: name, Cluster: ClusterConfig{Server: server, CertificateAuthorityData: caData}}},
+		Contexts:       []Context{{Name: name, Context: ContextConfig{Cluster: name, User: name, Namespace: opts.Namespace}}},
+		Users:          []User{{Name: name, User: user}},
+		CurrentContext: name,
+	}
+	return config, lease, nil
+}
+
+// readKubernetesEngineConfig returns the cluster host and CA configured on
+// the engine.
+func readKubernetesEngineConfig(client *api.Client, mount string) (string, string, error) {
+	secret, err := client.Logical().Read(mount + "/config")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s/config: %w", mount, err)
+	}
+	if secret == nil {
+		return "", "", fmt.Errorf("kubernetes secrets engine at %s is not configured", mount)
+	}
+	host, _ := secret.Data["kubernetes_host"].(string)
+	caCert, _ := secret.Data["kubernetes_ca_cert"].(string)
+	if host == "" {
+		return "", "", fmt.Errorf("%s/config has no kubernetes_host", mount)
+	}
+	return host, caCert, nil
+}
+
+// RevokeLease revokes a Vault lease.
+func (s *VaultKubeconfigService) RevokeLease(leaseID string) error {
+	client, err := s.client.VaultClient()
+	if err != nil {
+		return err
+	}
+	if err := client.Sys().Revoke(leaseID); err != nil {
+		return fmt.Errorf("failed to revoke lease %s: %w", leaseID, err)
+	}
+	log.Infof("🔒 Revoked Vault lease %s", leaseID)
+	return nil
+}
+
+// setLease records lease as an extension of user.
+func setLease(user *UserConfig, lease *DynamicLease) error {
+	raw, err := yaml.Marshal(lease)
+	if err != nil {
+		return err
+	}
+	var extension map[string]interface{}
+	if err := yaml.Unmarshal(raw, &extension); err != nil {
+		return err
+	}
+	user.Extensions = append(user.Extensions, NamedExtension{Name: LeaseExtensionName, Extension: extension})
+	return nil
+}
+
+// LeaseFromUser returns the Vault lease recorded on a user, if any.
+func LeaseFromUser(user *UserConfig) *DynamicLease {
+	if user == nil {
+		return nil
+	}
+	for _, ext := range user.Extensions {
+		if ext.Name != LeaseExtensionName {
+			continue
+		}
+		raw, err := yaml.Marshal(ext.Extension)
+		if err != nil {
+			return nil
+		}
+		var lease DynamicLease
+		if err := yaml.Unmarshal(raw, &lease); err != nil || lease.LeaseID == "" {
+			return nil
+		}
+		return &lease
+	}
+	return nil
+}
+
+// LeaseOfContext returns the Vault lease of the user of a context, or nil
+// when it does not use a dynamic credential.
+func LeaseOfContext(path, contextName string) (*DynamicLease, error) {
+	config, err := Load(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	_, _, user, err := resolveContext(config, contextName)
+	if err != nil {
+		return nil, err
+	}
+	return LeaseFromUser(user), nil
+}
@@ -0,0 +1,117 @@
+package kubeconfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// kubernetesEngine adds the kubernetes secrets engine endpoints to a
+// fakeVault and records the revoked leases.
+func kubernetesEngine(v *fakeVault) *[]string {
+	revoked := &[]string{}
+	issued := 0
+	v.routes["GET kubernetes/config"] = func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"data": map[string]interface{}{
+			"kubernetes_host":    "https://k8s.example.com:6443",
+			"kubernetes_ca_cert": "-----BEGIN CERTIFICATE-----\n",
+		}})
+	}
+	v.routes["PUT kubernetes/creds/dev"] = func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		issued++
+		writeJSON(w, map[string]interface{}{
+			"lease_id":       "kubernetes/creds/dev/" + string(rune('0'+issued)),
+			"lease_duration": 3600,
+			"renewable":      false,
+			"data": map[string]interface{}{
+				"service_account_token":     "token-" + body["kubernetes_namespace"].(string),
+				"service_account_namespace": body["kubernetes_namespace"],
+			},
+		})
+	}
+	v.routes["PUT sys/leases/revoke"] = func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			LeaseID string `json:"lease_id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		*revoked = append(*revoked, body.LeaseID)
+		w.WriteHeader(http.StatusNoContent)
+	}
+	return revoked
+}
+
+func TestAddDynamic(t *testing.T) {
+	vault, svc := newFakeVault(t)
+	revoked := kubernetesEngine(vault)
+	path := filepath.Join(t.TempDir(), "config")
+	t.Setenv(BackupDirEnvVar, t.TempDir())
+
+	lease, err := svc.AddDynamic(path, DynamicOptions{Role: "dev", Namespace: "team-a", TTL: time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lease.LeaseID != "kubernetes/creds/dev/1" || lease.Expires.Before(time.Now().Add(59*time.Minute)) {
+		t.Errorf("unexpected lease %+v", lease)
+	}
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	report, err := InspectContext(config, "dev-team-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Server != "https://k8s.example.com:6443" || report.Namespace != "team-a" || report.AuthType != "bearer token" {
+		t.Errorf("unexpected context: %+v", report)
+	}
+	if report.Lease == nil || report.Lease.LeaseID != lease.LeaseID || report.Lease.Role != "dev" {
+		t.Errorf("expected the lease to be recorded on the user, got %+v", report.Lease)
+	}
+
+	// Issuing again replaces the context and revokes the old lease.
+	if _, err := svc.AddDynamic(path, DynamicOptions{Role: "dev", Namespace: "team-a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*revoked) != 1 || (*revoked)[0] != "kubernetes/creds/dev/1" {
+		t.Errorf("expected the replaced lease to be revoked, got %v", *revoked)
+	}
+	current, err := LeaseOfContext(path, "dev-team-a")
+	if err != nil || current == nil || current.LeaseID != "kubernetes/creds/dev/2" {
+		t.Errorf("expected the new lease, got %+v (%v)", current, err)
+	}
+
+	if err := svc.RevokeLease(current.LeaseID); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(*revoked) != 2 {
+		t.Errorf("expected two revoked leases, got %v", *revoked)
+	}
+}
+
+func TestAddDynamic_RequiresServerWithoutEngineConfig(t *testing.T) {
+	_, svc := newFakeVault(t)
+	path := filepath.Join(t.TempDir(), "config")
+
+	if _, err := svc.AddDynamic(path, DynamicOptions{Role: "dev"}); err == nil {
+		t.Error("expected an error without a namespace")
+	}
+	if _, err := svc.AddDynamic(path, DynamicOptions{Role: "dev", Namespace: "team-a"}); err == nil {
+		t.Error("expected an error when the engine config cannot be read")
+	}
+}
+
+func TestLeaseOfContext_StaticUser(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := Save(path, syncContext("prod", "https://prod:6443")); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	lease, err := LeaseOfContext(path, "prod")
+	if err != nil || lease != nil {
+		t.Errorf("expected no lease for a static user, got %+v (%v)", lease, err)
+	}
+}
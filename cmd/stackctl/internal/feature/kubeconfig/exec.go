@@ -0,0 +1,179 @@
+package kubeconfig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// ExecOptions configures a command run against an ephemeral kubeconfig.
+type ExecOptions struct {
+	// Args is the command and its arguments.
+	Args []string
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// ExitError is returned by Exec when the command exits with a non-zero code.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command exited with code %d", e.Code)
+}
+
+// ExitCode returns the exit code of the command.
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+// forwardedSignals are relayed to the command while it runs.
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+
+// LocalContextConfig returns a single-context kubeconfig for a local context.
+// Relative file references are made absolute against the file defining the
+// cluster or user, as the config is written elsewhere by Exec.
+func LocalContextConfig(path, contextName string) (*Config, error) {
+	config, sources, err := loadWithFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	single, err := extractContext(config, contextName)
+	if err != nil {
+		return nil, err
+	}
+	resolvePaths(single, sources.dir)
+	return single, nil
+}
+
+// RemoteContextConfig reads a kubeconfig from Vault, decoding it as
+// add-from-vault does, and returns a single-context kubeconfig. An empty
+// contextName selects the only context of the secret or its current-context.
+func (s *VaultKubeconfigService) RemoteContextConfig(secretName, contextName string) (*Config, error) {
+	config, err := s.readRemoteConfig(s.SecretPath(secretName))
+	if err != nil {
+		return nil, err
+	}
+
+	if contextName == "" {
+		switch {
+		case len(config.Contexts) == 1:
+			contextName = config.Contexts[0].Name
+		case config.CurrentContext != "":
+			contextName = config.CurrentContext
+		default:
+			names := make([]string, 0, len(config.Contexts))
+			for _, c := range config.Contexts {
+				names = append(names, c.Name)
+			}
+			return nil, fmt.Errorf("secret '%s' holds several contexts (%s); pick one with --context", secretName, strings.Join(names, ", "))
+		}
+	}
+	return extractContext(config, contextName)
+}
+
+// Exec writes config to a private temporary kubeconfig, runs the command
+// with KUBECONFIG pointing at it and wipes the file afterwards. Signals are
+// forwarded to the command; a non-zero exit is returned as *ExitError.
+func Exec(config *Config, opts ExecOptions) error {
+	if len(opts.Args) == 0 {
+		return fmt.Errorf("no command to run")
+	}
+
+	path, cleanup, err := writeEphemeral(config)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	cmd := exec.Command(opts.Args[0], opts.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = opts.Stdin, opts.Stdout, opts.Stderr
+	cmd.Env = append(withoutEnv(os.Environ(), "KUBECONFIG"), "KUBECONFIG="+path)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", opts.Args[0], err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = cmd.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+	err = cmd.Wait()
+	close(done)
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		if code < 0 {
+			// Killed by a signal.
+			code = 1
+		}
+		return &ExitError{Code: code}
+	}
+	return err
+}
+
+// writeEphemeral writes config to a 0600 file inside a private temporary
+// directory. The returned cleanup overwrites the file before removing it.
+func writeEphemeral(config *Config) (string, func(), error) {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal kubeconfig: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "stackctl-kubeconfig-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	path := filepath.Join(dir, "config")
+	cleanup := func() {
+		if f, err := os.OpenFile(path, os.O_WRONLY, 0); err == nil {
+			_, _ = f.Write(make([]byte, len(data)))
+			_ = f.Sync()
+			_ = f.Close()
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			log.Warnf("⚠️  Failed to remove temporary kubeconfig %s: %v", path, err)
+		}
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write temporary kubeconfig: %w", err)
+	}
+	return path, cleanup, nil
+}
+
+// withoutEnv returns env without the entries for key.
+func withoutEnv(env []string, key string) []string {
+	filtered := make([]string, 0, len(env))
+	for _, entry := range env {
+		if !strings.HasPrefix(entry, key+"=") {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,118 @@
+package kubeconfig
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func requireShell(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+}
+
+func TestExec(t *testing.T) {
+	requireShell(t)
+	t.Setenv("KUBECONFIG", "/should/not/leak")
+
+	var out bytes.Buffer
+	err := Exec(syncContext("prod", "https://prod:6443"), ExecOptions{
+		Args:   []string{"sh", "-c", `echo "$KUBECONFIG"; ls -l "$KUBECONFIG"; cat "$KUBECONFIG"`},
+		Stdout: &out,
+		Stderr: &out,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out.String())
+	}
+
+	lines := strings.SplitN(out.String(), "\n", 3)
+	path := lines[0]
+	if path == "/should/not/leak" || !strings.HasPrefix(filepath.Base(filepath.Dir(path)), "stackctl-kubeconfig-") {
+		t.Errorf("expected KUBECONFIG to point at a temporary file, got %s", path)
+	}
+	if !strings.HasPrefix(lines[1], "-rw-------") {
+		t.Errorf("expected a 0600 file, got %s", lines[1])
+	}
+	if !strings.Contains(lines[2], "server: https://prod:6443") {
+		t.Errorf("expected the context in the file:\n%s", lines[2])
+	}
+	if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Errorf("expected the temporary kubeconfig to be removed, got %v", err)
+	}
+}
+
+func TestExec_ExitCode(t *testing.T) {
+	requireShell(t)
+
+	err := Exec(syncContext("prod", "https://prod:6443"), ExecOptions{Args: []string{"sh", "-c", "exit 3"}})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("expected exit code 3, got %v", err)
+	}
+
+	if err := Exec(&Config{}, ExecOptions{Args: []string{"stackctl-missing-binary"}}); err == nil || errors.As(err, &exitErr) {
+		t.Errorf("expected a start error for a missing binary, got %v", err)
+	}
+}
+
+func TestRemoteContextConfig(t *testing.T) {
+	vault, svc := newFakeVault(t)
+	vault.put(t, "prod", syncContext("prod", "https://prod:6443"))
+
+	bundle := syncContext("a", "https://a:6443")
+	bundle.CurrentContext = ""
+	bundle.Contexts = append(bundle.Contexts, Context{Name: "b", Context: ContextConfig{Cluster: "a-cluster", User: "a-admin"}})
+	vault.put(t, "bundle", bundle)
+
+	config, err := svc.RemoteContextConfig("prod", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.CurrentContext != "prod" || len(config.Contexts) != 1 {
+		t.Errorf("expected a single-context config, got %+v", config)
+	}
+
+	if _, err := svc.RemoteContextConfig("bundle", ""); err == nil || !strings.Contains(err.Error(), "--context") {
+		t.Errorf("expected an error asking for --context, got %v", err)
+	}
+	config, err = svc.RemoteContextConfig("bundle", "b")
+	if err != nil || config.CurrentContext != "b" {
+		t.Errorf("expected context b, got %+v (%v)", config, err)
+	}
+}
+
+func TestLocalContextConfig_ResolvesRelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	config := syncContext("prod", "https://prod:6443")
+	config.Clusters[0].Cluster.CertificateAuthority = "certs/ca.crt"
+	config.Users[0].User.Token = ""
+	config.Users[0].User.ClientCertificate = "certs/client.crt"
+	config.Users[0].User.ClientKey = "/etc/prod/client.key"
+	config.Users[0].User.TokenFile = "token"
+	path := filepath.Join(dir, "config")
+	if err := Save(path, config); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	t.Chdir(t.TempDir())
+
+	single, err := LocalContextConfig(path, "prod")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cluster, user := single.Clusters[0].Cluster, single.Users[0].User
+	if cluster.CertificateAuthority != filepath.Join(dir, "certs", "ca.crt") {
+		t.Errorf("expected an absolute certificate-authority, got %s", cluster.CertificateAuthority)
+	}
+	if user.ClientCertificate != filepath.Join(dir, "certs", "client.crt") || user.TokenFile != filepath.Join(dir, "token") {
+		t.Errorf("expected absolute user paths, got %+v", user)
+	}
+	if user.ClientKey != "/etc/prod/client.key" {
+		t.Errorf("expected an absolute path to be kept, got %s", user.ClientKey)
+	}
+}
@@ -0,0 +1,120 @@
+package kubeconfig
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeConfig parses a kubeconfig given as raw YAML (or JSON) or as base64.
+// Base64 never contains ':', which every kubeconfig document does, so the
+// encoding is detected from that.
+func DecodeConfig(input []byte) (*Config, error) {
+	data := bytes.TrimSpace(input)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("kubeconfig is empty")
+	}
+	if !bytes.ContainsRune(data, ':') {
+		decoded, err := decodeBase64Config(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode base64 config: %w", err)
+		}
+		data = decoded
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	return &config, nil
+}
+
+// Flatten replaces the certificate-authority, client-certificate and
+// client-key file references of config with the inline *-data fields, so the
+// config no longer depends on local files. Relative paths are resolved
+// against baseDir. References that cannot be read are kept and reported in
+// the returned error.
+func Flatten(config *Config, baseDir string) error {
+	return flatten(config, func(kind, name string) string { return baseDir })
+}
+
+// flatten implements Flatten, resolving the relative paths of each cluster
+// and user against the directory returned by dirOf.
+func flatten(config *Config, dirOf func(kind, name string) string) error {
+	var errs []error
+	inline := func(kind, name, field string, file, data *string) {
+		if *file == "" {
+			return
+		}
+		path := resolvePath(dirOf(kind, name), *file)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s %s: %w", kind, name, field, err))
+			return
+		}
+		*data = base64.StdEncoding.EncodeToString(content)
+		*file = ""
+	}
+
+	for i := range config.Clusters {
+		c := &config.Clusters[i].Cluster
+		inline("cluster", config.Clusters[i].Name, "certificate-authority", &c.CertificateAuthority, &c.CertificateAuthorityData)
+	}
+	for i := range config.Users {
+		u := &config.Users[i].User
+		inline("user", config.Users[i].Name, "client-certificate", &u.ClientCertificate, &u.ClientCertificateData)
+		inline("user", config.Users[i].Name, "client-key", &u.ClientKey, &u.ClientKeyData)
+	}
+	return errors.Join(errs...)
+}
+
+// resolvePaths makes the certificate-authority, client-certificate,
+// client-key and tokenFile references of config absolute, resolving each
+// against the directory returned by dirOf, so the config can be written to
+// another directory.
+func resolvePaths(config *Config, dirOf func(kind, name string) string) {
+	for i := range config.Clusters {
+		c := &config.Clusters[i].Cluster
+		c.CertificateAuthority = resolvePath(dirOf("cluster", config.Clusters[i].Name), c.CertificateAuthority)
+	}
+	for i := range config.Users {
+		u := &config.Users[i].User
+		dir := dirOf("user", config.Users[i].Name)
+		u.ClientCertificate = resolvePath(dir, u.ClientCertificate)
+		u.ClientKey = resolvePath(dir, u.ClientKey)
+		u.TokenFile = resolvePath(dir, u.TokenFile)
+	}
+}
+
+// resolvePath joins a relative path to baseDir. Empty and absolute paths
+// and an empty baseDir leave path as is.
+func resolvePath(baseDir, path string) string {
+	if path == "" || baseDir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// FileReferences lists the certificate-authority, client-certificate and
+// client-key file references of config as "<kind> <name> <field>=<path>".
+func FileReferences(config *Config) []string {
+	var refs []string
+	add := func(entry, field, file string) {
+		if file != "" {
+			refs = append(refs, fmt.Sprintf("%s %s=%s", entry, field, file))
+		}
+	}
+	for _, c := range config.Clusters {
+		add("cluster "+c.Name, "certificate-authority", c.Cluster.CertificateAuthority)
+	}
+	for _, u := range config.Users {
+		add("user "+u.Name, "client-certificate", u.User.ClientCertificate)
+		add("user "+u.Name, "client-key", u.User.ClientKey)
+	}
+	return refs
+}
@@ -0,0 +1,166 @@
+package kubeconfig
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const flattenYAML = `apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://dev:6443
+    certificate-authority: ca.crt
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: dev
+users:
+- name: dev
+  user:
+    client-certificate: %s
+    client-key: client.key
+current-context: dev
+`
+
+func TestDecodeConfig(t *testing.T) {
+	raw := "apiVersion: v1\nkind: Config\ncurrent-context: dev\n"
+	for name, input := range map[string]string{
+		"yaml":    raw,
+		"base64":  base64.StdEncoding.EncodeToString([]byte(raw)),
+		"wrapped": "\n" + base64.StdEncoding.EncodeToString([]byte(raw)) + "\n",
+		"json":    `{"apiVersion": "v1", "kind": "Config", "current-context": "dev"}`,
+	} {
+		config, err := DecodeConfig([]byte(input))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if config.CurrentContext != "dev" {
+			t.Errorf("%s: expected current-context dev, got %+v", name, config)
+		}
+	}
+
+	for _, invalid := range []string{"", "!!!not-base64!!!", "clusters: ["} {
+		if _, err := DecodeConfig([]byte(invalid)); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	dir := t.TempDir()
+	cert := newTestCert(t, "admin", time.Hour)
+	for name, content := range map[string][]byte{"ca.crt": cert, "client.crt": cert, "client.key": []byte("key")} {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	config, err := DecodeConfig([]byte(strings.ReplaceAll(flattenYAML, "%s", filepath.Join(dir, "client.crt"))))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Flatten(config, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cluster, user := config.Clusters[0].Cluster, config.Users[0].User
+	if cluster.CertificateAuthority != "" || cluster.CertificateAuthorityData != base64.StdEncoding.EncodeToString(cert) {
+		t.Errorf("expected the relative CA to be inlined, got %+v", cluster)
+	}
+	if user.ClientCertificate != "" || user.ClientCertificateData != base64.StdEncoding.EncodeToString(cert) {
+		t.Errorf("expected the absolute client certificate to be inlined, got %+v", user)
+	}
+	if user.ClientKey != "" || user.ClientKeyData != base64.StdEncoding.EncodeToString([]byte("key")) {
+		t.Errorf("expected the client key to be inlined, got %+v", user)
+	}
+}
+
+func TestFlatten_KeepsUnreadableReferences(t *testing.T) {
+	config, err := DecodeConfig([]byte(strings.ReplaceAll(flattenYAML, "%s", "client.crt")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = Flatten(config, t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "cluster dev certificate-authority") {
+		t.Fatalf("expected the unreadable CA to be reported, got %v", err)
+	}
+	if config.Clusters[0].Cluster.CertificateAuthority != "ca.crt" || config.Users[0].User.ClientKey != "client.key" {
+		t.Errorf("expected unreadable references to be kept, got %+v", config)
+	}
+}
+
+func TestProcessConfigData_FlattensLocalSourcesOnly(t *testing.T) {
+	dir := t.TempDir()
+	cert := newTestCert(t, "admin", time.Hour)
+	for name, content := range map[string][]byte{"ca.crt": cert, "client.crt": cert, "client.key": []byte("key")} {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	data := []byte(strings.ReplaceAll(flattenYAML, "%s", filepath.Join(dir, "client.crt")))
+
+	remotePath := filepath.Join(t.TempDir(), "config")
+	t.Setenv("KUBECONFIG", remotePath)
+	if err := ProcessConfigData(data, ImportOptions{BaseDir: dir}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	remote, err := Load(remotePath)
+	if err != nil {
+		t.Fatalf("failed to load kubeconfig: %v", err)
+	}
+	if remote.Users[0].User.ClientCertificateData != "" || remote.Users[0].User.ClientCertificate == "" {
+		t.Errorf("expected a remote config to keep its references, got %+v", remote.Users[0].User)
+	}
+
+	localPath := filepath.Join(t.TempDir(), "config")
+	t.Setenv("KUBECONFIG", localPath)
+	if err := ProcessConfigData(data, ImportOptions{Flatten: true, BaseDir: dir}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	local, err := Load(localPath)
+	if err != nil {
+		t.Fatalf("failed to load kubeconfig: %v", err)
+	}
+	if local.Clusters[0].Cluster.CertificateAuthorityData == "" || local.Users[0].User.ClientKeyData == "" {
+		t.Errorf("expected a local config to be flattened, got %+v", local)
+	}
+}
+
+func TestLoadWithFiles_ResolvesAgainstOwningFile(t *testing.T) {
+	cert := newTestCert(t, "admin", time.Hour)
+	first, second := filepath.Join(t.TempDir(), "config"), filepath.Join(t.TempDir(), "work")
+	if err := os.WriteFile(first, []byte("apiVersion: v1\nkind: Config\ncurrent-context: dev\n"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := os.WriteFile(second, []byte(strings.ReplaceAll(flattenYAML, "%s", "client.crt")), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	for name, content := range map[string][]byte{"ca.crt": cert, "client.crt": cert, "client.key": []byte("key")} {
+		if err := os.WriteFile(filepath.Join(filepath.Dir(second), name), content, 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	config, sources, err := loadWithFiles(first + string(os.PathListSeparator) + second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dir := sources.dir("cluster", "dev"); dir != filepath.Dir(second) {
+		t.Errorf("expected cluster dev to resolve against %s, got %s", filepath.Dir(second), dir)
+	}
+	if err := flatten(config, sources.dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Clusters[0].Cluster.CertificateAuthorityData == "" || config.Users[0].User.ClientCertificateData == "" {
+		t.Errorf("expected the entries of the second file to be flattened, got %+v", config)
+	}
+}
@@ -7,9 +7,11 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// GetContextConfig extracts a single cluster's kubeconfig and outputs it
-func GetContextConfig(path, clusterName string, encode bool) error {
-	config, err := Load(path)
+// GetContextConfig extracts a single cluster's kubeconfig and outputs it.
+// With flatten, referenced certificate and key files are inlined; relative
+// paths are resolved against the directory of the file defining the entry.
+func GetContextConfig(path, clusterName string, encode, flattenFiles bool) error {
+	config, sources, err := loadWithFiles(path)
 	if err != nil {
 		return fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
@@ -62,6 +64,11 @@ func GetContextConfig(path, clusterName string, encode bool) error {
 		Users:          []User{*targetUser},
 		CurrentContext: targetContext.Name,
 	}
+	if flattenFiles {
+		if err := flatten(singleConfig, sources.dir); err != nil {
+			return fmt.Errorf("failed to flatten config: %w", err)
+		}
+	}
 
 	// Marshal to YAML
 	yamlData, err := yaml.Marshal(singleConfig)
@@ -87,6 +94,17 @@ func GetEncodedContextConfig(path, contextName string) (string, error) {
 		return "", fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
 
+	singleConfig, err := extractContext(config, contextName)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeConfig(singleConfig)
+}
+
+// extractContext returns a config holding only the named context and the
+// cluster and user it references.
+func extractContext(config *Config, contextName string) (*Config, error) {
 	// Find the context
 	var targetContext *Context
 	for _, ctx := range config.Contexts {
@@ -97,7 +115,7 @@ func GetEncodedContextConfig(path, contextName string) (string, error) {
 	}
 
 	if targetContext == nil {
-		return "", fmt.Errorf("context '%s' not found in kubeconfig", contextName)
+		return nil, fmt.Errorf("context '%s' not found in kubeconfig", contextName)
 	}
 
 	// Find associated cluster
@@ -110,7 +128,7 @@ func GetEncodedContextConfig(path, contextName string) (string, error) {
 	}
 
 	if targetCluster == nil {
-		return "", fmt.Errorf("cluster '%s' not found for context '%s'", targetContext.Context.Cluster, contextName)
+		return nil, fmt.Errorf("cluster '%s' not found for context '%s'", targetContext.Context.Cluster, contextName)
 	}
 
 	// Find associated user
@@ -123,21 +141,23 @@ func GetEncodedContextConfig(path, contextName string) (string, error) {
 	}
 
 	if targetUser == nil {
-		return "", fmt.Errorf("user '%s' not found for context '%s'", targetContext.Context.User, contextName)
+		return nil, fmt.Errorf("user '%s' not found for context '%s'", targetContext.Context.User, contextName)
 	}
 
 	// Create a new config with only this context
-	singleConfig := &Config{
+	return &Config{
 		APIVersion:     "v1",
 		Kind:           "Config",
 		Clusters:       []Cluster{*targetCluster},
 		Contexts:       []Context{*targetContext},
 		Users:          []User{*targetUser},
 		CurrentContext: targetContext.Name,
-	}
+	}, nil
+}
 
-	// Marshal to YAML
-	yamlData, err := yaml.Marshal(singleConfig)
+// encodeConfig marshals config to YAML and base64-encodes it.
+func encodeConfig(config *Config) (string, error) {
+	yamlData, err := yaml.Marshal(config)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal config: %w", err)
 	}
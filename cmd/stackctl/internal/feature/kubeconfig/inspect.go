@@ -0,0 +1,299 @@
+package kubeconfig
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// DefaultWarnDays is the default number of days before expiry that a
+// certificate is reported as expiring soon.
+const DefaultWarnDays = 30
+
+// CertificateInfo describes a certificate found in a kubeconfig.
+type CertificateInfo struct {
+	Subject     string
+	Issuer      string
+	NotBefore   time.Time
+	NotAfter    time.Time
+	Fingerprint string // SHA-256, colon separated
+}
+
+// DaysRemaining returns the whole days left until the certificate expires;
+// negative once it has expired.
+func (c CertificateInfo) DaysRemaining(now time.Time) int {
+	return int(math.Floor(c.NotAfter.Sub(now).Hours() / 24))
+}
+
+// ContextReport summarizes the connection settings of a context.
+type ContextReport struct {
+	Context   string
+	Current   bool
+	Cluster   string
+	User      string
+	Namespace string
+
+	Server                string
+	TLSServerName         string
+	InsecureSkipTLSVerify bool
+	ProxyURL              string
+	CASource              string
+
+	AuthType string
+
+	CA         []CertificateInfo
+	ClientCert []CertificateInfo
+
+	// Lease is the Vault lease of a dynamic credential (add-dynamic).
+	Lease *DynamicLease
+
+	// Problems lists issues found while decoding the entries.
+	Problems []string
+}
+
+// Expiring returns the certificates that expire within warnDays.
+func (r *ContextReport) Expiring(now time.Time, warnDays int) []CertificateInfo {
+	var out []CertificateInfo
+	for _, c := range append(append([]CertificateInfo{}, r.CA...), r.ClientCert...) {
+		if c.DaysRemaining(now) < warnDays {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// ExpiryError is returned by Inspect when certificates expire soon.
+type ExpiryError struct {
+	Count    int
+	WarnDays int
+}
+
+func (e *ExpiryError) Error() string {
+	return fmt.Sprintf("%d certificate(s) expired or expiring within %d days", e.Count, e.WarnDays)
+}
+
+// InspectContext builds the report of a single context.
+func InspectContext(config *Config, contextName string) (*ContextReport, error) {
+	target, cluster, user, err := resolveContext(config, contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ContextReport{
+		Context:               contextName,
+		Current:               config.CurrentContext == contextName,
+		Cluster:               target.Context.Cluster,
+		User:                  target.Context.User,
+		Namespace:             target.Context.Namespace,
+		Server:                cluster.Server,
+		TLSServerName:         cluster.TLSServerName,
+		InsecureSkipTLSVerify: cluster.InsecureSkipTLSVerify,
+		ProxyURL:              cluster.ProxyURL,
+		AuthType:              describeAuth(user),
+	}
+
+	switch {
+	case cluster.CertificateAuthorityData != "":
+		report.CASource = "inline (certificate-authority-data)"
+	case cluster.CertificateAuthority != "":
+		report.CASource = cluster.CertificateAuthority
+	default:
+		report.CASource = "system roots"
+	}
+
+	if certs, err := readCertificates(cluster.CertificateAuthorityData, cluster.CertificateAuthority); err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("certificate authority: %v", err))
+	} else {
+		report.CA = certs
+	}
+
+	if user == nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("user '%s' not found", target.Context.User))
+		return report, nil
+	}
+	if certs, err := readCertificates(user.ClientCertificateData, user.ClientCertificate); err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("client certificate: %v", err))
+	} else {
+		report.ClientCert = certs
+	}
+	report.Lease = LeaseFromUser(user)
+
+	return report, nil
+}
+
+// describeAuth names the authentication mechanisms configured for a user.
+func describeAuth(user *UserConfig) string {
+	if user == nil {
+		return "none"
+	}
+	var kinds []string
+	if user.Exec != nil {
+		kinds = append(kinds, fmt.Sprintf("exec plugin (%s)", user.Exec.Command))
+	}
+	if user.AuthProvider != nil {
+		kinds = append(kinds, fmt.Sprintf("auth provider (%s)", user.AuthProvider.Name))
+	}
+	if user.ClientCertificateData != "" || user.ClientCertificate != "" {
+		kinds = append(kinds, "client certificate")
+	}
+	if user.Token != "" {
+		kinds = append(kinds, "bearer token")
+	}
+	if user.TokenFile != "" {
+		kinds = append(kinds, fmt.Sprintf("bearer token file (%s)", user.TokenFile))
+	}
+	if user.Username != "" {
+		kinds = append(kinds, "basic auth")
+	}
+	if user.Impersonate != "" {
+		kinds = append(kinds, fmt.Sprintf("impersonating %s", user.Impersonate))
+	}
+	if len(kinds) == 0 {
+		return "none"
+	}
+	return strings.Join(kinds, " + ")
+}
+
+// readCertificates decodes every PEM certificate from inline data or a file.
+func readCertificates(data, file string) ([]CertificateInfo, error) {
+	raw, err := dataOrFile(data, file)
+	if err != nil || len(raw) == 0 {
+		return nil, err
+	}
+
+	var certs []CertificateInfo
+	for {
+		var block *pem.Block
+		block, raw = pem.Decode(raw)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return certs, fmt.Errorf("invalid certificate: %w", err)
+		}
+		sum := sha256.Sum256(cert.Raw)
+		hexParts := make([]string, len(sum))
+		for i, b := range sum {
+			hexParts[i] = fmt.Sprintf("%02X", b)
+		}
+		certs = append(certs, CertificateInfo{
+			Subject:     cert.Subject.String(),
+			Issuer:      cert.Issuer.String(),
+			NotBefore:   cert.NotBefore,
+			NotAfter:    cert.NotAfter,
+			Fingerprint: strings.Join(hexParts, ":"),
+		})
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM certificates found")
+	}
+	return certs, nil
+}
+
+// Inspect prints the report of one context, or of every context when
+// contextName is empty. It returns an ExpiryError when a certificate has
+// expired or expires within warnDays.
+func Inspect(path, contextName string, warnDays int) error {
+	config, sources, err := loadWithFiles(path)
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	resolvePaths(config, sources.dir)
+
+	names := []string{contextName}
+	if contextName == "" {
+		names = names[:0]
+		for _, ctx := range config.Contexts {
+			names = append(names, ctx.Name)
+		}
+	}
+	if len(names) == 0 {
+		fmt.Println("No contexts found in kubeconfig")
+		return nil
+	}
+
+	now := time.Now()
+	expiring := 0
+	for i, name := range names {
+		report, err := InspectContext(config, name)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		printContextReport(report, now, warnDays)
+		expiring += len(report.Expiring(now, warnDays))
+	}
+
+	if expiring > 0 {
+		return &ExpiryError{Count: expiring, WarnDays: warnDays}
+	}
+	return nil
+}
+
+func printContextReport(r *ContextReport, now time.Time, warnDays int) {
+	current := ""
+	if r.Current {
+		current = " (current)"
+	}
+	fmt.Printf("🔎 Context: %s%s\n", r.Context, current)
+	fmt.Printf("  Cluster:    %s\n", r.Cluster)
+	fmt.Printf("  Server:     %s\n", r.Server)
+	fmt.Printf("  CA:         %s\n", r.CASource)
+	if r.TLSServerName != "" {
+		fmt.Printf("  TLS name:   %s\n", r.TLSServerName)
+	}
+	if r.InsecureSkipTLSVerify {
+		fmt.Println("  TLS verify: ⚠️  disabled (insecure-skip-tls-verify)")
+	}
+	if r.ProxyURL != "" {
+		fmt.Printf("  Proxy:      %s\n", r.ProxyURL)
+	}
+	if r.Namespace != "" {
+		fmt.Printf("  Namespace:  %s\n", r.Namespace)
+	}
+	fmt.Printf("  User:       %s\n", r.User)
+	fmt.Printf("  Auth:       %s\n", r.AuthType)
+	if r.Lease != nil {
+		status := fmt.Sprintf("✅ %s left", r.Lease.Expires.Sub(now).Round(time.Second))
+		if r.Lease.Expired(now) {
+			status = "❌ expired"
+		}
+		fmt.Printf("  Vault lease: %s\n", r.Lease.LeaseID)
+		fmt.Printf("    Expires:   %s (%s)\n", r.Lease.Expires.Format(time.RFC3339), status)
+	}
+
+	printCertificates("CA certificate", r.CA, now, warnDays)
+	printCertificates("Client certificate", r.ClientCert, now, warnDays)
+
+	for _, problem := range r.Problems {
+		fmt.Printf("  ⚠️  %s\n", problem)
+	}
+}
+
+func printCertificates(title string, certs []CertificateInfo, now time.Time, warnDays int) {
+	for _, c := range certs {
+		days := c.DaysRemaining(now)
+		status := fmt.Sprintf("✅ %d days left", days)
+		switch {
+		case days < 0:
+			status = fmt.Sprintf("❌ expired %d days ago", -days)
+		case days < warnDays:
+			status = fmt.Sprintf("⚠️  %d days left", days)
+		}
+		fmt.Printf("  %s:\n", title)
+		fmt.Printf("    Subject:   %s\n", c.Subject)
+		fmt.Printf("    Issuer:    %s\n", c.Issuer)
+		fmt.Printf("    Not after: %s (%s)\n", c.NotAfter.Format(time.RFC3339), status)
+		fmt.Printf("    SHA256:    %s\n", c.Fingerprint)
+	}
+}
@@ -0,0 +1,146 @@
+package kubeconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestCert returns a PEM encoded self-signed certificate valid for ttl.
+func newTestCert(t *testing.T, commonName string, ttl time.Duration) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(ttl),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func writeInspectConfig(t *testing.T, clientTTL time.Duration) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(caPath, newTestCert(t, "cluster-ca", 365*24*time.Hour), 0600); err != nil {
+		t.Fatalf("failed to write ca: %v", err)
+	}
+
+	config := &Config{
+		APIVersion:     "v1",
+		Kind:           "Config",
+		CurrentContext: "dev",
+		Clusters:       []Cluster{{Name: "dev", Cluster: ClusterConfig{Server: "https://dev:6443", CertificateAuthority: caPath}}},
+		Contexts:       []Context{{Name: "dev", Context: ContextConfig{Cluster: "dev", User: "dev"}}},
+		Users: []User{{Name: "dev", User: UserConfig{
+			ClientCertificateData: base64.StdEncoding.EncodeToString(newTestCert(t, "admin", clientTTL)),
+		}}},
+	}
+	path := filepath.Join(dir, "config")
+	if err := Save(path, config); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	return path, caPath
+}
+
+func TestInspectContext_ReportsCertificatesAndAuth(t *testing.T) {
+	path, caPath := writeInspectConfig(t, 90*24*time.Hour)
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+
+	report, err := InspectContext(config, "dev")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.Current || report.CASource != caPath || report.AuthType != "client certificate" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if len(report.CA) != 1 || report.CA[0].Subject != "CN=cluster-ca" {
+		t.Fatalf("expected the CA certificate, got %+v", report.CA)
+	}
+	if parts := strings.Split(report.CA[0].Fingerprint, ":"); len(parts) != 32 {
+		t.Errorf("expected a colon separated SHA-256 fingerprint, got %s", report.CA[0].Fingerprint)
+	}
+	if len(report.ClientCert) != 1 || report.ClientCert[0].Subject != "CN=admin" {
+		t.Errorf("expected the client certificate, got %+v", report.ClientCert)
+	}
+	if days := report.ClientCert[0].DaysRemaining(time.Now()); days != 89 {
+		t.Errorf("expected 89 whole days remaining, got %d", days)
+	}
+}
+
+func TestInspect_FailsWhenCertificateExpiresSoon(t *testing.T) {
+	path, _ := writeInspectConfig(t, 10*24*time.Hour)
+
+	err := Inspect(path, "", DefaultWarnDays)
+	var expiryErr *ExpiryError
+	if !errors.As(err, &expiryErr) || expiryErr.Count != 1 {
+		t.Fatalf("expected one expiring certificate, got %v", err)
+	}
+
+	if err := Inspect(path, "dev", 5); err != nil {
+		t.Errorf("expected no error below the warning window, got %v", err)
+	}
+}
+
+func TestInspectContext_ReportsUnreadableCertificate(t *testing.T) {
+	config := &Config{
+		Clusters: []Cluster{{Name: "dev", Cluster: ClusterConfig{Server: "https://dev", CertificateAuthority: "/does/not/exist"}}},
+		Contexts: []Context{{Name: "dev", Context: ContextConfig{Cluster: "dev", User: "dev"}}},
+		Users:    []User{{Name: "dev", User: UserConfig{Exec: &ExecConfig{Command: "aws"}, Token: "t"}}},
+	}
+
+	report, err := InspectContext(config, "dev")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Problems) != 1 {
+		t.Errorf("expected the missing CA file to be reported, got %v", report.Problems)
+	}
+	if report.AuthType != "exec plugin (aws) + bearer token" {
+		t.Errorf("unexpected auth type: %s", report.AuthType)
+	}
+}
+
+func TestInspect_ResolvesRelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ca.crt"), newTestCert(t, "cluster-ca", 10*24*time.Hour), 0600); err != nil {
+		t.Fatalf("failed to write ca: %v", err)
+	}
+	config := &Config{
+		Clusters: []Cluster{{Name: "dev", Cluster: ClusterConfig{Server: "https://dev:6443", CertificateAuthority: "ca.crt"}}},
+		Contexts: []Context{{Name: "dev", Context: ContextConfig{Cluster: "dev", User: "dev"}}},
+		Users:    []User{{Name: "dev", User: UserConfig{Token: "t"}}},
+	}
+	path := filepath.Join(dir, "config")
+	if err := Save(path, config); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	t.Chdir(t.TempDir())
+
+	var expiryErr *ExpiryError
+	if err := Inspect(path, "dev", DefaultWarnDays); !errors.As(err, &expiryErr) || expiryErr.Count != 1 {
+		t.Errorf("expected the CA next to the kubeconfig to be read, got %v", err)
+	}
+}
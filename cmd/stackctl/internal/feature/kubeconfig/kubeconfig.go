@@ -4,59 +4,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"time"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 )
 
-// Config represents a Kubernetes configuration
-type Config struct {
-	APIVersion     string    `yaml:"apiVersion"`
-	Kind           string    `yaml:"kind"`
-	Clusters       []Cluster `yaml:"clusters"`
-	Contexts       []Context `yaml:"contexts"`
-	Users          []User    `yaml:"users"`
-	CurrentContext string    `yaml:"current-context,omitempty"`
-
-	// Internal field for duplicate detection (not serialized)
-	duplicateInfo *duplicateInfo `yaml:"-"`
-}
-
-type Cluster struct {
-	Name    string        `yaml:"name"`
-	Cluster ClusterConfig `yaml:"cluster"`
-}
-
-type ClusterConfig struct {
-	Server                   string `yaml:"server"`
-	CertificateAuthorityData string `yaml:"certificate-authority-data,omitempty"`
-	InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify,omitempty"`
-}
-
-type Context struct {
-	Name    string        `yaml:"name"`
-	Context ContextConfig `yaml:"context"`
-}
-
-type ContextConfig struct {
-	Cluster   string `yaml:"cluster"`
-	User      string `yaml:"user"`
-	Namespace string `yaml:"namespace,omitempty"`
-}
-
-type User struct {
-	Name string     `yaml:"name"`
-	User UserConfig `yaml:"user"`
-}
-
-type UserConfig struct {
-	ClientCertificateData string `yaml:"client-certificate-data,omitempty"`
-	ClientKeyData         string `yaml:"client-key-data,omitempty"`
-	Token                 string `yaml:"token,omitempty"`
-}
-
-// GetPath returns the path to the kubeconfig file
+// GetPath returns the path to the kubeconfig file. When KUBECONFIG holds a
+// list of files, the raw list is returned; Load and Save understand it.
 func GetPath() string {
 	if kubeconfigEnv := os.Getenv("KUBECONFIG"); kubeconfigEnv != "" {
 		return kubeconfigEnv
@@ -65,8 +20,22 @@ func GetPath() string {
 	return filepath.Join(homeDir, ".kube", "config")
 }
 
-// Load loads an existing kubeconfig file
+// Load loads an existing kubeconfig. The path may be a KUBECONFIG-style list
+// of files, in which case they are merged following kubectl's rules.
 func Load(path string) (*Config, error) {
+	paths := SplitPaths(path)
+	if len(paths) > 1 {
+		config, _, err := loadMerged(path, paths)
+		return config, err
+	}
+	if len(paths) == 1 {
+		path = paths[0]
+	}
+	return loadFile(path)
+}
+
+// loadFile loads a single kubeconfig file.
+func loadFile(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -96,11 +65,18 @@ func LoadAndCheckDuplicates(path string) (*Config, error) {
 	// Store duplicate info in config for later display
 	if totalDups > 0 {
 		// We'll display this message after the main output
+		conflicts := 0
+		for _, d := range FindDuplicates(config) {
+			if !d.Identical() {
+				conflicts++
+			}
+		}
 		config.duplicateInfo = &duplicateInfo{
-			total:    totalDups,
-			clusters: clusterDups,
-			contexts: contextDups,
-			users:    userDups,
+			total:     totalDups,
+			clusters:  clusterDups,
+			contexts:  contextDups,
+			users:     userDups,
+			conflicts: conflicts,
 		}
 	}
 
@@ -108,10 +84,11 @@ func LoadAndCheckDuplicates(path string) (*Config, error) {
 }
 
 type duplicateInfo struct {
-	total    int
-	clusters int
-	contexts int
-	users    int
+	total     int
+	clusters  int
+	contexts  int
+	users     int
+	conflicts int
 }
 
 // ShowDuplicateWarning displays duplicate warning if duplicates were detected
@@ -128,66 +105,44 @@ func ShowDuplicateWarning(config *Config) {
 		if info.users > 0 {
 			fmt.Printf("  - %d duplicate users\n", info.users)
 		}
-		fmt.Println("\n💡 Execute with --clean flag to remove duplicates")
-	}
-}
-
-// Backup creates a timestamped backup of the kubeconfig
-func Backup(path string) (string, error) {
-	timestamp := time.Now().Format("20060102_150405")
-	backupPath := fmt.Sprintf("%s.backup.%s", path, timestamp)
-
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return "", err
-	}
-
-	if err := os.WriteFile(backupPath, data, 0600); err != nil {
-		return "", err
+		if info.conflicts > 0 {
+			fmt.Printf("  - %d names defined with conflicting content (kubectl uses the first)\n", info.conflicts)
+		}
+		fmt.Println("\n💡 Run 'stackctl kubeconfig clean' to remove duplicates")
 	}
-
-	return backupPath, nil
 }
 
-// Deduplicate removes duplicate entries from the config
+// Deduplicate removes duplicate entries from the config, keeping the first
+// definition of each name like kubectl does. Dropping a definition with
+// different content is logged; `clean` lets the user resolve those first.
 func Deduplicate(config *Config) *Config {
-	// Deduplicate clusters
-	seenClusters := make(map[string]bool)
-	uniqueClusters := []Cluster{}
-	for _, cluster := range config.Clusters {
-		if !seenClusters[cluster.Name] {
-			seenClusters[cluster.Name] = true
-			uniqueClusters = append(uniqueClusters, cluster)
+	for _, d := range FindDuplicates(config) {
+		if !d.Identical() {
+			log.Warnf("⚠️  Dropping %d conflicting definitions of %s '%s'; run 'stackctl kubeconfig clean' to keep them",
+				d.Count-1, d.Kind, d.Name)
 		}
 	}
-	config.Clusters = uniqueClusters
-
-	// Deduplicate contexts
-	seenContexts := make(map[string]bool)
-	uniqueContexts := []Context{}
-	for _, context := range config.Contexts {
-		if !seenContexts[context.Name] {
-			seenContexts[context.Name] = true
-			uniqueContexts = append(uniqueContexts, context)
-		}
-	}
-	config.Contexts = uniqueContexts
+	config.Clusters = dedupeEntries(config.Clusters)
+	config.Contexts = dedupeEntries(config.Contexts)
+	config.Users = dedupeEntries(config.Users)
+	return config
+}
 
-	// Deduplicate users
-	seenUsers := make(map[string]bool)
-	uniqueUsers := []User{}
-	for _, user := range config.Users {
-		if !seenUsers[user.Name] {
-			seenUsers[user.Name] = true
-			uniqueUsers = append(uniqueUsers, user)
+// dedupeEntries keeps the first entry of each name.
+func dedupeEntries[T any](items []T) []T {
+	seen := make(map[string]bool)
+	unique := []T{}
+	for _, item := range items {
+		name := entryName(item)
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, item)
 		}
 	}
-	config.Users = uniqueUsers
-
-	return config
+	return unique
 }
 
-// Merge merges new config into existing config
+// Merge merges new config into existing config, replacing same-named entries.
 func Merge(existing, new *Config) *Config {
 	if new == nil {
 		return existing
@@ -197,86 +152,50 @@ func Merge(existing, new *Config) *Config {
 		return Deduplicate(new)
 	}
 
-	// Create a new config to avoid modifying the 'existing' one directly (though it's usually what we want, it's safer this way)
-	merged := &Config{
-		APIVersion:     existing.APIVersion,
-		Kind:           existing.Kind,
-		CurrentContext: existing.CurrentContext,
-		Clusters:       append([]Cluster{}, existing.Clusters...),
-		Contexts:       append([]Context{}, existing.Contexts...),
-		Users:          append([]User{}, existing.Users...),
-	}
-
-	// Preserve apiVersion and kind from new config if current ones are empty
-	if merged.APIVersion == "" && new.APIVersion != "" {
-		merged.APIVersion = new.APIVersion
-	}
-	if merged.Kind == "" && new.Kind != "" {
-		merged.Kind = new.Kind
-	}
+	merged, report, _ := MergeWithOptions(existing, new, MergeOptions{OnConflict: ConflictReplace})
+	logMergeReport(report)
+	return merged
+}
 
-	// Merge ALL clusters from new config
-	for _, newCluster := range new.Clusters {
-		clusterExists := false
-		for i, existingCluster := range merged.Clusters {
-			if existingCluster.Name == newCluster.Name {
-				log.Infof("🔄 Cluster '%s' already exists, replacing configuration", newCluster.Name)
-				merged.Clusters[i] = newCluster
-				clusterExists = true
-				break
-			}
-		}
-		if !clusterExists {
-			log.Infof("➕ Adding new cluster '%s' to kubeconfig", newCluster.Name)
-			merged.Clusters = append(merged.Clusters, newCluster)
+// logMergeReport logs each change of a merge.
+func logMergeReport(report *MergeReport) {
+	for _, c := range report.Changes {
+		switch c.Action {
+		case ActionAdded:
+			log.Infof("➕ Adding new %s '%s' to kubeconfig", c.Kind, c.Name)
+		case ActionReplaced:
+			log.Infof("🔄 %s '%s' already exists, replacing configuration", capitalize(c.Kind), c.Name)
+		case ActionRenamed:
+			log.Infof("🏷️  %s '%s' already exists, importing as '%s'", capitalize(c.Kind), c.Name, c.NewName)
+		case ActionKept:
+			log.Infof("⏭️  %s '%s' already exists, keeping existing configuration", capitalize(c.Kind), c.Name)
 		}
 	}
+}
 
-	// Merge ALL contexts from new config
-	for _, newContext := range new.Contexts {
-		contextExists := false
-		for i, existingContext := range merged.Contexts {
-			if existingContext.Name == newContext.Name {
-				log.Infof("🔄 Context '%s' already exists, replacing configuration", newContext.Name)
-				merged.Contexts[i] = newContext
-				contextExists = true
-				break
-			}
-		}
-		if !contextExists {
-			log.Infof("➕ Adding new context '%s' to kubeconfig", newContext.Name)
-			merged.Contexts = append(merged.Contexts, newContext)
-		}
+func capitalize(s string) string {
+	if s == "" {
+		return s
 	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
 
-	// Merge ALL users from new config
-	for _, newUser := range new.Users {
-		userExists := false
-		for i, existingUser := range merged.Users {
-			if existingUser.Name == newUser.Name {
-				log.Infof("🔄 User '%s' already exists, replacing configuration", newUser.Name)
-				merged.Users[i] = newUser
-				userExists = true
-				break
-			}
-		}
-		if !userExists {
-			log.Infof("➕ Adding new user '%s' to kubeconfig", newUser.Name)
-			merged.Users = append(merged.Users, newUser)
-		}
+// Save saves the kubeconfig. When path is a KUBECONFIG-style list, every
+// entry is written back to the file that owns it. Files are replaced
+// atomically; callers changing an existing config hold its lock (withLock).
+func Save(path string, config *Config) error {
+	paths := SplitPaths(path)
+	if len(paths) > 1 {
+		return saveMerged(paths, Deduplicate(config))
 	}
-
-	// Set current context if not set or just imported
-	if merged.CurrentContext == "" && len(new.Contexts) > 0 {
-		merged.CurrentContext = new.Contexts[0].Name
+	if len(paths) == 1 {
+		path = paths[0]
 	}
-
-	// Deduplicate before returning
-	return Deduplicate(merged)
+	return saveFile(path, config)
 }
 
-// Save saves the kubeconfig to file
-func Save(path string, config *Config) error {
+// saveFile saves the kubeconfig to a single file.
+func saveFile(path string, config *Config) error {
 	// Ensure directory exists
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -291,7 +210,7 @@ func Save(path string, config *Config) error {
 		return fmt.Errorf("failed to marshal kubeconfig: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0600); err != nil {
+	if err := writeFileAtomic(path, data, 0600); err != nil {
 		return fmt.Errorf("failed to write kubeconfig: %w", err)
 	}
 
@@ -0,0 +1,264 @@
+package kubeconfig
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Severity ranks a lint finding.
+type Severity string
+
+const (
+	// SeverityError marks entries kubectl cannot use.
+	SeverityError Severity = "error"
+	// SeverityWarning marks entries that work but are risky or ambiguous.
+	SeverityWarning Severity = "warning"
+	// SeverityInfo marks style issues.
+	SeverityInfo Severity = "info"
+)
+
+// Lint check identifiers.
+const (
+	CheckDanglingCluster       = "dangling-cluster"
+	CheckDanglingUser          = "dangling-user"
+	CheckMissingNamespace      = "missing-namespace"
+	CheckInvalidBase64         = "invalid-base64"
+	CheckInvalidPEM            = "invalid-pem"
+	CheckUnreadableFile        = "unreadable-file"
+	CheckInsecureSkipTLSVerify = "insecure-skip-tls-verify"
+	CheckDuplicateServer       = "duplicate-server"
+	CheckCurrentContext        = "current-context"
+)
+
+// LintFinding is a problem found by Lint.
+type LintFinding struct {
+	Severity Severity `json:"severity"`
+	Check    string   `json:"check"`
+	Kind     string   `json:"kind"` // cluster, context, user or config
+	Name     string   `json:"name"`
+	Message  string   `json:"message"`
+}
+
+// LintReport lists the findings of a kubeconfig, errors first.
+type LintReport struct {
+	Findings []LintFinding
+}
+
+// Count returns the number of findings with the given severity.
+func (r *LintReport) Count(severity Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Print writes the report.
+func (r *LintReport) Print(w io.Writer) {
+	if len(r.Findings) == 0 {
+		_, _ = fmt.Fprintln(w, "✅ No problems found")
+		return
+	}
+	icons := map[Severity]string{SeverityError: "❌", SeverityWarning: "⚠️ ", SeverityInfo: "ℹ️ "}
+	for _, f := range r.Findings {
+		_, _ = fmt.Fprintf(w, "%s %-7s %-8s %s: %s [%s]\n", icons[f.Severity], f.Severity, f.Kind, f.Name, f.Message, f.Check)
+	}
+	_, _ = fmt.Fprintf(w, "\n🔎 %d errors, %d warnings, %d info\n",
+		r.Count(SeverityError), r.Count(SeverityWarning), r.Count(SeverityInfo))
+}
+
+// LintError is returned when findings reach the failing severity.
+type LintError struct {
+	Errors   int
+	Warnings int
+}
+
+func (e *LintError) Error() string {
+	return fmt.Sprintf("kubeconfig has %d error(s) and %d warning(s)", e.Errors, e.Warnings)
+}
+
+// Check returns a LintError when the report has errors, or warnings too when
+// strict is set.
+func (r *LintReport) Check(strict bool) error {
+	errs, warnings := r.Count(SeverityError), r.Count(SeverityWarning)
+	if errs > 0 || (strict && warnings > 0) {
+		return &LintError{Errors: errs, Warnings: warnings}
+	}
+	return nil
+}
+
+// LintFile loads the kubeconfig at path and lints it. Relative certificate
+// and key paths are resolved against the file defining the entry, as kubectl
+// does.
+func LintFile(path string) (*LintReport, error) {
+	config, sources, err := loadWithFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	return lint(config, sources.dir), nil
+}
+
+// linter collects findings.
+type linter struct {
+	findings []LintFinding
+	// dirOf returns the directory relative paths of an entry resolve against.
+	dirOf func(kind, name string) string
+}
+
+func (l *linter) add(severity Severity, check, kind, name, format string, args ...interface{}) {
+	l.findings = append(l.findings, LintFinding{
+		Severity: severity, Check: check, Kind: kind, Name: name, Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Lint checks references, encoded data, TLS settings and the current context
+// of config. Relative certificate and key paths are resolved against the
+// working directory.
+func Lint(config *Config) *LintReport {
+	return lint(config, func(kind, name string) string { return "" })
+}
+
+// lint implements Lint, resolving relative paths with dirOf.
+func lint(config *Config, dirOf func(kind, name string) string) *LintReport {
+	l := &linter{dirOf: dirOf}
+
+	clusters := make(map[string]bool, len(config.Clusters))
+	for _, c := range config.Clusters {
+		clusters[c.Name] = true
+	}
+	users := make(map[string]bool, len(config.Users))
+	for _, u := range config.Users {
+		users[u.Name] = true
+	}
+
+	contexts := make(map[string]bool, len(config.Contexts))
+	for _, ctx := range config.Contexts {
+		contexts[ctx.Name] = true
+		if !clusters[ctx.Context.Cluster] {
+			l.add(SeverityError, CheckDanglingCluster, "context", ctx.Name, "cluster '%s' does not exist", ctx.Context.Cluster)
+		}
+		if !users[ctx.Context.User] {
+			l.add(SeverityError, CheckDanglingUser, "context", ctx.Name, "user '%s' does not exist", ctx.Context.User)
+		}
+		if ctx.Context.Namespace == "" {
+			l.add(SeverityInfo, CheckMissingNamespace, "context", ctx.Name, "no namespace set, kubectl uses 'default'")
+		}
+	}
+
+	servers := make(map[string][]string)
+	for _, c := range config.Clusters {
+		l.pem("cluster", c.Name, "certificate-authority", c.Cluster.CertificateAuthorityData, c.Cluster.CertificateAuthority, "CERTIFICATE")
+		if c.Cluster.InsecureSkipTLSVerify {
+			l.add(SeverityWarning, CheckInsecureSkipTLSVerify, "cluster", c.Name, "TLS verification is disabled")
+		}
+		if server := strings.ToLower(strings.TrimRight(c.Cluster.Server, "/")); server != "" {
+			servers[server] = append(servers[server], c.Name)
+		}
+	}
+	for _, u := range config.Users {
+		l.pem("user", u.Name, "client-certificate", u.User.ClientCertificateData, u.User.ClientCertificate, "CERTIFICATE")
+		l.pem("user", u.Name, "client-key", u.User.ClientKeyData, u.User.ClientKey, "")
+	}
+
+	for server, names := range servers {
+		if len(names) > 1 {
+			l.add(SeverityWarning, CheckDuplicateServer, "cluster", strings.Join(names, ", "), "clusters share the server %s", server)
+		}
+	}
+
+	switch {
+	case config.CurrentContext == "" && len(config.Contexts) > 0:
+		l.add(SeverityWarning, CheckCurrentContext, "config", "current-context", "no current-context set")
+	case config.CurrentContext != "" && !contexts[config.CurrentContext]:
+		l.add(SeverityError, CheckCurrentContext, "config", "current-context", "context '%s' does not exist", config.CurrentContext)
+	}
+
+	findings := l.findings
+	rank := map[Severity]int{SeverityError: 0, SeverityWarning: 1, SeverityInfo: 2}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if rank[findings[i].Severity] != rank[findings[j].Severity] {
+			return rank[findings[i].Severity] < rank[findings[j].Severity]
+		}
+		if findings[i].Kind != findings[j].Kind {
+			return findings[i].Kind < findings[j].Kind
+		}
+		return findings[i].Name < findings[j].Name
+	})
+	return &LintReport{Findings: findings}
+}
+
+// pem checks the inline <field>-data or the file referenced by <field>. An
+// empty blockType accepts any PEM block (private keys); otherwise the blocks
+// of that type must parse as X.509 certificates.
+func (l *linter) pem(kind, name, field, data, file, blockType string) {
+	var raw []byte
+	switch {
+	case data != "":
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			l.add(SeverityError, CheckInvalidBase64, kind, name, "%s-data is not valid base64: %v", field, err)
+			return
+		}
+		raw = decoded
+		field += "-data"
+	case file != "":
+		if dir := l.dirOf(kind, name); !filepath.IsAbs(file) && dir != "" {
+			file = filepath.Join(dir, file)
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			l.add(SeverityError, CheckUnreadableFile, kind, name, "%s: %v", field, err)
+			return
+		}
+		raw = content
+	default:
+		return
+	}
+
+	found := false
+	for {
+		var block *pem.Block
+		block, raw = pem.Decode(raw)
+		if block == nil {
+			break
+		}
+		if blockType == "" {
+			found = true
+			continue
+		}
+		if block.Type != blockType {
+			continue
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			l.add(SeverityError, CheckInvalidPEM, kind, name, "%s holds an invalid certificate: %v", field, err)
+			return
+		}
+		found = true
+	}
+	if !found {
+		l.add(SeverityError, CheckInvalidPEM, kind, name, "%s holds no PEM data", field)
+	}
+}
+
+// warnLint logs the problems of an imported config. It never fails the
+// import: missing namespaces and the current context are left out, as the
+// merge decides the latter.
+func warnLint(config *Config) {
+	for _, f := range Lint(config).Findings {
+		if f.Severity == SeverityInfo || f.Check == CheckCurrentContext {
+			continue
+		}
+		log.Warnf("⚠️  %s %s: %s [%s]", f.Kind, f.Name, f.Message, f.Check)
+	}
+}
@@ -0,0 +1,133 @@
+package kubeconfig
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func lintChecks(report *LintReport) map[string]Severity {
+	checks := make(map[string]Severity)
+	for _, f := range report.Findings {
+		checks[f.Check+" "+f.Kind+"/"+f.Name] = f.Severity
+	}
+	return checks
+}
+
+func TestLint(t *testing.T) {
+	cert := base64.StdEncoding.EncodeToString(newTestCert(t, "ca", time.Hour))
+	config := &Config{
+		CurrentContext: "gone",
+		Clusters: []Cluster{
+			{Name: "dev", Cluster: ClusterConfig{Server: "https://dev:6443", CertificateAuthorityData: cert}},
+			{Name: "dev-copy", Cluster: ClusterConfig{Server: "https://DEV:6443/", InsecureSkipTLSVerify: true}},
+			{Name: "broken", Cluster: ClusterConfig{Server: "https://broken:6443", CertificateAuthorityData: "not base64!"}},
+			{Name: "garbage", Cluster: ClusterConfig{Server: "https://garbage:6443",
+				CertificateAuthorityData: base64.StdEncoding.EncodeToString([]byte("garbage"))}},
+		},
+		Users: []User{
+			{Name: "dev", User: UserConfig{Token: "t"}},
+			{Name: "missing-file", User: UserConfig{ClientCertificate: filepath.Join(t.TempDir(), "missing.crt")}},
+		},
+		Contexts: []Context{
+			{Name: "dev", Context: ContextConfig{Cluster: "dev", User: "dev", Namespace: "apps"}},
+			{Name: "orphan", Context: ContextConfig{Cluster: "nowhere", User: "nobody"}},
+		},
+	}
+
+	report := Lint(config)
+	checks := lintChecks(report)
+	expected := map[string]Severity{
+		"dangling-cluster context/orphan":           SeverityError,
+		"dangling-user context/orphan":              SeverityError,
+		"missing-namespace context/orphan":          SeverityInfo,
+		"invalid-base64 cluster/broken":             SeverityError,
+		"invalid-pem cluster/garbage":               SeverityError,
+		"unreadable-file user/missing-file":         SeverityError,
+		"insecure-skip-tls-verify cluster/dev-copy": SeverityWarning,
+		"duplicate-server cluster/dev, dev-copy":    SeverityWarning,
+		"current-context config/current-context":    SeverityError,
+	}
+	for check, severity := range expected {
+		if checks[check] != severity {
+			t.Errorf("expected %s as %s, got %q", check, severity, checks[check])
+		}
+	}
+	if len(report.Findings) != len(expected) {
+		t.Errorf("unexpected findings: %+v", report.Findings)
+	}
+	if report.Findings[0].Severity != SeverityError || report.Findings[len(report.Findings)-1].Severity != SeverityInfo {
+		t.Errorf("expected findings ordered by severity, got %+v", report.Findings)
+	}
+
+	var lintErr *LintError
+	if err := report.Check(false); !errors.As(err, &lintErr) || lintErr.Errors != 6 || lintErr.Warnings != 2 {
+		t.Errorf("expected a LintError with 6 errors and 2 warnings, got %v", err)
+	}
+}
+
+func TestLint_Strict(t *testing.T) {
+	config := &Config{
+		Clusters: []Cluster{{Name: "dev", Cluster: ClusterConfig{Server: "https://dev:6443"}}},
+		Users:    []User{{Name: "dev", User: UserConfig{Token: "t"}}},
+		Contexts: []Context{{Name: "dev", Context: ContextConfig{Cluster: "dev", User: "dev", Namespace: "apps"}}},
+	}
+
+	report := Lint(config)
+	if len(report.Findings) != 1 || report.Findings[0].Check != CheckCurrentContext {
+		t.Fatalf("expected only the missing current-context, got %+v", report.Findings)
+	}
+	if err := report.Check(false); err != nil {
+		t.Errorf("expected warnings not to fail, got %v", err)
+	}
+	if err := report.Check(true); err == nil {
+		t.Error("expected warnings to fail with strict")
+	}
+
+	config.CurrentContext = "dev"
+	if report := Lint(config); len(report.Findings) != 0 {
+		t.Errorf("expected a clean config, got %+v", report.Findings)
+	}
+}
+
+func TestLintFile_ResolvesRelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ca.crt"), newTestCert(t, "ca", time.Hour), 0600); err != nil {
+		t.Fatalf("failed to write ca.crt: %v", err)
+	}
+	path := filepath.Join(dir, "config")
+	config := `apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://dev:6443
+    certificate-authority: ca.crt
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: dev
+    namespace: apps
+users:
+- name: dev
+  user:
+    token: t
+current-context: dev
+`
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	t.Chdir(t.TempDir())
+
+	report, err := LintFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Findings) != 0 {
+		t.Errorf("expected ca.crt to be read next to the kubeconfig, got %+v", report.Findings)
+	}
+}
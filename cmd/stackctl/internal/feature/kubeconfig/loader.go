@@ -0,0 +1,310 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is a single file from a KUBECONFIG list together with its
+// parsed content. Config is nil when the file does not exist.
+type fileConfig struct {
+	path   string
+	config *Config
+}
+
+// SplitPaths splits a KUBECONFIG-style value into its individual files using
+// the OS path list separator. Empty and repeated entries are dropped, matching
+// kubectl's loading rules.
+func SplitPaths(path string) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, p := range filepath.SplitList(path) {
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+// loadFiles reads every file of a KUBECONFIG list. Missing files are kept in
+// the result with a nil config so they can still be written to later.
+func loadFiles(paths []string) ([]fileConfig, error) {
+	files := make([]fileConfig, 0, len(paths))
+	for _, p := range paths {
+		config, err := loadFile(p)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		files = append(files, fileConfig{path: p, config: config})
+	}
+	return files, nil
+}
+
+// mergeFiles builds the effective view of a KUBECONFIG list: the first file
+// to define a cluster, context or user name wins, as does the first file to
+// set current-context, preferences or extensions.
+func mergeFiles(files []fileConfig) *Config {
+	merged := &Config{}
+	owners := ownersOf(files)
+
+	for i, f := range files {
+		if f.config == nil {
+			continue
+		}
+		c := f.config
+		if merged.APIVersion == "" {
+			merged.APIVersion = c.APIVersion
+		}
+		if merged.Kind == "" {
+			merged.Kind = c.Kind
+		}
+		if merged.CurrentContext == "" {
+			merged.CurrentContext = c.CurrentContext
+		}
+		if merged.Preferences == nil {
+			merged.Preferences = c.Preferences
+		}
+		if merged.Extensions == nil {
+			merged.Extensions = c.Extensions
+		}
+		if merged.Extra == nil {
+			merged.Extra = c.Extra
+		}
+		merged.Clusters = append(merged.Clusters, ownedBy(c.Clusters, owners.clusters, i)...)
+		merged.Contexts = append(merged.Contexts, ownedBy(c.Contexts, owners.contexts, i)...)
+		merged.Users = append(merged.Users, ownedBy(c.Users, owners.users, i)...)
+	}
+
+	return merged
+}
+
+// loadMerged loads and merges every file in a KUBECONFIG list, and reports
+// the file that owns each cluster and user. It returns a not-exist error only
+// when none of the files exist.
+func loadMerged(path string, paths []string) (*Config, entryFiles, error) {
+	files, err := loadFiles(paths)
+	if err != nil {
+		return nil, entryFiles{}, err
+	}
+
+	found := false
+	for _, f := range files {
+		if f.config != nil {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, entryFiles{}, &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+	}
+
+	owners := ownersOf(files)
+	sources := entryFiles{clusters: make(map[string]string), users: make(map[string]string)}
+	for name, i := range owners.clusters {
+		sources.clusters[name] = files[i].path
+	}
+	for name, i := range owners.users {
+		sources.users[name] = files[i].path
+	}
+	return mergeFiles(files), sources, nil
+}
+
+// entryFiles maps the clusters and users of a loaded kubeconfig to the file
+// that defines them. kubectl resolves relative certificate and key paths
+// against that file's directory.
+type entryFiles struct {
+	clusters map[string]string
+	users    map[string]string
+}
+
+// dir returns the directory relative paths of the named cluster or user
+// resolve against; empty when unknown.
+func (f entryFiles) dir(kind, name string) string {
+	files := f.clusters
+	if kind == "user" {
+		files = f.users
+	}
+	if file, ok := files[name]; ok {
+		return filepath.Dir(file)
+	}
+	return ""
+}
+
+// loadWithFiles is Load that also reports the file defining each cluster
+// and user.
+func loadWithFiles(path string) (*Config, entryFiles, error) {
+	paths := SplitPaths(path)
+	if len(paths) > 1 {
+		return loadMerged(path, paths)
+	}
+	if len(paths) == 1 {
+		path = paths[0]
+	}
+	config, err := loadFile(path)
+	if err != nil {
+		return nil, entryFiles{}, err
+	}
+	sources := entryFiles{clusters: make(map[string]string), users: make(map[string]string)}
+	for _, c := range config.Clusters {
+		sources.clusters[c.Name] = path
+	}
+	for _, u := range config.Users {
+		sources.users[u.Name] = path
+	}
+	return config, sources, nil
+}
+
+// saveMerged writes a merged config back to a KUBECONFIG list. Each entry is
+// written to the file that currently owns it; entries that no file owns yet
+// go to the first file. Entries shadowed by an earlier file are left alone and
+// files without changes are not rewritten.
+func saveMerged(paths []string, config *Config) error {
+	files, err := loadFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	owners := ownersOf(files)
+	currentOwner := 0
+	for i, f := range files {
+		if f.config != nil && f.config.CurrentContext != "" {
+			currentOwner = i
+			break
+		}
+	}
+
+	for i, f := range files {
+		updated := &Config{APIVersion: "v1", Kind: "Config"}
+		if f.config != nil {
+			copied := *f.config
+			updated = &copied
+		} else if config.APIVersion != "" {
+			updated.APIVersion = config.APIVersion
+			updated.Kind = config.Kind
+		}
+
+		var originalClusters []Cluster
+		var originalContexts []Context
+		var originalUsers []User
+		if f.config != nil {
+			originalClusters = f.config.Clusters
+			originalContexts = f.config.Contexts
+			originalUsers = f.config.Users
+		}
+		updated.Clusters = distribute(config.Clusters, originalClusters, owners.clusters, i)
+		updated.Contexts = distribute(config.Contexts, originalContexts, owners.contexts, i)
+		updated.Users = distribute(config.Users, originalUsers, owners.users, i)
+		if i == currentOwner {
+			updated.CurrentContext = config.CurrentContext
+		}
+
+		if f.config == nil {
+			if len(updated.Clusters) == 0 && len(updated.Contexts) == 0 &&
+				len(updated.Users) == 0 && updated.CurrentContext == "" {
+				continue
+			}
+		} else if sameContent(f.config, updated) {
+			continue
+		}
+
+		if err := saveFile(f.path, updated); err != nil {
+			return fmt.Errorf("%s: %w", f.path, err)
+		}
+	}
+
+	return nil
+}
+
+// entryOwners maps entry names to the index of the first file defining them.
+type entryOwners struct {
+	clusters map[string]int
+	contexts map[string]int
+	users    map[string]int
+}
+
+func ownersOf(files []fileConfig) entryOwners {
+	owners := entryOwners{
+		clusters: make(map[string]int),
+		contexts: make(map[string]int),
+		users:    make(map[string]int),
+	}
+	for i, f := range files {
+		if f.config == nil {
+			continue
+		}
+		recordOwners(f.config.Clusters, owners.clusters, i)
+		recordOwners(f.config.Contexts, owners.contexts, i)
+		recordOwners(f.config.Users, owners.users, i)
+	}
+	return owners
+}
+
+func recordOwners[T any](items []T, owners map[string]int, idx int) {
+	for _, item := range items {
+		name := entryName(item)
+		if _, ok := owners[name]; !ok {
+			owners[name] = idx
+		}
+	}
+}
+
+// ownedBy returns the items of file idx that are not shadowed by an earlier file.
+func ownedBy[T any](items []T, owners map[string]int, idx int) []T {
+	var out []T
+	for _, item := range items {
+		if owners[entryName(item)] == idx {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// distribute returns the entries file idx should contain after a write: the
+// merged entries it owns (plus new entries when it is the first file),
+// followed by its own entries that an earlier file shadows.
+func distribute[T any](merged, original []T, owners map[string]int, idx int) []T {
+	var out []T
+	for _, item := range merged {
+		owner, known := owners[entryName(item)]
+		if (known && owner == idx) || (!known && idx == 0) {
+			out = append(out, item)
+		}
+	}
+	for _, item := range original {
+		if owners[entryName(item)] != idx {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// entryName returns the name of a cluster, context or user entry.
+func entryName[T any](item T) string {
+	switch v := any(item).(type) {
+	case Cluster:
+		return v.Name
+	case Context:
+		return v.Name
+	case User:
+		return v.Name
+	}
+	return ""
+}
+
+// sameContent reports whether two configs serialize to the same YAML.
+func sameContent(a, b *Config) bool {
+	left, err := yaml.Marshal(a)
+	if err != nil {
+		return false
+	}
+	right, err := yaml.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(left) == string(right)
+}
@@ -0,0 +1,241 @@
+package kubeconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const primaryFile = `apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: https://dev:6443
+- name: shared
+  cluster:
+    server: https://primary-shared:6443
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: dev
+users:
+- name: dev
+  user:
+    token: dev-token
+`
+
+const secondaryFile = `apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: prod
+  cluster:
+    server: https://prod:6443
+- name: shared
+  cluster:
+    server: https://secondary-shared:6443
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: prod
+users:
+- name: prod
+  user:
+    token: prod-token
+`
+
+// writeKubeconfigList writes the given files into a temp dir and returns
+// their paths and the KUBECONFIG-style list joining them.
+func writeKubeconfigList(t *testing.T, contents ...string) ([]string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	var paths []string
+	for i, content := range contents {
+		p := filepath.Join(dir, "config-"+string(rune('a'+i)))
+		if content != "" {
+			if err := os.WriteFile(p, []byte(content), 0600); err != nil {
+				t.Fatalf("failed to write %s: %v", p, err)
+			}
+		}
+		paths = append(paths, p)
+	}
+	return paths, strings.Join(paths, string(os.PathListSeparator))
+}
+
+func TestSplitPaths_DropsEmptyAndDuplicateEntries(t *testing.T) {
+	sep := string(os.PathListSeparator)
+	got := SplitPaths("a" + sep + sep + "b" + sep + "a")
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("expected [a b], got %v", got)
+	}
+}
+
+func TestLoadSave_SingleEntryList(t *testing.T) {
+	sep := string(os.PathListSeparator)
+	for name, list := range map[string]func(string) string{
+		"trailing separator": func(p string) string { return p + sep },
+		"duplicated entry":   func(p string) string { return p + sep + p },
+	} {
+		t.Run(name, func(t *testing.T) {
+			paths, _ := writeKubeconfigList(t, primaryFile)
+			path := list(paths[0])
+
+			if _, _, err := loadWithFiles(path); err != nil {
+				t.Fatalf("failed to load %q: %v", path, err)
+			}
+			if err := SetNamespace(path, "dev", "payments"); err != nil {
+				t.Fatalf("failed to set namespace: %v", err)
+			}
+
+			config, err := Load(path)
+			if err != nil {
+				t.Fatalf("failed to load %q: %v", path, err)
+			}
+			if config.Contexts[0].Context.Namespace != "payments" {
+				t.Errorf("expected the namespace to be saved, got %+v", config.Contexts[0])
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("expected no file named %q, got %v", path, err)
+			}
+		})
+	}
+}
+
+func TestLoad_MultiFileFirstDefinitionWins(t *testing.T) {
+	_, list := writeKubeconfigList(t, primaryFile, "", secondaryFile)
+
+	config, err := Load(list)
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+
+	if config.CurrentContext != "dev" {
+		t.Errorf("expected current-context from first file, got %q", config.CurrentContext)
+	}
+	if len(config.Clusters) != 3 {
+		t.Fatalf("expected 3 clusters, got %d", len(config.Clusters))
+	}
+	for _, c := range config.Clusters {
+		if c.Name == "shared" && c.Cluster.Server != "https://primary-shared:6443" {
+			t.Errorf("expected first file to win for 'shared', got %s", c.Cluster.Server)
+		}
+	}
+	if len(config.Contexts) != 2 || len(config.Users) != 2 {
+		t.Errorf("expected contexts and users from both files, got %d/%d", len(config.Contexts), len(config.Users))
+	}
+}
+
+func TestLoad_MultiFileNoneExist(t *testing.T) {
+	_, list := writeKubeconfigList(t, "", "")
+	if _, err := Load(list); !os.IsNotExist(err) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+}
+
+func TestSave_MultiFileWritesBackToOwningFile(t *testing.T) {
+	paths, list := writeKubeconfigList(t, primaryFile, secondaryFile)
+
+	if err := SetNamespace(list, "prod", "payments"); err != nil {
+		t.Fatalf("failed to set namespace: %v", err)
+	}
+
+	second, err := loadFile(paths[1])
+	if err != nil {
+		t.Fatalf("failed to load second file: %v", err)
+	}
+	if second.Contexts[0].Context.Namespace != "payments" {
+		t.Errorf("expected namespace written to owning file, got %+v", second.Contexts[0])
+	}
+	if len(second.Clusters) != 2 {
+		t.Errorf("expected shadowed cluster to be kept in second file, got %d clusters", len(second.Clusters))
+	}
+
+	first, err := loadFile(paths[0])
+	if err != nil {
+		t.Fatalf("failed to load first file: %v", err)
+	}
+	for _, ctx := range first.Contexts {
+		if ctx.Name == "prod" {
+			t.Error("context 'prod' must not be copied into the first file")
+		}
+	}
+}
+
+func TestSave_MultiFileNewEntriesGoToFirstFile(t *testing.T) {
+	paths, list := writeKubeconfigList(t, primaryFile, secondaryFile)
+
+	existing, err := Load(list)
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	incoming := &Config{
+		Clusters: []Cluster{{Name: "staging", Cluster: ClusterConfig{Server: "https://staging:6443"}}},
+		Contexts: []Context{{Name: "staging", Context: ContextConfig{Cluster: "staging", User: "staging"}}},
+		Users:    []User{{Name: "staging", User: UserConfig{Token: "t"}}},
+	}
+
+	secondInfo, err := os.Stat(paths[1])
+	if err != nil {
+		t.Fatalf("failed to stat second file: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	if err := Save(list, Merge(existing, incoming)); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+
+	first, err := loadFile(paths[0])
+	if err != nil {
+		t.Fatalf("failed to load first file: %v", err)
+	}
+	found := false
+	for _, ctx := range first.Contexts {
+		if ctx.Name == "staging" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected new context to be written to the first file")
+	}
+
+	after, err := os.Stat(paths[1])
+	if err != nil {
+		t.Fatalf("failed to stat second file: %v", err)
+	}
+	if !after.ModTime().Equal(secondInfo.ModTime()) {
+		t.Error("expected unchanged second file not to be rewritten")
+	}
+}
+
+func TestRemoveConfig_MultiFileRemovesFromOwner(t *testing.T) {
+	paths, list := writeKubeconfigList(t, primaryFile, secondaryFile)
+
+	if err := RemoveConfig(list, "prod"); err != nil {
+		t.Fatalf("failed to remove: %v", err)
+	}
+
+	second, err := loadFile(paths[1])
+	if err != nil {
+		t.Fatalf("failed to load second file: %v", err)
+	}
+	if len(second.Contexts) != 0 || len(second.Users) != 0 {
+		t.Errorf("expected prod context and user removed, got %+v / %+v", second.Contexts, second.Users)
+	}
+	if second.CurrentContext != "prod" {
+		t.Errorf("expected current-context of second file untouched, got %q", second.CurrentContext)
+	}
+
+	merged, err := Load(list)
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if merged.CurrentContext != "dev" {
+		t.Errorf("expected effective current-context 'dev', got %q", merged.CurrentContext)
+	}
+}
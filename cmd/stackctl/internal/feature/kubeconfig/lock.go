@@ -0,0 +1,117 @@
+package kubeconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// lockSuffix follows client-go's convention: kubectl creates "<file>.lock"
+// with O_EXCL before writing a kubeconfig and removes it afterwards, so
+// honoring the same file keeps stackctl and kubectl from racing.
+const lockSuffix = ".lock"
+
+var (
+	// lockTimeout is how long to wait for another process to release a lock.
+	lockTimeout = 10 * time.Second
+	// lockRetryInterval is the delay between lock attempts.
+	lockRetryInterval = 100 * time.Millisecond
+)
+
+// lockFile acquires the advisory lock of a single kubeconfig file and
+// returns the function that releases it.
+func lockFile(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	lockPath := path + lockSuffix
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, _ = f.WriteString(strconv.Itoa(os.Getpid()))
+			_ = f.Close()
+			return func() { _ = os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("kubeconfig is locked by another process (%s); remove the lock file if no other process is running", lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// withLock runs fn while holding the locks of every file in a (possibly
+// multi-file) kubeconfig path. Files are locked in sorted order so two
+// processes locking the same list cannot deadlock.
+func withLock(path string, fn func() error) error {
+	paths := SplitPaths(path)
+	sort.Strings(paths)
+
+	var unlocks []func()
+	defer func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}()
+
+	for _, p := range paths {
+		unlock, err := lockFile(p)
+		if err != nil {
+			return err
+		}
+		unlocks = append(unlocks, unlock)
+	}
+
+	return fn()
+}
+
+// writeFileAtomic writes data to a temporary file in the target directory,
+// syncs it and renames it over path, so readers never observe a partially
+// written kubeconfig and a crash leaves the previous content intact. A
+// symlinked path is resolved first so the link target is updated and the
+// link itself survives.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	// Persist the rename itself; not supported on every platform.
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWriteFileAtomic_ReplacesContentWithoutLeftovers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if err := writeFileAtomic(path, []byte("new"), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "new" {
+		t.Errorf("expected new content, got %q", data)
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected no temp files left behind, got %d entries", len(entries))
+	}
+}
+
+func TestWriteFileAtomic_UpdatesSymlinkTarget(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "kubeconfig")
+	link := filepath.Join(dir, "config")
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(target, []byte("old"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := writeFileAtomic(link, []byte("new"), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("expected %s to stay a symlink, got %v (%v)", link, info, err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "new" {
+		t.Errorf("expected the link target to be updated, got %q", data)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(target)); len(entries) != 1 {
+		t.Errorf("expected no temp files left next to the target, got %d entries", len(entries))
+	}
+}
+
+func TestWithLock_HonorsExistingLockFile(t *testing.T) {
+	origTimeout, origInterval := lockTimeout, lockRetryInterval
+	lockTimeout, lockRetryInterval = 200*time.Millisecond, 10*time.Millisecond
+	defer func() { lockTimeout, lockRetryInterval = origTimeout, origInterval }()
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(primaryFile), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	// Simulate kubectl holding the lock.
+	if err := os.WriteFile(path+lockSuffix, nil, 0600); err != nil {
+		t.Fatalf("failed to write lock: %v", err)
+	}
+
+	err := SetNamespace(path, "dev", "other")
+	if err == nil || !strings.Contains(err.Error(), "locked by another process") {
+		t.Fatalf("expected lock error, got %v", err)
+	}
+
+	// Release the lock while a writer is waiting.
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = os.Remove(path + lockSuffix)
+	}()
+	if err := SetNamespace(path, "dev", "other"); err != nil {
+		t.Fatalf("expected write after lock release, got %v", err)
+	}
+	if _, err := os.Stat(path + lockSuffix); !os.IsNotExist(err) {
+		t.Error("expected lock file to be removed after the write")
+	}
+}
+
+func TestImportConfig_ConcurrentWritersDoNotLoseEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	t.Setenv(BackupKeepEnvVar, "1")
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("ctx-%d", i)
+			incoming := &Config{
+				Clusters: []Cluster{{Name: name, Cluster: ClusterConfig{Server: "https://" + name}}},
+				Contexts: []Context{{Name: name, Context: ContextConfig{Cluster: name, User: name}}},
+				Users:    []User{{Name: name, User: UserConfig{Token: name}}},
+			}
+			if _, err := importConfig(path, incoming, ImportOptions{}); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if len(config.Contexts) != writers {
+		t.Errorf("expected %d contexts, got %d", writers, len(config.Contexts))
+	}
+}
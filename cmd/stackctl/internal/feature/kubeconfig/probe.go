@@ -0,0 +1,379 @@
+package kubeconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultValidateTimeout bounds a single cluster probe.
+const DefaultValidateTimeout = 10 * time.Second
+
+// ValidationMode decides whether imported contexts are probed and whether a
+// failed probe aborts the import.
+type ValidationMode string
+
+const (
+	// ValidateSkip does not probe the imported contexts.
+	ValidateSkip ValidationMode = "skip"
+	// ValidateWarn probes and only logs failures.
+	ValidateWarn ValidationMode = "warn"
+	// ValidateStrict probes and aborts the import on failure.
+	ValidateStrict ValidationMode = "strict"
+)
+
+// ProbeErrorKind classifies why a cluster probe failed.
+type ProbeErrorKind string
+
+const (
+	ProbeConfigError  ProbeErrorKind = "config"
+	ProbeNetworkError ProbeErrorKind = "network"
+	ProbeTLSError     ProbeErrorKind = "tls"
+	ProbeAuthError    ProbeErrorKind = "auth"
+	ProbeServerError  ProbeErrorKind = "server"
+)
+
+// ProbeError is returned by ProbeContext.
+type ProbeError struct {
+	Kind    ProbeErrorKind
+	Context string
+	Err     error
+}
+
+func (e *ProbeError) Error() string {
+	return fmt.Sprintf("%s error for context '%s': %v", e.Kind, e.Context, e.Err)
+}
+
+func (e *ProbeError) Unwrap() error {
+	return e.Err
+}
+
+// ProbeResult describes a reachable cluster.
+type ProbeResult struct {
+	Context string
+	Server  string
+	Version string
+	Ready   bool
+	// ReadyUnknown is set when the server has no /readyz endpoint, as older
+	// API servers and some proxies do.
+	ReadyUnknown bool
+	// AuthSkipped is set when the user authenticates through an exec plugin
+	// or auth provider, whose credentials the probe does not evaluate.
+	AuthSkipped bool
+}
+
+// ProbeContext calls /version and /readyz on the cluster of a context using
+// the context's CA, client certificate, token or basic auth credentials.
+func ProbeContext(ctx context.Context, config *Config, contextName string) (*ProbeResult, error) {
+	fail := func(kind ProbeErrorKind, err error) error {
+		return &ProbeError{Kind: kind, Context: contextName, Err: err}
+	}
+
+	target, cluster, user, err := resolveContext(config, contextName)
+	if err != nil {
+		return nil, fail(ProbeConfigError, err)
+	}
+	server := strings.TrimRight(cluster.Server, "/")
+	if server == "" {
+		return nil, fail(ProbeConfigError, fmt.Errorf("cluster '%s' has no server", target.Context.Cluster))
+	}
+
+	client, err := probeHTTPClient(cluster, user)
+	if err != nil {
+		return nil, fail(ProbeConfigError, err)
+	}
+	authorize, err := probeAuthorizer(user)
+	if err != nil {
+		return nil, fail(ProbeConfigError, err)
+	}
+
+	result := &ProbeResult{
+		Context:     contextName,
+		Server:      server,
+		AuthSkipped: user != nil && (user.Exec != nil || user.AuthProvider != nil),
+	}
+
+	get := func(path string) (int, []byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server+path, nil)
+		if err != nil {
+			return 0, nil, fail(ProbeConfigError, err)
+		}
+		authorize(req)
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, nil, fail(classifyTransportError(err), err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		return resp.StatusCode, body, nil
+	}
+
+	checkStatus := func(path string, status int, body []byte) error {
+		switch {
+		case status >= 200 && status < 300:
+			return nil
+		case status == http.StatusUnauthorized || status == http.StatusForbidden:
+			if result.AuthSkipped {
+				return nil
+			}
+			return fail(ProbeAuthError, fmt.Errorf("%s returned HTTP %d", path, status))
+		default:
+			return fail(ProbeServerError, fmt.Errorf("%s returned HTTP %d: %s", path, status, strings.TrimSpace(string(body))))
+		}
+	}
+
+	status, body, err := get("/version")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatus("/version", status, body); err != nil {
+		return nil, err
+	}
+	var version struct {
+		GitVersion string `json:"gitVersion"`
+	}
+	if json.Unmarshal(body, &version) == nil {
+		result.Version = version.GitVersion
+	}
+
+	status, body, err = get("/readyz")
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		result.ReadyUnknown = true
+		return result, nil
+	}
+	if err := checkStatus("/readyz", status, body); err != nil {
+		return nil, err
+	}
+	result.Ready = status >= 200 && status < 300
+
+	return result, nil
+}
+
+// resolveContext finds a context and the cluster and user it references.
+// The user is nil when the context has none.
+func resolveContext(config *Config, contextName string) (*Context, *ClusterConfig, *UserConfig, error) {
+	var target *Context
+	for i := range config.Contexts {
+		if config.Contexts[i].Name == contextName {
+			target = &config.Contexts[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, nil, nil, fmt.Errorf("context '%s' not found", contextName)
+	}
+
+	var cluster *ClusterConfig
+	for i := range config.Clusters {
+		if config.Clusters[i].Name == target.Context.Cluster {
+			cluster = &config.Clusters[i].Cluster
+			break
+		}
+	}
+	if cluster == nil {
+		return nil, nil, nil, fmt.Errorf("cluster '%s' not found", target.Context.Cluster)
+	}
+
+	var user *UserConfig
+	for i := range config.Users {
+		if config.Users[i].Name == target.Context.User {
+			user = &config.Users[i].User
+			break
+		}
+	}
+	return target, cluster, user, nil
+}
+
+// probeHTTPClient builds an HTTP client trusting the cluster CA and
+// presenting the user's client certificate.
+func probeHTTPClient(cluster *ClusterConfig, user *UserConfig) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         cluster.TLSServerName,
+		InsecureSkipVerify: cluster.InsecureSkipTLSVerify,
+	}
+
+	caPEM, err := dataOrFile(cluster.CertificateAuthorityData, cluster.CertificateAuthority)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate authority: %w", err)
+	}
+	if len(caPEM) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("certificate authority contains no valid PEM certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if user != nil {
+		certPEM, err := dataOrFile(user.ClientCertificateData, user.ClientCertificate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client certificate: %w", err)
+		}
+		keyPEM, err := dataOrFile(user.ClientKeyData, user.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client key: %w", err)
+		}
+		if len(certPEM) > 0 && len(keyPEM) > 0 {
+			cert, err := tls.X509KeyPair(certPEM, keyPEM)
+			if err != nil {
+				return nil, fmt.Errorf("invalid client certificate: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	// Like kubectl, honor HTTPS_PROXY and NO_PROXY unless proxy-url is set.
+	transport := &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment}
+	if cluster.ProxyURL != "" {
+		proxy, err := url.Parse(cluster.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy-url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// probeAuthorizer returns a function adding the user's bearer token or basic
+// auth credentials to a request.
+func probeAuthorizer(user *UserConfig) (func(*http.Request), error) {
+	if user == nil {
+		return func(*http.Request) {}, nil
+	}
+
+	token := user.Token
+	if token == "" && user.TokenFile != "" {
+		data, err := os.ReadFile(user.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	return func(req *http.Request) {
+		switch {
+		case token != "":
+			req.Header.Set("Authorization", "Bearer "+token)
+		case user.Username != "":
+			req.SetBasicAuth(user.Username, user.Password)
+		}
+	}, nil
+}
+
+// dataOrFile returns the decoded inline data, or the content of file.
+func dataOrFile(data, file string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if file != "" {
+		return os.ReadFile(file)
+	}
+	return nil, nil
+}
+
+// classifyTransportError tells TLS failures apart from network failures.
+func classifyTransportError(err error) ProbeErrorKind {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		unknownCA    x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidCert  x509.CertificateInvalidError
+		recordHeader tls.RecordHeaderError
+		alertErr     tls.AlertError
+	)
+	switch {
+	case errors.As(err, &verifyErr), errors.As(err, &unknownCA), errors.As(err, &hostnameErr),
+		errors.As(err, &invalidCert), errors.As(err, &recordHeader), errors.As(err, &alertErr):
+		return ProbeTLSError
+	case strings.Contains(err.Error(), "tls:") || strings.Contains(err.Error(), "x509:"):
+		return ProbeTLSError
+	}
+	return ProbeNetworkError
+}
+
+// probeWithTimeout runs ProbeContext bounded by timeout.
+func probeWithTimeout(config *Config, contextName string, timeout time.Duration) (*ProbeResult, error) {
+	if timeout <= 0 {
+		timeout = DefaultValidateTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return ProbeContext(ctx, config, contextName)
+}
+
+// validateContexts probes every context of config according to mode. In strict
+// mode the first failures are returned; otherwise they are only logged.
+func validateContexts(config *Config, mode ValidationMode, timeout time.Duration) error {
+	if mode == "" || mode == ValidateSkip {
+		return nil
+	}
+
+	var failures []error
+	for _, ctx := range config.Contexts {
+		log.Infof("🔍 Validating cluster connection for context: %s...", ctx.Name)
+		result, err := probeWithTimeout(config, ctx.Name, timeout)
+		if err != nil {
+			if mode == ValidateStrict {
+				log.Errorf("❌ %v", err)
+			} else {
+				log.Warnf("⚠️  %v", err)
+			}
+			failures = append(failures, err)
+			continue
+		}
+		logProbeResult(result)
+	}
+
+	if len(failures) > 0 && mode == ValidateStrict {
+		return fmt.Errorf("cluster validation failed: %w", errors.Join(failures...))
+	}
+	return nil
+}
+
+func logProbeResult(result *ProbeResult) {
+	version := result.Version
+	if version == "" {
+		version = "unknown version"
+	}
+	log.Infof("✅ Connection validated successfully! (%s, %s)", result.Server, version)
+	if result.ReadyUnknown {
+		log.Info("ℹ️  The server has no /readyz endpoint, so readiness is unknown")
+	}
+	if result.AuthSkipped {
+		log.Info("ℹ️  Credentials come from an exec plugin or auth provider and were not checked")
+	}
+}
+
+// ValidateConfig checks cluster connectivity for the given context name of
+// the local kubeconfig.
+func ValidateConfig(contextName string) error {
+	config, sources, err := loadWithFiles(GetPath())
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	resolvePaths(config, sources.dir)
+
+	log.Infof("🔍 Validating cluster connection for context: %s...", contextName)
+	result, err := probeWithTimeout(config, contextName, DefaultValidateTimeout)
+	if err != nil {
+		return err
+	}
+	logProbeResult(result)
+	return nil
+}
@@ -0,0 +1,219 @@
+package kubeconfig
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newProbeServer starts a TLS API server stub that accepts the bearer token
+// "good" on /readyz and serves /version anonymously.
+func newProbeServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"gitVersion":"v1.30.1"}`))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	})
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// probeConfig builds a single-context config pointing at server.
+func probeConfig(server *httptest.Server, user UserConfig, withCA bool) *Config {
+	cluster := ClusterConfig{Server: server.URL}
+	if withCA {
+		caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		cluster.CertificateAuthorityData = base64.StdEncoding.EncodeToString(caPEM)
+	}
+	return &Config{
+		Clusters: []Cluster{{Name: "test", Cluster: cluster}},
+		Contexts: []Context{{Name: "test", Context: ContextConfig{Cluster: "test", User: "test"}}},
+		Users:    []User{{Name: "test", User: user}},
+	}
+}
+
+func probeKind(t *testing.T, err error) ProbeErrorKind {
+	t.Helper()
+	var probeErr *ProbeError
+	if !errors.As(err, &probeErr) {
+		t.Fatalf("expected ProbeError, got %v", err)
+	}
+	return probeErr.Kind
+}
+
+func TestProbeContext_Success(t *testing.T) {
+	server := newProbeServer(t)
+
+	result, err := ProbeContext(context.Background(), probeConfig(server, UserConfig{Token: "good"}, true), "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Ready || result.Version != "v1.30.1" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestProbeContext_NoReadyzEndpoint(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"gitVersion":"v1.15.0"}`))
+	})
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+
+	result, err := ProbeContext(context.Background(), probeConfig(server, UserConfig{Token: "good"}, true), "test")
+	if err != nil {
+		t.Fatalf("expected a missing /readyz not to fail the probe, got %v", err)
+	}
+	if result.Ready || !result.ReadyUnknown || result.Version != "v1.15.0" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestProbeContext_TokenFile(t *testing.T) {
+	server := newProbeServer(t)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("good\n"), 0600); err != nil {
+		t.Fatalf("failed to write token: %v", err)
+	}
+
+	if _, err := ProbeContext(context.Background(), probeConfig(server, UserConfig{TokenFile: tokenFile}, true), "test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestProbeContext_AuthError(t *testing.T) {
+	server := newProbeServer(t)
+
+	_, err := ProbeContext(context.Background(), probeConfig(server, UserConfig{Token: "bad"}, true), "test")
+	if kind := probeKind(t, err); kind != ProbeAuthError {
+		t.Errorf("expected auth error, got %s (%v)", kind, err)
+	}
+}
+
+func TestProbeContext_ExecUserSkipsAuth(t *testing.T) {
+	server := newProbeServer(t)
+	user := UserConfig{Exec: &ExecConfig{Command: "aws"}}
+
+	result, err := ProbeContext(context.Background(), probeConfig(server, user, true), "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.AuthSkipped {
+		t.Error("expected auth to be reported as skipped")
+	}
+}
+
+func TestProbeContext_TLSError(t *testing.T) {
+	server := newProbeServer(t)
+
+	_, err := ProbeContext(context.Background(), probeConfig(server, UserConfig{Token: "good"}, false), "test")
+	if kind := probeKind(t, err); kind != ProbeTLSError {
+		t.Errorf("expected tls error, got %s (%v)", kind, err)
+	}
+}
+
+func TestProbeContext_NetworkError(t *testing.T) {
+	server := newProbeServer(t)
+	config := probeConfig(server, UserConfig{Token: "good"}, true)
+	server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err := ProbeContext(ctx, config, "test")
+	if kind := probeKind(t, err); kind != ProbeNetworkError {
+		t.Errorf("expected network error, got %s (%v)", kind, err)
+	}
+}
+
+func TestProbeHTTPClient_Proxy(t *testing.T) {
+	client, err := probeHTTPClient(&ClusterConfig{Server: "https://dev:6443"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.Transport.(*http.Transport).Proxy == nil {
+		t.Error("expected the environment proxy to be used without proxy-url")
+	}
+
+	client, err = probeHTTPClient(&ClusterConfig{Server: "https://dev:6443", ProxyURL: "http://proxy:3128"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://dev:6443/readyz", nil)
+	if proxy, err := client.Transport.(*http.Transport).Proxy(req); err != nil || proxy.String() != "http://proxy:3128" {
+		t.Errorf("expected proxy-url to win, got %v (%v)", proxy, err)
+	}
+}
+
+func TestProbeContext_MissingCluster(t *testing.T) {
+	config := &Config{Contexts: []Context{{Name: "test", Context: ContextConfig{Cluster: "gone"}}}}
+
+	_, err := ProbeContext(context.Background(), config, "test")
+	if kind := probeKind(t, err); kind != ProbeConfigError {
+		t.Errorf("expected config error, got %s (%v)", kind, err)
+	}
+}
+
+func TestImportConfig_StrictValidationAbortsBeforeWrite(t *testing.T) {
+	server := newProbeServer(t)
+	path := filepath.Join(t.TempDir(), "config")
+
+	_, err := importConfig(path, probeConfig(server, UserConfig{Token: "bad"}, true), ImportOptions{
+		Validation:      ValidateStrict,
+		ValidateTimeout: time.Second,
+	})
+	if err == nil {
+		t.Fatal("expected strict validation to fail")
+	}
+	if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+		t.Error("kubeconfig must not be written when strict validation fails")
+	}
+
+	_, err = importConfig(path, probeConfig(server, UserConfig{Token: "bad"}, true), ImportOptions{
+		Validation:      ValidateWarn,
+		ValidateTimeout: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("expected warn validation to import anyway, got %v", err)
+	}
+}
+
+func TestValidateConfig_ResolvesRelativePaths(t *testing.T) {
+	server := newProbeServer(t)
+	dir := t.TempDir()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(filepath.Join(dir, "ca.crt"), caPEM, 0600); err != nil {
+		t.Fatalf("failed to write ca: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "token"), []byte("good\n"), 0600); err != nil {
+		t.Fatalf("failed to write token: %v", err)
+	}
+
+	config := probeConfig(server, UserConfig{TokenFile: "token"}, false)
+	config.Clusters[0].Cluster.CertificateAuthority = "ca.crt"
+	path := filepath.Join(dir, "config")
+	if err := Save(path, config); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	t.Setenv("KUBECONFIG", path)
+	t.Chdir(t.TempDir())
+
+	if err := ValidateConfig("test"); err != nil {
+		t.Errorf("expected the files next to the kubeconfig to be used, got %v", err)
+	}
+}
@@ -1,102 +1,185 @@
 package kubeconfig
 
 import (
-	"encoding/base64"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
-	"gopkg.in/yaml.v3"
 )
 
-// ProcessConfig decodes a base64 kubeconfig string, merges it into the existing
-// kubeconfig, and validates the imported contexts.
+// ImportOptions controls how an imported kubeconfig is merged into the local one.
+type ImportOptions struct {
+	// Name renames every cluster, context and user of the imported config.
+	Name string
+	// OnConflict decides what happens with same-named entries (default replace).
+	OnConflict ConflictStrategy
+	// DryRun prints the planned changes without writing the kubeconfig.
+	DryRun bool
+	// Validation decides whether imported contexts are probed before the
+	// kubeconfig is written (default skip).
+	Validation ValidationMode
+	// ValidateTimeout bounds each probe (default DefaultValidateTimeout).
+	ValidateTimeout time.Duration
+	// Server rewrites the server addresses of the imported clusters.
+	Server ServerRewrite
+	// Flatten inlines the certificate and key files referenced by the
+	// imported config. Only set it for local sources (a file or stdin):
+	// the paths of a remote config name files on another machine.
+	Flatten bool
+	// BaseDir resolves relative certificate and key paths of the imported
+	// config when flattening (default: the working directory).
+	BaseDir string
+}
+
+// ProcessConfig decodes a kubeconfig given as raw YAML or base64, validates
+// the imported contexts, and merges it into the existing kubeconfig.
 func ProcessConfig(k8sConfig string, name string) error {
-	k8sConfig = strings.ReplaceAll(k8sConfig, "\n", "")
-	k8sConfig = strings.ReplaceAll(k8sConfig, "\r", "")
-	k8sConfig = strings.ReplaceAll(k8sConfig, " ", "")
+	return ProcessConfigWithOptions(k8sConfig, ImportOptions{Name: name, Validation: ValidateWarn})
+}
 
-	if n := len(k8sConfig) % 4; n > 0 {
-		k8sConfig += strings.Repeat("=", 4-n)
-	}
+// ProcessConfigWithOptions is ProcessConfig with conflict handling and dry-run support.
+func ProcessConfigWithOptions(k8sConfig string, opts ImportOptions) error {
+	return ProcessConfigData([]byte(k8sConfig), opts)
+}
 
-	decodedConfig, err := base64.StdEncoding.DecodeString(k8sConfig)
+// ProcessConfigData is ProcessConfigWithOptions for file or stdin content.
+// With opts.Flatten, certificate and key files referenced by the imported
+// config are inlined; otherwise the references are kept as they are.
+func ProcessConfigData(data []byte, opts ImportOptions) error {
+	newConfig, err := DecodeConfig(data)
 	if err != nil {
-		decodedConfig, err = base64.URLEncoding.DecodeString(k8sConfig)
-		if err != nil {
-			return fmt.Errorf("failed to decode base64 config: %w", err)
+		return err
+	}
+
+	if opts.Flatten {
+		if err := Flatten(newConfig, opts.BaseDir); err != nil {
+			log.Warnf("⚠️  Keeping file references that could not be inlined: %v", err)
 		}
+	} else if refs := FileReferences(newConfig); len(refs) > 0 {
+		log.Warnf("⚠️  Keeping file references of the imported config, they are not read from this machine: %s",
+			strings.Join(refs, ", "))
 	}
 
-	var newConfig Config
-	if err := yaml.Unmarshal(decodedConfig, &newConfig); err != nil {
-		return fmt.Errorf("failed to parse kubeconfig: %w", err)
+	if opts.Name != "" {
+		renameConfigComponents(newConfig, opts.Name)
 	}
 
-	if name != "" {
-		log.Infof("🏷️  Renaming configuration components to: %s", name)
-		for i := range newConfig.Clusters {
-			newConfig.Clusters[i].Name = name
-		}
-		for i := range newConfig.Contexts {
-			newConfig.Contexts[i].Name = name
-			newConfig.Contexts[i].Context.Cluster = name
-			newConfig.Contexts[i].Context.User = name
-		}
-		for i := range newConfig.Users {
-			newConfig.Users[i].Name = name
-		}
-		if newConfig.CurrentContext != "" {
-			newConfig.CurrentContext = name
-		}
+	if err := rewriteServers(newConfig, opts.Server); err != nil {
+		return fmt.Errorf("failed to rewrite server address: %w", err)
 	}
 
 	kubeconfigPath := GetPath()
 
-	existingConfig, err := Load(kubeconfigPath)
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to load existing kubeconfig: %w", err)
+	if _, err := importConfig(kubeconfigPath, newConfig, opts); err != nil || opts.DryRun {
+		return err
 	}
+	warnLint(newConfig)
 
-	if existingConfig != nil {
-		backupPath, err := Backup(kubeconfigPath)
-		if err != nil {
-			log.Warnf("⚠️  Warning: Failed to create backup: %v", err)
-		} else {
-			log.Infof("📦 Backed up existing kubeconfig to: %s", backupPath)
-		}
+	log.Infof("💾 Kubeconfig saved successfully to: %s", kubeconfigPath)
+	log.Info("🎉 Done! Use 'stackctl kubeconfig list-contexts' to see all available contexts")
+
+	return nil
+}
+
+// importConfig merges newConfig into the kubeconfig at path according to opts.
+// The imported contexts are probed first (outside the lock, as probes can be
+// slow). On a dry run the planned changes are printed and nothing is written;
+// otherwise the existing files are backed up and the merged config saved.
+// It returns the merge report.
+func importConfig(path string, newConfig *Config, opts ImportOptions) (*MergeReport, error) {
+	if err := validateContexts(newConfig, opts.Validation, opts.ValidateTimeout); err != nil {
+		return nil, err
 	}
 
-	mergedConfig := Merge(existingConfig, &newConfig)
+	if opts.DryRun {
+		return mergeAndSave(path, newConfig, opts, nil)
+	}
 
-	if err := Save(kubeconfigPath, mergedConfig); err != nil {
-		return fmt.Errorf("failed to save kubeconfig: %w", err)
+	// Ask before taking the lock: other writers only wait so long for it.
+	var answers map[string]ConflictStrategy
+	if opts.OnConflict == ConflictPrompt {
+		var err error
+		if answers, err = askConflicts(path, newConfig); err != nil {
+			return nil, err
+		}
 	}
 
-	log.Infof("💾 Kubeconfig saved successfully to: %s", kubeconfigPath)
-	log.Info("🎉 Done! Use 'stackctl kubeconfig list-contexts' to see all available contexts")
+	var report *MergeReport
+	err := withLock(path, func() error {
+		var err error
+		report, err = mergeAndSave(path, newConfig, opts, answers)
+		return err
+	})
+	return report, err
+}
 
-	for _, ctx := range newConfig.Contexts {
-		ValidateConfig(ctx.Name)
+// askConflicts prompts for every conflict of merging newConfig into the
+// kubeconfig at path and returns the answers keyed by "<kind>/<name>".
+func askConflicts(path string, newConfig *Config) (map[string]ConflictStrategy, error) {
+	existingConfig, err := Load(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load existing kubeconfig: %w", err)
 	}
 
-	return nil
+	answers := make(map[string]ConflictStrategy)
+	prompt := newConflictPrompt()
+	_, _, err = MergeWithOptions(existingConfig, newConfig, MergeOptions{
+		OnConflict: ConflictPrompt,
+		Prompt: func(c Change) (ConflictStrategy, error) {
+			strategy, err := prompt(c)
+			answers[c.Kind+"/"+c.Name] = strategy
+			return strategy, err
+		},
+	})
+	return answers, err
 }
 
-// ValidateConfig checks cluster connectivity for the given context name.
-func ValidateConfig(contextName string) {
-	log.Infof("🔍 Validating cluster connection for context: %s...", contextName)
-	for i := 1; i <= 10; i++ {
-		cmd := exec.Command("kubectl", "cluster-info", "--context", contextName)
-		if err := cmd.Run(); err == nil {
-			log.Info("✅ Connection validated successfully!")
-			return
+// mergeAndSave implements importConfig; callers hold the kubeconfig lock
+// unless it is a dry run. Prompted conflicts are resolved with answers.
+func mergeAndSave(path string, newConfig *Config, opts ImportOptions, answers map[string]ConflictStrategy) (*MergeReport, error) {
+	existingConfig, err := Load(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load existing kubeconfig: %w", err)
+	}
+
+	mergeOpts := MergeOptions{OnConflict: opts.OnConflict}
+	if answers != nil {
+		mergeOpts.Prompt = func(c Change) (ConflictStrategy, error) {
+			if strategy, ok := answers[c.Kind+"/"+c.Name]; ok {
+				return strategy, nil
+			}
+			return "", fmt.Errorf("kubeconfig changed while waiting for input, run the import again")
 		}
-		log.Warnf("⚠️  Connection attempt %d/10 failed. Retrying in 1s...", i)
-		time.Sleep(1 * time.Second)
 	}
-	log.Error("❌ Failed to validate cluster connection after 10 attempts.")
+
+	mergedConfig, report, err := MergeWithOptions(existingConfig, newConfig, mergeOpts)
+
+	if opts.DryRun && report != nil {
+		fmt.Printf("🔍 Dry run: planned changes for %s\n", path)
+		report.Print(os.Stdout)
+		return report, err
+	}
+	if err != nil {
+		return report, err
+	}
+
+	logMergeReport(report)
+
+	if existingConfig != nil {
+		backupPaths, err := BackupFiles(path)
+		if err != nil {
+			log.Warnf("⚠️  Warning: Failed to create backup: %v", err)
+		}
+		for _, backupPath := range backupPaths {
+			log.Infof("📦 Backed up existing kubeconfig to: %s", backupPath)
+		}
+	}
+
+	if err := Save(path, mergedConfig); err != nil {
+		return report, fmt.Errorf("failed to save kubeconfig: %w", err)
+	}
+
+	return report, nil
 }
@@ -0,0 +1,248 @@
+package kubeconfig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultPruneTimeout bounds a single cluster probe of prune --unreachable.
+const DefaultPruneTimeout = 5 * time.Second
+
+// pruneConcurrency is the number of clusters probed at the same time.
+const pruneConcurrency = 8
+
+// PruneOptions selects what Prune removes. Without Orphans, Unreachable or
+// ExpiredCerts, orphans are pruned.
+type PruneOptions struct {
+	// Orphans removes clusters and users no context references.
+	Orphans bool
+	// Unreachable removes contexts whose cluster does not answer within
+	// Timeout. Clusters answering with an error are kept.
+	Unreachable bool
+	Timeout     time.Duration
+	// ExpiredCerts removes contexts whose CA or client certificate expired.
+	ExpiredCerts bool
+	// DryRun reports without writing the kubeconfig.
+	DryRun bool
+}
+
+// PruneEntry is an entry removed by Prune.
+type PruneEntry struct {
+	Kind   string // cluster, context or user
+	Name   string
+	Reason string
+}
+
+// PruneReport lists the entries Prune removed or would remove.
+type PruneReport struct {
+	Entries []PruneEntry
+	DryRun  bool
+}
+
+// Print writes the report.
+func (r *PruneReport) Print(w io.Writer) {
+	if len(r.Entries) == 0 {
+		_, _ = fmt.Fprintln(w, "✅ Nothing to prune")
+		return
+	}
+	verb := "Removed"
+	if r.DryRun {
+		verb = "Would remove"
+	}
+	for _, e := range r.Entries {
+		_, _ = fmt.Fprintf(w, "  - %-8s %s (%s)\n", e.Kind, e.Name, e.Reason)
+	}
+	_, _ = fmt.Fprintf(w, "\n🧹 %s %d entries\n", verb, len(r.Entries))
+}
+
+// Prune removes orphaned clusters and users, unreachable contexts and
+// contexts with expired certificates from the kubeconfig at path, taking a
+// backup first. Clusters and users only used by removed contexts are removed
+// with them.
+func Prune(path string, opts PruneOptions) (*PruneReport, error) {
+	if !opts.Orphans && !opts.Unreachable && !opts.ExpiredCerts {
+		opts.Orphans = true
+	}
+
+	config, sources, err := loadWithFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	resolvePaths(config, sources.dir)
+
+	// Probing is slow, so candidates are found before taking the lock.
+	candidates := make(map[string]string)
+	if opts.ExpiredCerts {
+		for name, reason := range expiredContexts(config, time.Now()) {
+			candidates[name] = reason
+		}
+	}
+	if opts.Unreachable {
+		for name, reason := range unreachableContexts(config, opts.Timeout) {
+			if _, ok := candidates[name]; !ok {
+				candidates[name] = reason
+			}
+		}
+	}
+
+	report := &PruneReport{DryRun: opts.DryRun}
+	if opts.DryRun {
+		report.Entries = pruneConfig(config, candidates, opts.Orphans)
+		return report, nil
+	}
+
+	err = withLock(path, func() error {
+		config, err := Load(path)
+		if err != nil {
+			return fmt.Errorf("failed to load kubeconfig: %w", err)
+		}
+		entries := pruneConfig(config, candidates, opts.Orphans)
+		if len(entries) == 0 {
+			return nil
+		}
+
+		backupPaths, err := BackupFiles(path)
+		if err != nil {
+			return fmt.Errorf("failed to create backup: %w", err)
+		}
+		for _, backupPath := range backupPaths {
+			log.Infof("📦 Created backup: %s", backupPath)
+		}
+
+		if err := Save(path, config); err != nil {
+			return fmt.Errorf("failed to save kubeconfig: %w", err)
+		}
+		report.Entries = entries
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// pruneConfig removes the contexts in candidates (name → reason) from config,
+// then the clusters and users they leave unreferenced, or every unreferenced
+// one when orphans is set. It returns the removed entries.
+func pruneConfig(config *Config, candidates map[string]string, orphans bool) []PruneEntry {
+	var entries []PruneEntry
+
+	released := make(map[string]bool) // "cluster/<name>" and "user/<name>"
+	var contexts []Context
+	for _, ctx := range config.Contexts {
+		reason, ok := candidates[ctx.Name]
+		if !ok {
+			contexts = append(contexts, ctx)
+			continue
+		}
+		entries = append(entries, PruneEntry{Kind: "context", Name: ctx.Name, Reason: reason})
+		released["cluster/"+ctx.Context.Cluster] = true
+		released["user/"+ctx.Context.User] = true
+	}
+	config.Contexts = contexts
+
+	if _, ok := candidates[config.CurrentContext]; ok {
+		config.CurrentContext = ""
+		if len(config.Contexts) > 0 {
+			config.CurrentContext = config.Contexts[0].Name
+		}
+	}
+
+	usedClusters := make(map[string]bool)
+	usedUsers := make(map[string]bool)
+	for _, ctx := range config.Contexts {
+		usedClusters[ctx.Context.Cluster] = true
+		usedUsers[ctx.Context.User] = true
+	}
+
+	var clusters []Cluster
+	for _, c := range config.Clusters {
+		switch {
+		case usedClusters[c.Name]:
+		case released["cluster/"+c.Name]:
+			entries = append(entries, PruneEntry{Kind: "cluster", Name: c.Name, Reason: "only used by pruned contexts"})
+			continue
+		case orphans:
+			entries = append(entries, PruneEntry{Kind: "cluster", Name: c.Name, Reason: "orphaned"})
+			continue
+		}
+		clusters = append(clusters, c)
+	}
+	config.Clusters = clusters
+
+	var users []User
+	for _, u := range config.Users {
+		switch {
+		case usedUsers[u.Name]:
+		case released["user/"+u.Name]:
+			entries = append(entries, PruneEntry{Kind: "user", Name: u.Name, Reason: "only used by pruned contexts"})
+			continue
+		case orphans:
+			entries = append(entries, PruneEntry{Kind: "user", Name: u.Name, Reason: "orphaned"})
+			continue
+		}
+		users = append(users, u)
+	}
+	config.Users = users
+
+	return entries
+}
+
+// expiredContexts returns the contexts whose CA or client certificate has
+// expired, with the reason.
+func expiredContexts(config *Config, now time.Time) map[string]string {
+	out := make(map[string]string)
+	for _, ctx := range config.Contexts {
+		report, err := InspectContext(config, ctx.Name)
+		if err != nil {
+			continue
+		}
+		for _, cert := range append(append([]CertificateInfo{}, report.CA...), report.ClientCert...) {
+			if cert.NotAfter.Before(now) {
+				out[ctx.Name] = fmt.Sprintf("certificate '%s' expired %s", cert.Subject, cert.NotAfter.Format("2006-01-02"))
+				break
+			}
+		}
+	}
+	return out
+}
+
+// unreachableContexts probes every context concurrently and returns the ones
+// whose cluster does not answer, with the reason.
+func unreachableContexts(config *Config, timeout time.Duration) map[string]string {
+	if timeout <= 0 {
+		timeout = DefaultPruneTimeout
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		out = make(map[string]string)
+		sem = make(chan struct{}, pruneConcurrency)
+	)
+	for _, ctx := range config.Contexts {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			log.Debugf("🔍 Probing context %s...", name)
+			_, err := probeWithTimeout(config, name, timeout)
+			var probeErr *ProbeError
+			if err == nil || !errors.As(err, &probeErr) || probeErr.Kind != ProbeNetworkError {
+				return
+			}
+			mu.Lock()
+			out[name] = fmt.Sprintf("unreachable: %v", probeErr.Err)
+			mu.Unlock()
+		}(ctx.Name)
+	}
+	wg.Wait()
+	return out
+}
@@ -0,0 +1,103 @@
+package kubeconfig
+
+import (
+	"encoding/base64"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// pruneFixture saves a kubeconfig with a live context served by server, a
+// context whose server is closed, a context with an expired client
+// certificate and an orphaned cluster and user.
+func pruneFixture(t *testing.T) string {
+	t.Helper()
+	live := newProbeServer(t)
+	dead := newProbeServer(t)
+	dead.Close()
+
+	config := probeConfig(live, UserConfig{Token: "good"}, true)
+	config.APIVersion, config.Kind, config.CurrentContext = "v1", "Config", "dead"
+	config.Clusters = append(config.Clusters,
+		Cluster{Name: "dead", Cluster: ClusterConfig{Server: dead.URL}},
+		Cluster{Name: "expired", Cluster: ClusterConfig{Server: "https://expired:6443"}},
+		Cluster{Name: "orphan", Cluster: ClusterConfig{Server: "https://orphan:6443"}},
+	)
+	config.Users = append(config.Users,
+		User{Name: "dead", User: UserConfig{Token: "t"}},
+		User{Name: "expired", User: UserConfig{
+			ClientCertificateData: base64.StdEncoding.EncodeToString(newTestCert(t, "admin", -time.Hour)),
+		}},
+		User{Name: "orphan", User: UserConfig{Token: "t"}},
+	)
+	config.Contexts = append(config.Contexts,
+		Context{Name: "dead", Context: ContextConfig{Cluster: "dead", User: "dead"}},
+		Context{Name: "expired", Context: ContextConfig{Cluster: "expired", User: "expired"}},
+	)
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := Save(path, config); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	return path
+}
+
+func prunedNames(report *PruneReport) map[string]bool {
+	names := make(map[string]bool)
+	for _, e := range report.Entries {
+		names[e.Kind+"/"+e.Name] = true
+	}
+	return names
+}
+
+func TestPrune_DefaultsToOrphans(t *testing.T) {
+	path := pruneFixture(t)
+	t.Setenv(BackupDirEnvVar, t.TempDir())
+
+	report, err := Prune(path, PruneOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	names := prunedNames(report)
+	if len(names) != 2 || !names["cluster/orphan"] || !names["user/orphan"] {
+		t.Errorf("expected only the orphans to be pruned, got %+v", report.Entries)
+	}
+
+	backups, err := ListBackups(path, BackupPolicyFromEnv())
+	if err != nil || len(backups) != 1 {
+		t.Errorf("expected a backup before pruning, got %v (%v)", backups, err)
+	}
+}
+
+func TestPrune_UnreachableAndExpired(t *testing.T) {
+	path := pruneFixture(t)
+	t.Setenv(BackupDirEnvVar, t.TempDir())
+
+	report, err := Prune(path, PruneOptions{Unreachable: true, ExpiredCerts: true, Timeout: 2 * time.Second, DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	names := prunedNames(report)
+	for _, expected := range []string{"context/dead", "cluster/dead", "user/dead", "context/expired", "cluster/expired", "user/expired"} {
+		if !names[expected] {
+			t.Errorf("expected %s to be pruned, got %+v", expected, report.Entries)
+		}
+	}
+	if names["context/test"] || names["cluster/orphan"] {
+		t.Errorf("expected the live context and the orphans to be kept, got %+v", report.Entries)
+	}
+	if names, _ := GetContextNames(path); len(names) != 3 {
+		t.Errorf("expected a dry run not to write the kubeconfig, got %v", names)
+	}
+
+	if _, err := Prune(path, PruneOptions{Unreachable: true, ExpiredCerts: true, Timeout: 2 * time.Second}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if len(config.Contexts) != 1 || config.CurrentContext != "test" {
+		t.Errorf("expected only the live context to remain as current, got %+v", config)
+	}
+}
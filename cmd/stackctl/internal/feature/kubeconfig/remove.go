@@ -8,6 +8,12 @@ import (
 
 // RemoveConfig removes a context and its associated data if they are not shared
 func RemoveConfig(path, contextName string) error {
+	return withLock(path, func() error {
+		return removeConfig(path, contextName)
+	})
+}
+
+func removeConfig(path, contextName string) error {
 	config, err := Load(path)
 	if err != nil {
 		return fmt.Errorf("failed to load kubeconfig: %w", err)
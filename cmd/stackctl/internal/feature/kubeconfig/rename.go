@@ -0,0 +1,193 @@
+package kubeconfig
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RenameOptions selects which entries referenced by the context are renamed
+// along with it.
+type RenameOptions struct {
+	// Cluster renames the context's cluster to the new name.
+	Cluster bool
+	// User renames the context's user to the new name.
+	User bool
+}
+
+// renamePlan holds the old and new names of the entries being renamed. An
+// empty old name means the entry is not renamed.
+type renamePlan struct {
+	oldContext, newContext string
+	oldCluster, newCluster string
+	oldUser, newUser       string
+}
+
+// RenameContext renames a context and, optionally, the cluster and user it
+// references. Every context referencing a renamed cluster or user is updated
+// and current-context follows the rename. Existing names are never
+// overwritten.
+func RenameContext(path, oldName, newName string, opts RenameOptions) error {
+	return withLock(path, func() error {
+		return renameContext(path, oldName, newName, opts)
+	})
+}
+
+func renameContext(path, oldName, newName string, opts RenameOptions) error {
+	if err := ValidateContextName(newName); err != nil {
+		return err
+	}
+
+	config, err := Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	plan, err := planRename(config, oldName, newName, opts)
+	if err != nil {
+		return err
+	}
+
+	// Rename in every file of the list so each entry stays in the file that
+	// owns it.
+	files, err := loadFiles(SplitPaths(path))
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	backupPaths, err := BackupFiles(path)
+	if err != nil {
+		log.Warnf("⚠️  Warning: Failed to create backup: %v", err)
+	}
+	for _, backupPath := range backupPaths {
+		log.Infof("📦 Backed up existing kubeconfig to: %s", backupPath)
+	}
+	for _, f := range files {
+		if f.config == nil {
+			continue
+		}
+		updated := *f.config
+		plan.apply(&updated)
+		if sameContent(f.config, &updated) {
+			continue
+		}
+		if err := saveFile(f.path, &updated); err != nil {
+			return fmt.Errorf("failed to save kubeconfig: %w", err)
+		}
+	}
+
+	log.Infof("✅ Renamed context '%s' to '%s'", oldName, newName)
+	if plan.oldCluster != "" {
+		log.Infof("✅ Renamed cluster '%s' to '%s'", plan.oldCluster, plan.newCluster)
+	}
+	if plan.oldUser != "" {
+		log.Infof("✅ Renamed user '%s' to '%s'", plan.oldUser, plan.newUser)
+	}
+	return nil
+}
+
+// planRename checks the rename against the merged config and refuses
+// collisions with existing entries.
+func planRename(config *Config, oldName, newName string, opts RenameOptions) (*renamePlan, error) {
+	var target *Context
+	for i := range config.Contexts {
+		if config.Contexts[i].Name == oldName {
+			target = &config.Contexts[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("context '%s' not found", oldName)
+	}
+
+	plan := &renamePlan{}
+	if newName != oldName {
+		if hasEntry(config.Contexts, newName) {
+			return nil, fmt.Errorf("context '%s' already exists", newName)
+		}
+		plan.oldContext, plan.newContext = oldName, newName
+	}
+
+	if opts.Cluster && target.Context.Cluster != newName {
+		if !hasEntry(config.Clusters, target.Context.Cluster) {
+			return nil, fmt.Errorf("cluster '%s' referenced by context '%s' not found", target.Context.Cluster, oldName)
+		}
+		if hasEntry(config.Clusters, newName) {
+			return nil, fmt.Errorf("cluster '%s' already exists", newName)
+		}
+		plan.oldCluster, plan.newCluster = target.Context.Cluster, newName
+		warnShared(config, "cluster", plan.oldCluster, oldName, func(c ContextConfig) string { return c.Cluster })
+	}
+
+	if opts.User && target.Context.User != newName {
+		if !hasEntry(config.Users, target.Context.User) {
+			return nil, fmt.Errorf("user '%s' referenced by context '%s' not found", target.Context.User, oldName)
+		}
+		if hasEntry(config.Users, newName) {
+			return nil, fmt.Errorf("user '%s' already exists", newName)
+		}
+		plan.oldUser, plan.newUser = target.Context.User, newName
+		warnShared(config, "user", plan.oldUser, oldName, func(c ContextConfig) string { return c.User })
+	}
+
+	if plan.oldContext == "" && plan.oldCluster == "" && plan.oldUser == "" {
+		return nil, fmt.Errorf("nothing to rename: '%s' already uses that name", oldName)
+	}
+	return plan, nil
+}
+
+// apply renames the planned entries and their references in config.
+func (p *renamePlan) apply(config *Config) {
+	config.Contexts = append([]Context(nil), config.Contexts...)
+	for i := range config.Contexts {
+		ctx := &config.Contexts[i]
+		if p.oldContext != "" && ctx.Name == p.oldContext {
+			ctx.Name = p.newContext
+		}
+		if p.oldCluster != "" && ctx.Context.Cluster == p.oldCluster {
+			ctx.Context.Cluster = p.newCluster
+		}
+		if p.oldUser != "" && ctx.Context.User == p.oldUser {
+			ctx.Context.User = p.newUser
+		}
+	}
+
+	if p.oldCluster != "" {
+		config.Clusters = append([]Cluster(nil), config.Clusters...)
+		for i := range config.Clusters {
+			if config.Clusters[i].Name == p.oldCluster {
+				config.Clusters[i].Name = p.newCluster
+			}
+		}
+	}
+	if p.oldUser != "" {
+		config.Users = append([]User(nil), config.Users...)
+		for i := range config.Users {
+			if config.Users[i].Name == p.oldUser {
+				config.Users[i].Name = p.newUser
+			}
+		}
+	}
+
+	if p.oldContext != "" && config.CurrentContext == p.oldContext {
+		config.CurrentContext = p.newContext
+	}
+}
+
+func hasEntry[T any](items []T, name string) bool {
+	for _, item := range items {
+		if entryName(item) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// warnShared logs the other contexts whose reference follows a cluster or
+// user rename.
+func warnShared(config *Config, kind, name, contextName string, ref func(ContextConfig) string) {
+	for _, ctx := range config.Contexts {
+		if ctx.Name != contextName && ref(ctx.Context) == name {
+			log.Warnf("⚠️  Context '%s' also uses %s '%s'; its reference is updated too", ctx.Name, kind, name)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package kubeconfig
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func renameFixture(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	config := &Config{
+		APIVersion:     "v1",
+		Kind:           "Config",
+		CurrentContext: "ctx-a",
+		Clusters:       []Cluster{{Name: "cluster-a"}, {Name: "cluster-b"}},
+		Contexts: []Context{
+			{Name: "ctx-a", Context: ContextConfig{Cluster: "cluster-a", User: "user-a"}},
+			{Name: "ctx-b", Context: ContextConfig{Cluster: "cluster-b", User: "user-b"}},
+			{Name: "ctx-shared", Context: ContextConfig{Cluster: "cluster-a", User: "user-b"}},
+		},
+		Users: []User{{Name: "user-a"}, {Name: "user-b"}},
+	}
+	if err := Save(path, config); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	return path
+}
+
+func TestRenameContext_CascadesToClusterAndUser(t *testing.T) {
+	path := renameFixture(t)
+
+	if err := RenameContext(path, "ctx-a", "prod", RenameOptions{Cluster: true, User: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	config, _ := Load(path)
+	if config.CurrentContext != "prod" {
+		t.Errorf("expected current-context to follow the rename, got %s", config.CurrentContext)
+	}
+	if config.Contexts[0].Name != "prod" || config.Contexts[0].Context.Cluster != "prod" || config.Contexts[0].Context.User != "prod" {
+		t.Errorf("unexpected renamed context: %+v", config.Contexts[0])
+	}
+	if config.Clusters[0].Name != "prod" || config.Users[0].Name != "prod" {
+		t.Errorf("expected cluster and user to be renamed, got %s / %s", config.Clusters[0].Name, config.Users[0].Name)
+	}
+	if config.Contexts[2].Context.Cluster != "prod" {
+		t.Errorf("expected shared cluster reference to be updated, got %s", config.Contexts[2].Context.Cluster)
+	}
+}
+
+func TestRenameContext_OnlyContextByDefault(t *testing.T) {
+	path := renameFixture(t)
+
+	if err := RenameContext(path, "ctx-b", "staging", RenameOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	config, _ := Load(path)
+	if config.CurrentContext != "ctx-a" {
+		t.Errorf("current-context must not change, got %s", config.CurrentContext)
+	}
+	if config.Contexts[1].Name != "staging" || config.Contexts[1].Context.Cluster != "cluster-b" {
+		t.Errorf("unexpected renamed context: %+v", config.Contexts[1])
+	}
+}
+
+func TestRenameContext_RefusesCollisions(t *testing.T) {
+	path := renameFixture(t)
+
+	tests := []struct {
+		name    string
+		oldName string
+		newName string
+		opts    RenameOptions
+		want    string
+	}{
+		{"context", "ctx-a", "ctx-b", RenameOptions{}, "context 'ctx-b' already exists"},
+		{"cluster", "ctx-a", "cluster-b", RenameOptions{Cluster: true}, "cluster 'cluster-b' already exists"},
+		{"user", "ctx-a", "user-b", RenameOptions{User: true}, "user 'user-b' already exists"},
+		{"missing", "gone", "x", RenameOptions{}, "context 'gone' not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RenameContext(path, tt.oldName, tt.newName, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	config, _ := Load(path)
+	if config.Contexts[0].Name != "ctx-a" {
+		t.Error("kubeconfig must be unchanged after a refused rename")
+	}
+}
+
+func TestRenameContext_KeepsEntriesInOwningFile(t *testing.T) {
+	paths, list := writeKubeconfigList(t, primaryFile, secondaryFile)
+
+	if err := RenameContext(list, "prod", "production", RenameOptions{Cluster: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	primary, _ := loadFile(paths[0])
+	secondary, _ := loadFile(paths[1])
+	if hasEntry(primary.Contexts, "production") {
+		t.Error("renamed context must not move to the first file")
+	}
+	if !hasEntry(secondary.Contexts, "production") || !hasEntry(secondary.Clusters, "production") {
+		t.Errorf("expected renamed entries in the owning file, got %+v", secondary)
+	}
+	if secondary.Contexts[0].Context.Cluster != "production" {
+		t.Errorf("expected the cluster reference to be updated, got %s", secondary.Contexts[0].Context.Cluster)
+	}
+}
@@ -0,0 +1,79 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Selector filters stored kubeconfigs by their tags, like a Kubernetes label
+// selector: "env=prod,owner!=qa,team" requires every term to match.
+type Selector []selectorTerm
+
+type selectorTerm struct {
+	key    string
+	value  string
+	negate bool
+	// exists matches on the presence of the key only.
+	exists bool
+}
+
+// ParseSelector parses a comma-separated list of key=value, key!=value and
+// key terms. An empty string selects everything.
+func ParseSelector(value string) (Selector, error) {
+	var selector Selector
+	for _, raw := range strings.Split(value, ",") {
+		term := strings.TrimSpace(raw)
+		if term == "" {
+			continue
+		}
+
+		var t selectorTerm
+		switch {
+		case strings.Contains(term, "!="):
+			t.key, t.value, _ = strings.Cut(term, "!=")
+			t.negate = true
+		case strings.Contains(term, "=="):
+			t.key, t.value, _ = strings.Cut(term, "==")
+		case strings.Contains(term, "="):
+			t.key, t.value, _ = strings.Cut(term, "=")
+		default:
+			t.key, t.exists = term, true
+		}
+		t.key, t.value = strings.TrimSpace(t.key), strings.TrimSpace(t.value)
+		if t.key == "" {
+			return nil, fmt.Errorf("invalid selector term %q", term)
+		}
+		selector = append(selector, t)
+	}
+	return selector, nil
+}
+
+// Matches reports whether tags satisfy every term of the selector.
+func (s Selector) Matches(tags map[string]string) bool {
+	for _, t := range s {
+		value, ok := tags[t.key]
+		switch {
+		case t.exists && !ok:
+			return false
+		case t.negate && ok && value == t.value:
+			return false
+		case !t.exists && !t.negate && (!ok || value != t.value):
+			return false
+		}
+	}
+	return true
+}
+
+// FilterRemotes returns the remotes whose tags match the selector.
+func FilterRemotes(remotes []RemoteKubeconfig, selector Selector) []RemoteKubeconfig {
+	if len(selector) == 0 {
+		return remotes
+	}
+	var matched []RemoteKubeconfig
+	for _, r := range remotes {
+		if selector.Matches(r.Tags) {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
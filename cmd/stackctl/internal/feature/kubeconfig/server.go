@@ -0,0 +1,98 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ServerRewrite adjusts the cluster server addresses of an imported config.
+// Kubeconfigs generated on a node (k3s, kubeadm, ...) point at the loopback
+// address, which only works on the node itself.
+type ServerRewrite struct {
+	// Host replaces loopback and unspecified server hosts, keeping the port.
+	Host string
+	// Server replaces every server URL. A missing scheme defaults to https.
+	Server string
+	// Port overrides the port of every server URL.
+	Port int
+	// TLSServerName is set on every cluster so the certificate, issued for
+	// the original name, still validates against the rewritten address.
+	TLSServerName string
+}
+
+// IsZero reports whether the rewrite changes nothing.
+func (r ServerRewrite) IsZero() bool {
+	return r == ServerRewrite{}
+}
+
+// rewriteServers applies r to every cluster of config.
+func rewriteServers(config *Config, r ServerRewrite) error {
+	if r.IsZero() {
+		return nil
+	}
+
+	for i := range config.Clusters {
+		cluster := &config.Clusters[i]
+		server, err := rewriteServer(cluster.Cluster.Server, r)
+		if err != nil {
+			return fmt.Errorf("cluster '%s': %w", cluster.Name, err)
+		}
+		if server != cluster.Cluster.Server {
+			log.Infof("🔁 Rewrote server of cluster '%s': %s -> %s", cluster.Name, cluster.Cluster.Server, server)
+			cluster.Cluster.Server = server
+		}
+		if r.TLSServerName != "" {
+			cluster.Cluster.TLSServerName = r.TLSServerName
+		}
+	}
+	return nil
+}
+
+func rewriteServer(server string, r ServerRewrite) (string, error) {
+	if r.Server != "" {
+		server = r.Server
+		if !strings.Contains(server, "://") {
+			server = "https://" + server
+		}
+	}
+	if server == "" {
+		return server, nil
+	}
+
+	u, err := url.Parse(server)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL '%s': %w", server, err)
+	}
+
+	host, port := u.Hostname(), u.Port()
+	if r.Server == "" && r.Host != "" && isLoopbackHost(host) {
+		host = r.Host
+	}
+	if r.Port > 0 {
+		port = strconv.Itoa(r.Port)
+	}
+
+	if port != "" {
+		u.Host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		u.Host = "[" + host + "]"
+	} else {
+		u.Host = host
+	}
+	return u.String(), nil
+}
+
+// isLoopbackHost reports whether host only resolves on the machine that
+// generated the kubeconfig.
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
@@ -0,0 +1,46 @@
+package kubeconfig
+
+import "testing"
+
+func TestRewriteServer(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  string
+		rewrite ServerRewrite
+		want    string
+	}{
+		{"k3s loopback", "https://127.0.0.1:6443", ServerRewrite{Host: "1.2.3.4"}, "https://1.2.3.4:6443"},
+		{"localhost", "https://localhost:6443", ServerRewrite{Host: "k3s.example.com"}, "https://k3s.example.com:6443"},
+		{"ipv6 loopback", "https://[::1]:6443", ServerRewrite{Host: "fd00::10"}, "https://[fd00::10]:6443"},
+		{"unspecified", "https://0.0.0.0:6443", ServerRewrite{Host: "1.2.3.4"}, "https://1.2.3.4:6443"},
+		{"remote host kept", "https://10.0.0.5:6443", ServerRewrite{Host: "1.2.3.4"}, "https://10.0.0.5:6443"},
+		{"port override", "https://127.0.0.1:6443", ServerRewrite{Host: "1.2.3.4", Port: 16443}, "https://1.2.3.4:16443"},
+		{"server override", "https://127.0.0.1:6443", ServerRewrite{Host: "1.2.3.4", Server: "api.example.com:443"}, "https://api.example.com:443"},
+		{"server and port", "https://127.0.0.1:6443", ServerRewrite{Server: "https://api.example.com", Port: 8443}, "https://api.example.com:8443"},
+		{"no rewrite", "https://127.0.0.1:6443", ServerRewrite{}, "https://127.0.0.1:6443"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rewriteServer(tt.server, tt.rewrite)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRewriteServers_SetsTLSServerName(t *testing.T) {
+	config := &Config{Clusters: []Cluster{{Name: "default", Cluster: ClusterConfig{Server: "https://127.0.0.1:6443"}}}}
+
+	if err := rewriteServers(config, ServerRewrite{Host: "k3s.example.com", TLSServerName: "127.0.0.1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cluster := config.Clusters[0].Cluster
+	if cluster.Server != "https://k3s.example.com:6443" || cluster.TLSServerName != "127.0.0.1" {
+		t.Errorf("unexpected cluster: %+v", cluster)
+	}
+}
@@ -0,0 +1,324 @@
+package kubeconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// SyncDirection decides how Sync reconciles the local kubeconfig with Vault.
+type SyncDirection string
+
+const (
+	// SyncReportOnly only reports the drift.
+	SyncReportOnly SyncDirection = ""
+	// SyncPull imports remote-only secrets and overwrites diverged local contexts.
+	SyncPull SyncDirection = "pull"
+	// SyncPush uploads local-only contexts and overwrites diverged secrets.
+	SyncPush SyncDirection = "push"
+	// SyncBoth copies missing entries both ways and leaves diverged ones alone.
+	SyncBoth SyncDirection = "both"
+)
+
+// SyncOptions controls Sync.
+type SyncOptions struct {
+	Direction SyncDirection
+	// Selector limits the sync to the secrets whose tags match; local-only
+	// contexts are then left out, as they carry no tags.
+	Selector Selector
+}
+
+// SyncStatus classifies a context by comparing its local and remote content.
+type SyncStatus string
+
+const (
+	SyncLocalOnly  SyncStatus = "local-only"
+	SyncRemoteOnly SyncStatus = "remote-only"
+	SyncIdentical  SyncStatus = "identical"
+	SyncDiverged   SyncStatus = "diverged"
+)
+
+// SyncAction is what Sync did with an entry.
+type SyncAction string
+
+const (
+	SyncNoAction SyncAction = ""
+	SyncPushed   SyncAction = "pushed"
+	SyncPulled   SyncAction = "pulled"
+	SyncFailed   SyncAction = "failed"
+)
+
+// SyncEntry is a single context of a SyncReport. Local contexts are matched
+// with the secret of the same name, as written by save-to-vault.
+type SyncEntry struct {
+	Name       string
+	Status     SyncStatus
+	LocalHash  string
+	RemoteHash string
+	Action     SyncAction
+	Err        error
+
+	local  *Config
+	remote *Config
+}
+
+// SyncReport lists every context compared by Sync.
+type SyncReport struct {
+	Direction SyncDirection
+	Entries   []SyncEntry
+}
+
+// Count returns the number of entries with the given status.
+func (r *SyncReport) Count(status SyncStatus) int {
+	n := 0
+	for _, e := range r.Entries {
+		if e.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// CountAction returns the number of entries with the given action.
+func (r *SyncReport) CountAction(action SyncAction) int {
+	n := 0
+	for _, e := range r.Entries {
+		if e.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Print writes the report and a summary line.
+func (r *SyncReport) Print(w io.Writer) {
+	if len(r.Entries) == 0 {
+		_, _ = fmt.Fprintln(w, "  (no contexts locally or in Vault)")
+	}
+	for _, e := range r.Entries {
+		marker := " "
+		switch e.Status {
+		case SyncLocalOnly:
+			marker = "<"
+		case SyncRemoteOnly:
+			marker = ">"
+		case SyncDiverged:
+			marker = "!"
+		}
+		line := fmt.Sprintf("  %s %-12s %s", marker, e.Status, e.Name)
+		switch e.Action {
+		case SyncPushed:
+			line += " (pushed to Vault)"
+		case SyncPulled:
+			line += " (pulled from Vault)"
+		case SyncFailed:
+			line += fmt.Sprintf(" (failed: %v)", e.Err)
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
+
+	_, _ = fmt.Fprintf(w, "\n%d local-only, %d remote-only, %d identical, %d diverged; %d pushed, %d pulled, %d failed\n",
+		r.Count(SyncLocalOnly), r.Count(SyncRemoteOnly), r.Count(SyncIdentical), r.Count(SyncDiverged),
+		r.CountAction(SyncPushed), r.CountAction(SyncPulled), r.CountAction(SyncFailed))
+
+	if unresolved := r.unresolved(); unresolved > 0 && r.Direction != SyncReportOnly {
+		_, _ = fmt.Fprintf(w, "⚠️  %d diverged context(s) left untouched; use --pull or --push to pick a side\n", unresolved)
+	}
+}
+
+// unresolved counts diverged entries that were neither pushed nor pulled.
+func (r *SyncReport) unresolved() int {
+	n := 0
+	for _, e := range r.Entries {
+		if e.Status == SyncDiverged && e.Action == SyncNoAction {
+			n++
+		}
+	}
+	return n
+}
+
+// Sync compares every local context with the kubeconfig secrets in Vault and
+// reconciles them according to opts.Direction. Contents are compared by a hash of
+// the single-context config with its cluster, context and user named after
+// the secret, so names given by add-from-vault do not count as drift.
+// Secrets holding more than one context are skipped.
+func (s *VaultKubeconfigService) Sync(kubeconfigPath string, opts SyncOptions) (*SyncReport, error) {
+	direction := opts.Direction
+	switch direction {
+	case SyncReportOnly, SyncPull, SyncPush, SyncBoth:
+	default:
+		return nil, fmt.Errorf("invalid sync direction %q", direction)
+	}
+
+	entries, err := s.compare(kubeconfigPath, opts.Selector)
+	if err != nil {
+		return nil, err
+	}
+	report := &SyncReport{Direction: direction}
+
+	var pulls []*SyncEntry
+	for i := range entries {
+		e := &entries[i]
+		switch {
+		case e.Status == SyncLocalOnly && (direction == SyncPush || direction == SyncBoth),
+			e.Status == SyncDiverged && direction == SyncPush:
+			s.push(e)
+		case e.Status == SyncRemoteOnly && (direction == SyncPull || direction == SyncBoth),
+			e.Status == SyncDiverged && direction == SyncPull:
+			pulls = append(pulls, e)
+		}
+	}
+
+	if len(pulls) > 0 {
+		s.pull(kubeconfigPath, pulls)
+	}
+
+	report.Entries = entries
+	return report, nil
+}
+
+// compare loads both sides and classifies every context, sorted by name.
+func (s *VaultKubeconfigService) compare(kubeconfigPath string, selector Selector) ([]SyncEntry, error) {
+	local, err := Load(kubeconfigPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	byName := make(map[string]*SyncEntry)
+	if local != nil {
+		for _, ctx := range local.Contexts {
+			single, err := extractContext(local, ctx.Name)
+			if err != nil {
+				log.Warnf("⚠️  Skipping local context '%s': %v", ctx.Name, err)
+				continue
+			}
+			hash, err := syncHash(single, ctx.Name)
+			if err != nil {
+				return nil, err
+			}
+			byName[ctx.Name] = &SyncEntry{Name: ctx.Name, Status: SyncLocalOnly, LocalHash: hash, local: single}
+		}
+	}
+
+	remotes, err := s.ListRemoteKubeconfigs()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range FilterRemotes(remotes, selector) {
+		if len(r.ContextNames) != 1 {
+			log.Warnf("⚠️  Skipping secret '%s': sync handles secrets holding a single context (found %d)", r.SecretName, len(r.ContextNames))
+			continue
+		}
+		remote, err := s.readRemoteConfig(r.DataPath)
+		if err != nil {
+			log.Warnf("⚠️  Skipping secret '%s': %v", r.SecretName, err)
+			continue
+		}
+		hash, err := syncHash(remote, r.SecretName)
+		if err != nil {
+			return nil, err
+		}
+
+		e, ok := byName[r.SecretName]
+		if !ok {
+			byName[r.SecretName] = &SyncEntry{Name: r.SecretName, Status: SyncRemoteOnly, RemoteHash: hash, remote: remote}
+			continue
+		}
+		e.RemoteHash = hash
+		e.remote = remote
+		e.Status = SyncDiverged
+		if e.LocalHash == hash {
+			e.Status = SyncIdentical
+		}
+	}
+
+	entries := make([]SyncEntry, 0, len(byName))
+	for _, e := range byName {
+		if len(selector) > 0 && e.Status == SyncLocalOnly {
+			continue
+		}
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// push writes the local context to the secret of the same name.
+func (s *VaultKubeconfigService) push(e *SyncEntry) {
+	encoded, err := encodeConfig(e.local)
+	if err == nil {
+		dataPath := s.dataBase + "/" + e.Name
+		log.Infof("📤 Pushing context '%s' to Vault at %s", e.Name, dataPath)
+		err = s.client.WriteSecret(dataPath, map[string]interface{}{s.secretKey: encoded})
+	}
+	if err != nil {
+		e.Action, e.Err = SyncFailed, fmt.Errorf("failed to write secret to Vault: %w", err)
+		return
+	}
+	e.Action = SyncPushed
+}
+
+// pull merges the remote configs into the local kubeconfig in a single
+// locked write, so the existing files are backed up once.
+func (s *VaultKubeconfigService) pull(kubeconfigPath string, entries []*SyncEntry) {
+	merged := &Config{APIVersion: "v1", Kind: "Config"}
+	for _, e := range entries {
+		log.Infof("📥 Pulling context '%s' from Vault", e.Name)
+		setComponentNames(e.remote, e.Name)
+		merged.Clusters = append(merged.Clusters, e.remote.Clusters...)
+		merged.Contexts = append(merged.Contexts, e.remote.Contexts...)
+		merged.Users = append(merged.Users, e.remote.Users...)
+	}
+
+	_, err := importConfig(kubeconfigPath, merged, ImportOptions{OnConflict: ConflictReplace})
+	for _, e := range entries {
+		if err != nil {
+			e.Action, e.Err = SyncFailed, err
+			continue
+		}
+		e.Action = SyncPulled
+	}
+}
+
+// readRemoteConfig reads and decodes the kubeconfig stored at dataPath.
+func (s *VaultKubeconfigService) readRemoteConfig(dataPath string) (*Config, error) {
+	encodedConfig, err := s.readSecretFieldValue(dataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	decodedConfig, err := decodeBase64Config(encodedConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode kubeconfig: %w", err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(decodedConfig, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	return &config, nil
+}
+
+// syncHash hashes a copy of a single-context config with every component
+// named name and the header fields set like extractContext does.
+func syncHash(config *Config, name string) (string, error) {
+	normalized := *config
+	normalized.Clusters = append([]Cluster(nil), config.Clusters...)
+	normalized.Contexts = append([]Context(nil), config.Contexts...)
+	normalized.Users = append([]User(nil), config.Users...)
+	setComponentNames(&normalized, name)
+	normalized.APIVersion, normalized.Kind, normalized.CurrentContext = "v1", "Config", name
+
+	data, err := yaml.Marshal(&normalized)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,200 @@
+package kubeconfig
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func syncContext(name, server string) *Config {
+	return &Config{
+		APIVersion:     "v1",
+		Kind:           "Config",
+		CurrentContext: name,
+		Clusters:       []Cluster{{Name: name + "-cluster", Cluster: ClusterConfig{Server: server}}},
+		Contexts:       []Context{{Name: name, Context: ContextConfig{Cluster: name + "-cluster", User: name + "-admin"}}},
+		Users:          []User{{Name: name + "-admin", User: UserConfig{Token: name + "-token"}}},
+	}
+}
+
+// syncFixture writes a local kubeconfig with "local", "same" and "drift" and
+// stores "same", "drift", "remote" and a two-context "bundle" in Vault.
+func syncFixture(t *testing.T) (string, *fakeVault, *VaultKubeconfigService) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	local := &Config{APIVersion: "v1", Kind: "Config", CurrentContext: "local"}
+	for _, c := range []*Config{
+		syncContext("local", "https://local:6443"),
+		syncContext("same", "https://same:6443"),
+		syncContext("drift", "https://drift:6443"),
+	} {
+		local.Clusters = append(local.Clusters, c.Clusters...)
+		local.Contexts = append(local.Contexts, c.Contexts...)
+		local.Users = append(local.Users, c.Users...)
+	}
+	if err := Save(path, local); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	vault, svc := newFakeVault(t)
+	// Pulled secrets are named after the secret; that is not drift.
+	same := syncContext("same", "https://same:6443")
+	setComponentNames(same, "same")
+	vault.put(t, "same", same)
+	vault.put(t, "drift", syncContext("drift", "https://drift.example.com:6443"))
+	vault.put(t, "remote", syncContext("remote", "https://remote:6443"))
+
+	bundle := syncContext("a", "https://a:6443")
+	bundle.Contexts = append(bundle.Contexts, Context{Name: "b", Context: ContextConfig{Cluster: "a-cluster", User: "a-admin"}})
+	vault.put(t, "bundle", bundle)
+	return path, vault, svc
+}
+
+func syncStatuses(report *SyncReport) map[string]string {
+	statuses := make(map[string]string)
+	for _, e := range report.Entries {
+		statuses[e.Name] = string(e.Status) + "/" + string(e.Action)
+	}
+	return statuses
+}
+
+func assertStatuses(t *testing.T, report *SyncReport, want map[string]string) {
+	t.Helper()
+	got := syncStatuses(report)
+	if len(got) != len(want) {
+		t.Errorf("expected %d entries, got %v", len(want), got)
+	}
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("%s: expected %s, got %s", name, status, got[name])
+		}
+	}
+}
+
+func TestSync_ReportOnly(t *testing.T) {
+	path, vault, svc := syncFixture(t)
+
+	report, err := svc.Sync(path, SyncOptions{Direction: SyncReportOnly})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertStatuses(t, report, map[string]string{
+		"local":  "local-only/",
+		"same":   "identical/",
+		"drift":  "diverged/",
+		"remote": "remote-only/",
+	})
+
+	if _, ok := vault.secrets["local"]; ok {
+		t.Error("a report must not push")
+	}
+	if names, _ := GetContextNames(path); len(names) != 3 {
+		t.Errorf("a report must not pull, got %v", names)
+	}
+
+	var out bytes.Buffer
+	report.Print(&out)
+	if !strings.Contains(out.String(), "1 local-only, 1 remote-only, 1 identical, 1 diverged; 0 pushed, 0 pulled, 0 failed") {
+		t.Errorf("unexpected summary:\n%s", out.String())
+	}
+}
+
+func TestSync_Push(t *testing.T) {
+	path, vault, svc := syncFixture(t)
+
+	report, err := svc.Sync(path, SyncOptions{Direction: SyncPush})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertStatuses(t, report, map[string]string{
+		"local":  "local-only/pushed",
+		"same":   "identical/",
+		"drift":  "diverged/pushed",
+		"remote": "remote-only/",
+	})
+
+	if server := vault.config(t, "drift").Clusters[0].Cluster.Server; server != "https://drift:6443" {
+		t.Errorf("expected the local content to win, got %s", server)
+	}
+	if vault.config(t, "local").Contexts[0].Name != "local" {
+		t.Error("expected local-only context to be pushed")
+	}
+	if names, _ := GetContextNames(path); len(names) != 3 {
+		t.Errorf("push must not change the local kubeconfig, got %v", names)
+	}
+}
+
+func TestSync_Pull(t *testing.T) {
+	path, vault, svc := syncFixture(t)
+
+	report, err := svc.Sync(path, SyncOptions{Direction: SyncPull})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertStatuses(t, report, map[string]string{
+		"local":  "local-only/",
+		"same":   "identical/",
+		"drift":  "diverged/pulled",
+		"remote": "remote-only/pulled",
+	})
+
+	config, _ := Load(path)
+	drift, err := InspectContext(config, "drift")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if drift.Server != "https://drift.example.com:6443" {
+		t.Errorf("expected the remote content to win, got %s", drift.Server)
+	}
+	if _, err := InspectContext(config, "remote"); err != nil {
+		t.Errorf("expected remote-only context to be pulled: %v", err)
+	}
+	if config.CurrentContext != "local" {
+		t.Errorf("current-context must not change, got %s", config.CurrentContext)
+	}
+	if _, ok := vault.secrets["local"]; ok {
+		t.Error("pull must not push")
+	}
+
+	// Pulled contexts compare identical afterwards.
+	report, err = svc.Sync(path, SyncOptions{Direction: SyncReportOnly})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Count(SyncIdentical) != 3 {
+		t.Errorf("expected 3 identical contexts after pull, got %v", syncStatuses(report))
+	}
+}
+
+func TestSync_BothLeavesDivergedAlone(t *testing.T) {
+	path, vault, svc := syncFixture(t)
+
+	report, err := svc.Sync(path, SyncOptions{Direction: SyncBoth})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertStatuses(t, report, map[string]string{
+		"local":  "local-only/pushed",
+		"same":   "identical/",
+		"drift":  "diverged/",
+		"remote": "remote-only/pulled",
+	})
+
+	if server := vault.config(t, "drift").Clusters[0].Cluster.Server; server != "https://drift.example.com:6443" {
+		t.Errorf("diverged secret must not change, got %s", server)
+	}
+
+	var out bytes.Buffer
+	report.Print(&out)
+	if !strings.Contains(out.String(), "1 diverged context(s) left untouched") {
+		t.Errorf("expected a hint about diverged contexts:\n%s", out.String())
+	}
+}
+
+func TestSync_InvalidDirection(t *testing.T) {
+	_, svc := newFakeVault(t)
+	if _, err := svc.Sync(filepath.Join(t.TempDir(), "config"), SyncOptions{Direction: "sideways"}); err == nil {
+		t.Error("expected an error for an unknown direction")
+	}
+}
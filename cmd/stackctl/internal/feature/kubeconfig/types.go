@@ -0,0 +1,125 @@
+package kubeconfig
+
+// Config represents a Kubernetes configuration.
+//
+// The model mirrors the kubeconfig v1 schema used by kubectl/client-go so that
+// loading and saving a file never drops credential plugins, auth providers,
+// file references or extensions. Any field stackctl does not know about is
+// kept in the inline Extra maps and written back unchanged.
+type Config struct {
+	APIVersion     string           `yaml:"apiVersion"`
+	Kind           string           `yaml:"kind"`
+	Preferences    *Preferences     `yaml:"preferences,omitempty"`
+	Clusters       []Cluster        `yaml:"clusters"`
+	Contexts       []Context        `yaml:"contexts"`
+	Users          []User           `yaml:"users"`
+	CurrentContext string           `yaml:"current-context,omitempty"`
+	Extensions     []NamedExtension `yaml:"extensions,omitempty"`
+
+	// Extra holds unknown top-level fields so they survive a round-trip.
+	Extra map[string]interface{} `yaml:",inline"`
+
+	// Internal field for duplicate detection (not serialized)
+	duplicateInfo *duplicateInfo `yaml:"-"`
+}
+
+// Preferences holds the kubeconfig preferences block.
+type Preferences struct {
+	Colors     bool             `yaml:"colors,omitempty"`
+	Extensions []NamedExtension `yaml:"extensions,omitempty"`
+
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// NamedExtension is a named, opaque extension object attached to a
+// kubeconfig entry. The payload is kept as decoded YAML.
+type NamedExtension struct {
+	Name      string      `yaml:"name"`
+	Extension interface{} `yaml:"extension"`
+}
+
+type Cluster struct {
+	Name    string        `yaml:"name"`
+	Cluster ClusterConfig `yaml:"cluster"`
+}
+
+type ClusterConfig struct {
+	Server                   string           `yaml:"server"`
+	TLSServerName            string           `yaml:"tls-server-name,omitempty"`
+	InsecureSkipTLSVerify    bool             `yaml:"insecure-skip-tls-verify,omitempty"`
+	CertificateAuthority     string           `yaml:"certificate-authority,omitempty"`
+	CertificateAuthorityData string           `yaml:"certificate-authority-data,omitempty"`
+	ProxyURL                 string           `yaml:"proxy-url,omitempty"`
+	DisableCompression       bool             `yaml:"disable-compression,omitempty"`
+	Extensions               []NamedExtension `yaml:"extensions,omitempty"`
+
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+type Context struct {
+	Name    string        `yaml:"name"`
+	Context ContextConfig `yaml:"context"`
+}
+
+type ContextConfig struct {
+	Cluster    string           `yaml:"cluster"`
+	User       string           `yaml:"user"`
+	Namespace  string           `yaml:"namespace,omitempty"`
+	Extensions []NamedExtension `yaml:"extensions,omitempty"`
+
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+type User struct {
+	Name string     `yaml:"name"`
+	User UserConfig `yaml:"user"`
+}
+
+type UserConfig struct {
+	ClientCertificate     string              `yaml:"client-certificate,omitempty"`
+	ClientCertificateData string              `yaml:"client-certificate-data,omitempty"`
+	ClientKey             string              `yaml:"client-key,omitempty"`
+	ClientKeyData         string              `yaml:"client-key-data,omitempty"`
+	Token                 string              `yaml:"token,omitempty"`
+	TokenFile             string              `yaml:"tokenFile,omitempty"`
+	Impersonate           string              `yaml:"as,omitempty"`
+	ImpersonateUID        string              `yaml:"as-uid,omitempty"`
+	ImpersonateGroups     []string            `yaml:"as-groups,omitempty"`
+	ImpersonateUserExtra  map[string][]string `yaml:"as-user-extra,omitempty"`
+	Username              string              `yaml:"username,omitempty"`
+	Password              string              `yaml:"password,omitempty"`
+	AuthProvider          *AuthProviderConfig `yaml:"auth-provider,omitempty"`
+	Exec                  *ExecConfig         `yaml:"exec,omitempty"`
+	Extensions            []NamedExtension    `yaml:"extensions,omitempty"`
+
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// AuthProviderConfig holds the configuration of a legacy auth provider
+// (e.g. oidc, gcp, azure).
+type AuthProviderConfig struct {
+	Name   string            `yaml:"name"`
+	Config map[string]string `yaml:"config,omitempty"`
+
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// ExecConfig describes an exec-based credential plugin such as
+// aws-iam-authenticator, gke-gcloud-auth-plugin or kubelogin.
+type ExecConfig struct {
+	APIVersion         string       `yaml:"apiVersion,omitempty"`
+	Command            string       `yaml:"command"`
+	Args               []string     `yaml:"args,omitempty"`
+	Env                []ExecEnvVar `yaml:"env,omitempty"`
+	InstallHint        string       `yaml:"installHint,omitempty"`
+	ProvideClusterInfo bool         `yaml:"provideClusterInfo,omitempty"`
+	InteractiveMode    string       `yaml:"interactiveMode,omitempty"`
+
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// ExecEnvVar is an environment variable passed to an exec credential plugin.
+type ExecEnvVar struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
@@ -0,0 +1,211 @@
+package kubeconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fullFidelityConfig exercises every part of the kubeconfig schema that
+// stackctl must carry through a load/save cycle untouched.
+const fullFidelityConfig = `apiVersion: v1
+kind: Config
+preferences: {}
+current-context: eks
+clusters:
+- name: eks
+  cluster:
+    server: https://ABC.gr7.us-east-1.eks.amazonaws.com
+    certificate-authority-data: Q0EtREFUQQ==
+    tls-server-name: kubernetes.default
+    proxy-url: socks5://localhost:1080
+    disable-compression: true
+    extensions:
+    - name: client.authentication.k8s.io/exec
+      extension:
+        audience: sts.amazonaws.com
+        nested:
+          values: [1, 2, 3]
+- name: on-prem
+  cluster:
+    server: https://10.0.0.1:6443
+    certificate-authority: /etc/ssl/on-prem-ca.crt
+    future-cluster-field: keep-me
+contexts:
+- name: eks
+  context:
+    cluster: eks
+    user: eks-user
+    namespace: apps
+    extensions:
+    - name: stackctl.io/meta
+      extension:
+        owner: platform
+- name: on-prem
+  context:
+    cluster: on-prem
+    user: basic
+users:
+- name: eks-user
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: aws
+      args:
+      - eks
+      - get-token
+      - --cluster-name
+      - prod
+      env:
+      - name: AWS_PROFILE
+        value: prod
+      installHint: install the aws cli
+      provideClusterInfo: true
+      interactiveMode: IfAvailable
+- name: oidc
+  user:
+    auth-provider:
+      name: oidc
+      config:
+        client-id: kubernetes
+        idp-issuer-url: https://issuer.example.com
+- name: basic
+  user:
+    username: admin
+    password: s3cret
+    as: jane
+    as-groups:
+    - developers
+    as-user-extra:
+      reason:
+      - debugging
+- name: files
+  user:
+    client-certificate: /home/me/.certs/client.crt
+    client-key: /home/me/.certs/client.key
+    tokenFile: /var/run/secrets/token
+extensions:
+- name: top-level
+  extension:
+    enabled: true
+unknown-top-level:
+  anything: goes
+`
+
+// semanticYAML decodes raw YAML into generic values so two documents can be
+// compared independently of key order and formatting.
+func semanticYAML(t *testing.T, data []byte) interface{} {
+	t.Helper()
+	var out interface{}
+	if err := yaml.Unmarshal(data, &out); err != nil {
+		t.Fatalf("failed to decode yaml: %v", err)
+	}
+	return out
+}
+
+func TestLoadSave_RoundTripPreservesAllFields(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config")
+	if err := os.WriteFile(configPath, []byte(fullFidelityConfig), 0600); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	config, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if err := Save(configPath, config); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	saved, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to read saved config: %v", err)
+	}
+
+	want := semanticYAML(t, []byte(fullFidelityConfig))
+	got := semanticYAML(t, saved)
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("round-trip changed the document:\nwant: %#v\ngot:  %#v", want, got)
+	}
+}
+
+func TestMerge_PreservesCredentialPluginsAndExtensions(t *testing.T) {
+	var existing Config
+	if err := yaml.Unmarshal([]byte(fullFidelityConfig), &existing); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+
+	incoming := &Config{
+		APIVersion: "v1",
+		Kind:       "Config",
+		Clusters:   []Cluster{{Name: "new", Cluster: ClusterConfig{Server: "https://new:6443"}}},
+		Contexts:   []Context{{Name: "new", Context: ContextConfig{Cluster: "new", User: "new"}}},
+		Users:      []User{{Name: "new", User: UserConfig{Token: "t"}}},
+	}
+
+	merged := Merge(&existing, incoming)
+
+	if merged.Preferences == nil {
+		t.Error("expected preferences to survive merge")
+	}
+	if len(merged.Extensions) != 1 || merged.Extensions[0].Name != "top-level" {
+		t.Errorf("expected top-level extensions to survive merge, got %+v", merged.Extensions)
+	}
+	if _, ok := merged.Extra["unknown-top-level"]; !ok {
+		t.Error("expected unknown top-level field to survive merge")
+	}
+
+	var eks *UserConfig
+	for i := range merged.Users {
+		if merged.Users[i].Name == "eks-user" {
+			eks = &merged.Users[i].User
+		}
+	}
+	if eks == nil || eks.Exec == nil {
+		t.Fatal("expected exec credential plugin to survive merge")
+	}
+	if eks.Exec.Command != "aws" || len(eks.Exec.Args) != 4 || eks.Exec.Env[0].Name != "AWS_PROFILE" {
+		t.Errorf("exec plugin changed during merge: %+v", eks.Exec)
+	}
+}
+
+func TestSetNamespace_PreservesUnknownFields(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config")
+	if err := os.WriteFile(configPath, []byte(fullFidelityConfig), 0600); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	if err := SetNamespace(configPath, "on-prem", "kube-system"); err != nil {
+		t.Fatalf("failed to set namespace: %v", err)
+	}
+
+	config, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	for _, cluster := range config.Clusters {
+		if cluster.Name != "on-prem" {
+			continue
+		}
+		if cluster.Cluster.CertificateAuthority != "/etc/ssl/on-prem-ca.crt" {
+			t.Errorf("expected certificate-authority file ref to be kept, got %q", cluster.Cluster.CertificateAuthority)
+		}
+		if cluster.Cluster.Extra["future-cluster-field"] != "keep-me" {
+			t.Errorf("expected unknown cluster field to be kept, got %v", cluster.Cluster.Extra)
+		}
+	}
+	for _, user := range config.Users {
+		if user.Name == "basic" && (user.User.Username != "admin" || user.User.Password != "s3cret") {
+			t.Errorf("expected basic auth credentials to be kept, got %+v", user.User)
+		}
+		if user.Name == "oidc" && (user.User.AuthProvider == nil || user.User.AuthProvider.Config["client-id"] != "kubernetes") {
+			t.Errorf("expected auth-provider to be kept, got %+v", user.User.AuthProvider)
+		}
+	}
+}
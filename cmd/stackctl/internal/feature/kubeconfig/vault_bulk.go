@@ -0,0 +1,479 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// saveConcurrency is the number of secrets written to Vault at the same time.
+const saveConcurrency = 8
+
+// BulkSaveOptions selects the contexts SaveContexts writes to Vault. Exactly
+// one of All, Contexts and Selector is set.
+type BulkSaveOptions struct {
+	All      bool
+	Contexts []string
+	// Selector is a regular expression matched against context names.
+	Selector string
+	// Bundle writes the selected contexts to a single secret of that name
+	// instead of one secret per context.
+	Bundle string
+	// Save holds the tags and description applied to every secret.
+	Save SaveOptions
+}
+
+// SaveResult is the outcome of writing one secret.
+type SaveResult struct {
+	Secret   string
+	Contexts []string
+	Err      error
+}
+
+// BulkSaveReport lists the secrets SaveContexts wrote, in context order.
+type BulkSaveReport struct {
+	Results []SaveResult
+}
+
+// Failed returns the number of secrets that could not be written.
+func (r *BulkSaveReport) Failed() int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Print writes the report.
+func (r *BulkSaveReport) Print(w io.Writer) {
+	for _, res := range r.Results {
+		if res.Err != nil {
+			_, _ = fmt.Fprintf(w, "  ❌ %s: %v\n", res.Secret, res.Err)
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "  ✅ %s (%d contexts)\n", res.Secret, len(res.Contexts))
+	}
+	_, _ = fmt.Fprintf(w, "\n📤 Saved %d of %d secrets to Vault", len(r.Results)-r.Failed(), len(r.Results))
+	if failed := r.Failed(); failed > 0 {
+		_, _ = fmt.Fprintf(w, ", %d failed", failed)
+	}
+	_, _ = fmt.Fprintln(w)
+}
+
+// SelectContexts returns the names of the contexts of config chosen by opts,
+// in kubeconfig order.
+func SelectContexts(config *Config, opts BulkSaveOptions) ([]string, error) {
+	selected := 0
+	for _, set := range []bool{opts.All, len(opts.Contexts) > 0, opts.Selector != ""} {
+		if set {
+			selected++
+		}
+	}
+	if selected != 1 {
+		return nil, fmt.Errorf("exactly one of all, contexts or selector must be set")
+	}
+
+	var names []string
+	switch {
+	case opts.All:
+		for _, ctx := range config.Contexts {
+			names = append(names, ctx.Name)
+		}
+	case opts.Selector != "":
+		re, err := regexp.Compile(opts.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector: %w", err)
+		}
+		for _, ctx := range config.Contexts {
+			if re.MatchString(ctx.Name) {
+				names = append(names, ctx.Name)
+			}
+		}
+	default:
+		existing := make(map[string]bool, len(config.Contexts))
+		for _, ctx := range config.Contexts {
+			existing[ctx.Name] = true
+		}
+		for _, name := range opts.Contexts {
+			if !existing[name] {
+				return nil, fmt.Errorf("context '%s' not found in kubeconfig", name)
+			}
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no contexts selected")
+	}
+	return names, nil
+}
+
+// SaveContexts writes the contexts selected by opts to Vault, each as the
+// secret of the same name or all of them as the Bundle secret. Secrets are
+// written concurrently; a failed write is reported in its result and does not
+// stop the others.
+func (s *VaultKubeconfigService) SaveContexts(kubeconfigPath string, opts BulkSaveOptions) (*BulkSaveReport, error) {
+	config, err := Load(kubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	names, err := SelectContexts(config, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Bundle != "" {
+		bundle, err := extractContexts(config, names)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("📝 Saving %d contexts to Vault as '%s'", len(names), opts.Bundle)
+		result := SaveResult{Secret: opts.Bundle, Contexts: names, Err: s.saveConfig(bundle, opts.Bundle, opts.Save)}
+		return &BulkSaveReport{Results: []SaveResult{result}}, nil
+	}
+
+	report := &BulkSaveReport{Results: make([]SaveResult, len(names))}
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		done int
+		sem  = make(chan struct{}, saveConcurrency)
+	)
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result := SaveResult{Secret: name, Contexts: []string{name}}
+			singleConfig, err := extractContext(config, name)
+			if err == nil {
+				err = s.saveConfig(singleConfig, name, opts.Save)
+			}
+			result.Err = err
+			report.Results[i] = result
+
+			mu.Lock()
+			done++
+			if err != nil {
+				log.Warnf("❌ [%d/%d] Context '%s': %v", done, len(names), name, err)
+			} else {
+				log.Infof("✅ [%d/%d] Context '%s' saved", done, len(names), name)
+			}
+			mu.Unlock()
+		}(i, name)
+	}
+	wg.Wait()
+	return report, nil
+}
+
+// extractContexts returns a config holding the named contexts and the
+// clusters and users they reference. The current context is kept when it is
+// among them.
+func extractContexts(config *Config, names []string) (*Config, error) {
+	bundle := &Config{APIVersion: "v1", Kind: "Config"}
+	clusters := make(map[string]bool)
+	users := make(map[string]bool)
+	for _, name := range names {
+		single, err := extractContext(config, name)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Contexts = append(bundle.Contexts, single.Contexts...)
+		if c := single.Clusters[0]; !clusters[c.Name] {
+			clusters[c.Name] = true
+			bundle.Clusters = append(bundle.Clusters, c)
+		}
+		if u := single.Users[0]; !users[u.Name] {
+			users[u.Name] = true
+			bundle.Users = append(bundle.Users, u)
+		}
+		if name == config.CurrentContext || bundle.CurrentContext == "" {
+			bundle.CurrentContext = name
+		}
+	}
+	return bundle, nil
+}
+
+// BulkImportOptions selects the secrets ImportContexts fetches from Vault.
+// All, Match and Selector can be combined; at least one must be set.
+type BulkImportOptions struct {
+	All bool
+	// Match is a glob (path.Match) on secret names, e.g. "prod-*".
+	Match string
+	// Selector filters the secrets by their tags.
+	Selector Selector
+	// Missing skips secrets whose contexts already exist locally.
+	Missing bool
+	// Import controls how the fetched contexts are merged.
+	Import ImportOptions
+}
+
+// ImportStatus is the outcome of importing one secret.
+type ImportStatus string
+
+const (
+	ImportImported ImportStatus = "imported"
+	ImportSkipped  ImportStatus = "skipped"
+	ImportFailed   ImportStatus = "failed"
+)
+
+// ImportResult is the outcome of importing one secret.
+type ImportResult struct {
+	Secret   string
+	Contexts []string
+	Status   ImportStatus
+	// Reason explains a skipped or failed secret.
+	Reason string
+}
+
+// BulkImportReport lists the secrets ImportContexts considered, by name.
+type BulkImportReport struct {
+	Results []ImportResult
+	DryRun  bool
+}
+
+// Count returns the number of results with the given status.
+func (r *BulkImportReport) Count(status ImportStatus) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Print writes the report.
+func (r *BulkImportReport) Print(w io.Writer) {
+	if len(r.Results) == 0 {
+		_, _ = fmt.Fprintln(w, "✅ No matching kubeconfigs in Vault")
+		return
+	}
+	icons := map[ImportStatus]string{ImportImported: "✅", ImportSkipped: "⏭️ ", ImportFailed: "❌"}
+	for _, res := range r.Results {
+		line := fmt.Sprintf("  %s %s", icons[res.Status], res.Secret)
+		if len(res.Contexts) > 0 {
+			line += fmt.Sprintf(" (%s)", strings.Join(res.Contexts, ", "))
+		}
+		if res.Reason != "" {
+			line += ": " + res.Reason
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
+	verb := "Imported"
+	if r.DryRun {
+		verb = "Would import"
+	}
+	_, _ = fmt.Fprintf(w, "\n📥 %s %d secrets, %d skipped, %d failed\n",
+		verb, r.Count(ImportImported), r.Count(ImportSkipped), r.Count(ImportFailed))
+}
+
+// ImportContexts fetches the secrets selected by opts concurrently and merges
+// them into the kubeconfig at kubeconfigPath in a single locked write, so the existing
+// files are backed up once. Single-context secrets are renamed after the
+// secret, like add-from-vault; bundles keep their names. A secret defining a
+// cluster, context or user that an earlier secret (by name) defines with
+// different content fails instead of overwriting it.
+func (s *VaultKubeconfigService) ImportContexts(kubeconfigPath string, opts BulkImportOptions) (*BulkImportReport, error) {
+	if !opts.All && opts.Match == "" && len(opts.Selector) == 0 {
+		return nil, fmt.Errorf("one of all, match or selector must be set")
+	}
+	if opts.Match != "" {
+		if _, err := path.Match(opts.Match, ""); err != nil {
+			return nil, fmt.Errorf("invalid match pattern: %w", err)
+		}
+	}
+	if s.client == nil {
+		return nil, fmt.Errorf("failed to list secrets")
+	}
+
+	keys, err := s.client.ListSecrets(s.metadataBase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets at %s: %w", s.metadataBase, err)
+	}
+	var names []string
+	for _, key := range keys {
+		name := strings.TrimRight(key, "/")
+		if opts.Match != "" {
+			if ok, _ := path.Match(opts.Match, name); !ok {
+				continue
+			}
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	local := make(map[string]bool)
+	if opts.Missing {
+		existing, err := GetContextNames(kubeconfigPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+		}
+		for _, name := range existing {
+			local[name] = true
+		}
+	}
+
+	report := &BulkImportReport{Results: make([]ImportResult, len(names)), DryRun: opts.Import.DryRun}
+	configs := make([]*Config, len(names))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, saveConcurrency)
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			report.Results[i], configs[i] = s.fetchForImport(name, opts, local)
+		}(i, name)
+	}
+	wg.Wait()
+
+	// Secrets deselected by the tag selector are left out of the report.
+	merged := &Config{APIVersion: "v1", Kind: "Config"}
+	claims := make(entryClaims)
+	var results []ImportResult
+	var imported []int
+	for i, res := range report.Results {
+		if res.Status == "" {
+			continue
+		}
+		if res.Status == ImportImported {
+			if reason := claims.clash(configs[i]); reason != "" {
+				res.Status, res.Reason = ImportFailed, reason
+			}
+		}
+		results = append(results, res)
+		if res.Status != ImportImported {
+			continue
+		}
+		claims.claim(res.Secret, configs[i])
+		imported = append(imported, len(results)-1)
+		merged.Clusters = append(merged.Clusters, configs[i].Clusters...)
+		merged.Contexts = append(merged.Contexts, configs[i].Contexts...)
+		merged.Users = append(merged.Users, configs[i].Users...)
+	}
+	report.Results = results
+
+	if len(imported) == 0 {
+		return report, nil
+	}
+	if _, err := importConfig(kubeconfigPath, merged, opts.Import); err != nil {
+		for _, i := range imported {
+			report.Results[i].Status, report.Results[i].Reason = ImportFailed, err.Error()
+		}
+		return report, nil
+	}
+	if !opts.Import.DryRun {
+		warnLint(merged)
+	}
+	return report, nil
+}
+
+// fetchForImport reads and filters one secret for ImportContexts. It returns
+// an empty status when the secret does not match the tag selector.
+func (s *VaultKubeconfigService) fetchForImport(name string, opts BulkImportOptions, local map[string]bool) (ImportResult, *Config) {
+	result := ImportResult{Secret: name}
+	if len(opts.Selector) > 0 {
+		custom, err := s.readCustomMetadata(name)
+		if err != nil {
+			result.Status, result.Reason = ImportFailed, fmt.Sprintf("failed to read metadata: %v", err)
+			return result, nil
+		}
+		if tags, _ := splitDescription(custom); !opts.Selector.Matches(tags) {
+			return ImportResult{}, nil
+		}
+	}
+
+	log.Infof("📥 Fetching '%s' from Vault", name)
+	config, err := s.readRemoteConfig(s.dataBase + "/" + name)
+	if err != nil {
+		result.Status, result.Reason = ImportFailed, err.Error()
+		return result, nil
+	}
+	if len(config.Contexts) == 0 {
+		result.Status, result.Reason = ImportSkipped, "no contexts found"
+		return result, nil
+	}
+	if len(config.Contexts) == 1 {
+		setComponentNames(config, name)
+	}
+	for _, ctx := range config.Contexts {
+		result.Contexts = append(result.Contexts, ctx.Name)
+	}
+
+	if opts.Missing {
+		present := true
+		for _, ctx := range result.Contexts {
+			present = present && local[ctx]
+		}
+		if present {
+			result.Status, result.Reason = ImportSkipped, "already present locally"
+			return result, nil
+		}
+	}
+	result.Status = ImportImported
+	return result, config
+}
+
+// entryClaims records which fetched secret defines each cluster, context and
+// user name, so that two secrets defining the same name differently are
+// caught before they are merged into each other.
+type entryClaims map[string]entryClaim
+
+type entryClaim struct {
+	secret string
+	entry  any
+}
+
+// configEntries keys the clusters, contexts and users of config by kind and name.
+func configEntries(config *Config) map[string]any {
+	entries := make(map[string]any)
+	for _, c := range config.Clusters {
+		entries[fmt.Sprintf("cluster '%s'", c.Name)] = c
+	}
+	for _, c := range config.Contexts {
+		entries[fmt.Sprintf("context '%s'", c.Name)] = c
+	}
+	for _, u := range config.Users {
+		entries[fmt.Sprintf("user '%s'", u.Name)] = u
+	}
+	return entries
+}
+
+// clash describes the first entry of config already claimed by another
+// secret with different content; empty when there is none.
+func (c entryClaims) clash(config *Config) string {
+	entries := configEntries(config)
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if claim, ok := c[key]; ok && !reflect.DeepEqual(claim.entry, entries[key]) {
+			return fmt.Sprintf("%s is also defined by '%s' with different content", key, claim.secret)
+		}
+	}
+	return ""
+}
+
+// claim records the entries of config as defined by secret.
+func (c entryClaims) claim(secret string, config *Config) {
+	for key, entry := range configEntries(config) {
+		if _, ok := c[key]; !ok {
+			c[key] = entryClaim{secret: secret, entry: entry}
+		}
+	}
+}
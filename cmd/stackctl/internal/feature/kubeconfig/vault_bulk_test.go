@@ -0,0 +1,197 @@
+package kubeconfig
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSelectContexts(t *testing.T) {
+	config := &Config{Contexts: []Context{{Name: "prod-eu"}, {Name: "prod-us"}, {Name: "dev"}}}
+
+	tests := []struct {
+		opts     BulkSaveOptions
+		expected string
+	}{
+		{BulkSaveOptions{All: true}, "prod-eu,prod-us,dev"},
+		{BulkSaveOptions{Contexts: []string{"dev", "prod-us"}}, "dev,prod-us"},
+		{BulkSaveOptions{Selector: "^prod-"}, "prod-eu,prod-us"},
+	}
+	for _, tt := range tests {
+		names, err := SelectContexts(config, tt.opts)
+		if err != nil {
+			t.Errorf("%+v: unexpected error: %v", tt.opts, err)
+			continue
+		}
+		if got := strings.Join(names, ","); got != tt.expected {
+			t.Errorf("%+v: expected %s, got %s", tt.opts, tt.expected, got)
+		}
+	}
+
+	for _, invalid := range []BulkSaveOptions{
+		{},
+		{All: true, Selector: "prod"},
+		{Contexts: []string{"missing"}},
+		{Selector: "("},
+		{Selector: "^staging"},
+	} {
+		if _, err := SelectContexts(config, invalid); err == nil {
+			t.Errorf("expected an error for %+v", invalid)
+		}
+	}
+}
+
+func TestSaveContexts(t *testing.T) {
+	path, vault, svc := syncFixture(t)
+
+	report, err := svc.SaveContexts(path, BulkSaveOptions{All: true, Save: SaveOptions{Tags: map[string]string{"team": "platform"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Results) != 3 || report.Failed() != 0 {
+		t.Fatalf("expected three saved secrets, got %+v", report.Results)
+	}
+	for i, name := range []string{"local", "same", "drift"} {
+		if report.Results[i].Secret != name {
+			t.Errorf("expected results in context order, got %+v", report.Results)
+		}
+		config := vault.config(t, name)
+		if len(config.Contexts) != 1 || config.Contexts[0].Name != name {
+			t.Errorf("expected secret %s to hold only its context, got %+v", name, config.Contexts)
+		}
+		if vault.secrets[name].custom["team"] != "platform" {
+			t.Errorf("expected secret %s to be tagged, got %v", name, vault.secrets[name].custom)
+		}
+	}
+}
+
+func TestSaveContexts_Bundle(t *testing.T) {
+	path, vault, svc := syncFixture(t)
+
+	report, err := svc.SaveContexts(path, BulkSaveOptions{Contexts: []string{"same", "local"}, Bundle: "team"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Results) != 1 || report.Results[0].Err != nil {
+		t.Fatalf("expected a single bundle secret, got %+v", report.Results)
+	}
+
+	config := vault.config(t, "team")
+	if len(config.Contexts) != 2 || len(config.Clusters) != 2 || len(config.Users) != 2 {
+		t.Errorf("expected both contexts with their clusters and users, got %+v", config)
+	}
+	if config.CurrentContext != "local" {
+		t.Errorf("expected the local current context to be kept, got %s", config.CurrentContext)
+	}
+}
+
+func importStatuses(report *BulkImportReport) map[string]string {
+	statuses := make(map[string]string)
+	for _, res := range report.Results {
+		statuses[res.Secret] = string(res.Status)
+	}
+	return statuses
+}
+
+func TestImportContexts_AllMissing(t *testing.T) {
+	path, vault, svc := syncFixture(t)
+	t.Setenv(BackupDirEnvVar, t.TempDir())
+	vault.routes["GET "+vault.layout.DataPath()+"/drift"] = func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}
+
+	report, err := svc.ImportContexts(path, BulkImportOptions{All: true, Missing: true, Import: ImportOptions{OnConflict: ConflictReplace}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{"bundle": "imported", "drift": "failed", "remote": "imported", "same": "skipped"}
+	if got := importStatuses(report); len(got) != len(expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	} else {
+		for name, status := range expected {
+			if got[name] != status {
+				t.Errorf("%s: expected %s, got %s", name, status, got[name])
+			}
+		}
+	}
+
+	names, err := GetContextNames(path)
+	if err != nil {
+		t.Fatalf("failed to read contexts: %v", err)
+	}
+	if got := strings.Join(names, ","); got != "local,same,drift,a,b,remote" {
+		t.Errorf("expected the bundle to keep its names and the single secret to be renamed, got %s", got)
+	}
+	backups, err := ListBackups(path, BackupPolicyFromEnv())
+	if err != nil || len(backups) != 1 {
+		t.Errorf("expected a single backup, got %v (%v)", backups, err)
+	}
+}
+
+func TestImportContexts_MatchAndSelector(t *testing.T) {
+	path, vault, svc := syncFixture(t)
+	t.Setenv(BackupDirEnvVar, t.TempDir())
+	vault.tag("remote", map[string]interface{}{"env": "staging"})
+	vault.tag("same", map[string]interface{}{"env": "staging"})
+
+	selector, err := ParseSelector("env=staging")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, err := svc.ImportContexts(path, BulkImportOptions{Match: "re*", Selector: selector, Import: ImportOptions{DryRun: true}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := importStatuses(report); len(got) != 1 || got["remote"] != "imported" {
+		t.Errorf("expected only remote to be selected, got %v", got)
+	}
+	if names, _ := GetContextNames(path); len(names) != 3 {
+		t.Errorf("expected a dry run not to write the kubeconfig, got %v", names)
+	}
+
+	for _, invalid := range []BulkImportOptions{{}, {Match: "["}} {
+		if _, err := svc.ImportContexts(path, invalid); err == nil {
+			t.Errorf("expected an error for %+v", invalid)
+		}
+	}
+}
+
+func TestImportContexts_BundleCollision(t *testing.T) {
+	path, vault, svc := syncFixture(t)
+	t.Setenv(BackupDirEnvVar, t.TempDir())
+	// Same cluster and user names as "bundle", pointing elsewhere.
+	other := syncContext("c", "https://c:6443")
+	other.Clusters[0].Name, other.Users[0].Name = "a-cluster", "a-admin"
+	other.Contexts[0].Context.Cluster, other.Contexts[0].Context.User = "a-cluster", "a-admin"
+	other.Contexts = append(other.Contexts, Context{Name: "d", Context: ContextConfig{Cluster: "a-cluster", User: "a-admin"}})
+	vault.put(t, "bundle2", other)
+
+	report, err := svc.ImportContexts(path, BulkImportOptions{Match: "bundle*", Import: ImportOptions{OnConflict: ConflictReplace}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := importStatuses(report)
+	if got["bundle"] != "imported" || got["bundle2"] != "failed" {
+		t.Fatalf("expected the second bundle to fail, got %v", got)
+	}
+	for _, res := range report.Results {
+		if res.Secret == "bundle2" && !strings.Contains(res.Reason, "cluster 'a-cluster' is also defined by 'bundle'") {
+			t.Errorf("expected the clash to be explained, got %q", res.Reason)
+		}
+	}
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load kubeconfig: %v", err)
+	}
+	for _, c := range config.Clusters {
+		if c.Name == "a-cluster" && c.Cluster.Server != "https://a:6443" {
+			t.Errorf("expected the first bundle's cluster to be kept, got %s", c.Cluster.Server)
+		}
+	}
+	for _, ctx := range config.Contexts {
+		if ctx.Name == "c" {
+			t.Error("expected the clashing bundle not to be imported")
+		}
+	}
+}
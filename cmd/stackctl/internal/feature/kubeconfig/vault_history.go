@@ -0,0 +1,225 @@
+package kubeconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// SecretVersion is one KV v2 version of a kubeconfig secret.
+type SecretVersion struct {
+	Version     int       `json:"version"`
+	CreatedTime time.Time `json:"createdTime"`
+	// DeletionTime is set when the version was soft-deleted.
+	DeletionTime time.Time `json:"deletionTime,omitzero"`
+	Destroyed    bool      `json:"destroyed"`
+	Current      bool      `json:"current"`
+}
+
+// Available reports whether the data of the version can still be read.
+func (v SecretVersion) Available() bool {
+	return v.DeletionTime.IsZero() && !v.Destroyed
+}
+
+// ParseVersion parses a version number given as "3" or "v3".
+func ParseVersion(value string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(value), "v"))
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid version %q (expected a positive number like 3 or v3)", value)
+	}
+	return n, nil
+}
+
+// History returns the versions of a kubeconfig secret, newest first, read
+// from the KV v2 metadata endpoint.
+func (s *VaultKubeconfigService) History(secretName string) ([]SecretVersion, error) {
+	metadata, err := s.readMetadata(secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	current := toInt(metadata["current_version"])
+	raw, _ := metadata["versions"].(map[string]interface{})
+	versions := make([]SecretVersion, 0, len(raw))
+	for key, value := range raw {
+		n, err := strconv.Atoi(key)
+		if err != nil {
+			continue
+		}
+		fields, _ := value.(map[string]interface{})
+		destroyed, _ := fields["destroyed"].(bool)
+		versions = append(versions, SecretVersion{
+			Version:      n,
+			CreatedTime:  parseVaultTime(fields["created_time"]),
+			DeletionTime: parseVaultTime(fields["deletion_time"]),
+			Destroyed:    destroyed,
+			Current:      n == current,
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version > versions[j].Version })
+	return versions, nil
+}
+
+// PrintHistory writes the versions of a secret as a table.
+func PrintHistory(w io.Writer, secretName string, versions []SecretVersion) error {
+	if len(versions) == 0 {
+		_, _ = fmt.Fprintf(w, "No versions found for '%s'\n", secretName)
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(w, "📜 Versions of '%s' (newest first):\n", secretName)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "VERSION\tCREATED\tSTATUS")
+	for _, v := range versions {
+		status := "available"
+		switch {
+		case v.Destroyed:
+			status = "destroyed"
+		case !v.DeletionTime.IsZero():
+			status = "deleted " + v.DeletionTime.Local().Format("2006-01-02 15:04:05")
+		}
+		if v.Current {
+			status += " (current)"
+		}
+		_, _ = fmt.Fprintf(tw, "v%d\t%s\t%s\n", v.Version, v.CreatedTime.Local().Format("2006-01-02 15:04:05"), status)
+	}
+	return tw.Flush()
+}
+
+// DiffVersions returns the line diff between two versions of a secret, with
+// both kubeconfigs decoded and their credentials redacted.
+func (s *VaultKubeconfigService) DiffVersions(secretName string, from, to int) ([]string, error) {
+	old, err := s.redactedVersionYAML(secretName, from)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.redactedVersionYAML(secretName, to)
+	if err != nil {
+		return nil, err
+	}
+	return DiffLines(old, current), nil
+}
+
+// RestoreVersion writes the data of an earlier version as a new version, as
+// "vault kv rollback" does, and returns the new version number.
+func (s *VaultKubeconfigService) RestoreVersion(secretName string, version int) (int, error) {
+	data, err := s.readVersion(secretName, version)
+	if err != nil {
+		return 0, err
+	}
+
+	client, err := s.client.VaultClient()
+	if err != nil {
+		return 0, err
+	}
+	dataPath := s.dataBase + "/" + secretName
+	log.Infof("⏪ Restoring version %d of '%s' at %s", version, secretName, dataPath)
+
+	secret, err := client.Logical().Write(dataPath, map[string]interface{}{"data": data})
+	if err != nil {
+		return 0, fmt.Errorf("failed to write secret to Vault: %w", err)
+	}
+	if secret == nil {
+		return 0, nil
+	}
+	return toInt(secret.Data["version"]), nil
+}
+
+// redactedVersionYAML decodes a version and renders it with credentials masked.
+func (s *VaultKubeconfigService) redactedVersionYAML(secretName string, version int) (string, error) {
+	data, err := s.readVersion(secretName, version)
+	if err != nil {
+		return "", err
+	}
+	encoded, ok := lookupField(data, s.secretKey)
+	if !ok {
+		return "", fmt.Errorf("field '%s' not found or empty in version %d of '%s'", s.secretKey, version, secretName)
+	}
+
+	decoded, err := decodeBase64Config(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode version %d: %w", version, err)
+	}
+	var config Config
+	if err := yaml.Unmarshal(decoded, &config); err != nil {
+		return "", fmt.Errorf("failed to parse version %d: %w", version, err)
+	}
+
+	out, err := yaml.Marshal(RedactConfig(&config))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return string(out), nil
+}
+
+// readVersion reads the key-value data of one version of a secret.
+func (s *VaultKubeconfigService) readVersion(secretName string, version int) (map[string]interface{}, error) {
+	client, err := s.client.VaultClient()
+	if err != nil {
+		return nil, err
+	}
+
+	dataPath := s.dataBase + "/" + secretName
+	secret, err := client.Logical().ReadWithData(dataPath, map[string][]string{"version": {strconv.Itoa(version)}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read version %d of %s: %w", version, dataPath, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("version %d of '%s' not found", version, secretName)
+	}
+	data, ok := secret.Data["data"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("version %d of '%s' is deleted or destroyed", version, secretName)
+	}
+	return data, nil
+}
+
+// readMetadata reads the KV v2 metadata of a secret.
+func (s *VaultKubeconfigService) readMetadata(secretName string) (map[string]interface{}, error) {
+	client, err := s.client.VaultClient()
+	if err != nil {
+		return nil, err
+	}
+
+	metadataPath := s.metadataBase + "/" + secretName
+	secret, err := client.Logical().Read(metadataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata at %s: %w", metadataPath, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("secret '%s' not found at %s", secretName, metadataPath)
+	}
+	return secret.Data, nil
+}
+
+// toInt converts a number decoded from a Vault response.
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case json.Number:
+		n, _ := v.Int64()
+		return int(n)
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+// parseVaultTime parses a Vault timestamp; empty values are the zero time.
+func parseVaultTime(value interface{}) time.Time {
+	s, _ := value.(string)
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,122 @@
+package kubeconfig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// historyFixture stores three versions of "prod": the token changes in v2
+// and the server in v3.
+func historyFixture(t *testing.T) (*fakeVault, *VaultKubeconfigService) {
+	t.Helper()
+	vault, svc := newFakeVault(t)
+	v1 := syncContext("prod", "https://prod:6443")
+	vault.put(t, "prod", v1)
+
+	v2 := syncContext("prod", "https://prod:6443")
+	v2.Users[0].User.Token = "rotated-token"
+	vault.put(t, "prod", v2)
+
+	v3 := syncContext("prod", "https://prod.example.com:6443")
+	v3.Users[0].User.Token = "rotated-token"
+	vault.put(t, "prod", v3)
+	return vault, svc
+}
+
+func TestParseVersion(t *testing.T) {
+	for value, want := range map[string]int{"3": 3, "v12": 12, "V1": 1} {
+		got, err := ParseVersion(value)
+		if err != nil || got != want {
+			t.Errorf("ParseVersion(%q) = %d, %v; want %d", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "0", "v", "latest", "-1"} {
+		if _, err := ParseVersion(value); err == nil {
+			t.Errorf("ParseVersion(%q): expected an error", value)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	vault, svc := historyFixture(t)
+	vault.secrets["prod"].versions[0].deleted = true
+
+	versions, err := svc.History("prod")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(versions) != 3 || versions[0].Version != 3 || versions[2].Version != 1 {
+		t.Fatalf("expected versions 3, 2, 1, got %+v", versions)
+	}
+	if !versions[0].Current || versions[1].Current {
+		t.Errorf("expected only v3 to be current, got %+v", versions)
+	}
+	if versions[2].Available() || !versions[1].Available() {
+		t.Errorf("expected v1 to be deleted, got %+v", versions)
+	}
+
+	var out bytes.Buffer
+	if err := PrintHistory(&out, "prod", versions); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "(current)") || !strings.Contains(out.String(), "deleted") {
+		t.Errorf("unexpected history:\n%s", out.String())
+	}
+
+	if _, err := svc.History("missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestDiffVersions_RedactsCredentials(t *testing.T) {
+	_, svc := historyFixture(t)
+
+	lines, err := svc.DiffVersions("prod", 1, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	diff := strings.Join(lines, "\n")
+	if !strings.Contains(diff, "- ") || !strings.Contains(diff, "https://prod.example.com:6443") {
+		t.Errorf("expected the server change in the diff:\n%s", diff)
+	}
+	if strings.Contains(diff, "prod-token") || strings.Contains(diff, "rotated-token") {
+		t.Errorf("diff must not contain credentials:\n%s", diff)
+	}
+	if !strings.Contains(diff, "<redacted:") {
+		t.Errorf("expected redacted token change in the diff:\n%s", diff)
+	}
+
+	lines, err = svc.DiffVersions("prod", 3, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if HasDiff(lines) {
+		t.Errorf("expected no diff between the same version, got %v", lines)
+	}
+
+	if _, err := svc.DiffVersions("prod", 1, 9); err == nil {
+		t.Error("expected an error for a missing version")
+	}
+}
+
+func TestRestoreVersion(t *testing.T) {
+	vault, svc := historyFixture(t)
+
+	version, err := svc.RestoreVersion("prod", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version != 4 {
+		t.Errorf("expected the restore to create v4, got v%d", version)
+	}
+	restored := vault.config(t, "prod")
+	if restored.Clusters[0].Cluster.Server != "https://prod:6443" || restored.Users[0].User.Token != "prod-token" {
+		t.Errorf("expected v1 content, got %+v", restored)
+	}
+
+	vault.secrets["prod"].versions[1].deleted = true
+	if _, err := svc.RestoreVersion("prod", 2); err == nil {
+		t.Error("expected an error restoring a deleted version")
+	}
+}
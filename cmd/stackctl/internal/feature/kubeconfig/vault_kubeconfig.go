@@ -73,43 +73,97 @@ func NewVaultKubeconfigService(client *envvault.Client, opts ...VaultKubeconfigO
 	return svc
 }
 
+// SecretKey returns the field the kubeconfig is stored under.
+func (s *VaultKubeconfigService) SecretKey() string {
+	return s.secretKey
+}
+
+// SecretPath returns the data path of a secret name, or name itself when it is
+// already a full path under the KV mount (e.g. secret/data/...). Other names
+// may contain "/", as EKS ARNs do, and are looked up under the base path.
+func (s *VaultKubeconfigService) SecretPath(name string) string {
+	if strings.HasPrefix(name, s.dataMount()) {
+		return name
+	}
+	return s.dataBase + "/" + name
+}
+
+// dataMount returns the "<mount>/data/" prefix of the data base path.
+func (s *VaultKubeconfigService) dataMount() string {
+	if i := strings.Index(s.dataBase+"/", "/data/"); i >= 0 {
+		return s.dataBase[:i] + "/data/"
+	}
+	return s.dataBase + "/"
+}
+
 // RemoteKubeconfig represents a kubeconfig stored in Vault with its
 // associated metadata for display purposes.
 type RemoteKubeconfig struct {
 	// SecretName is the Vault secret name (last path segment).
-	SecretName string
+	SecretName string `json:"name"`
 	// DataPath is the full Vault data path to the secret.
-	DataPath string
+	DataPath string `json:"dataPath"`
 	// ContextNames contains the Kubernetes context names found in the kubeconfig.
-	ContextNames []string
+	ContextNames []string `json:"contexts"`
+	// Tags and Description come from the KV v2 custom metadata.
+	Tags        map[string]string `json:"tags,omitempty"`
+	Description string            `json:"description,omitempty"`
 }
 
 // SaveContextToVault extracts a local kubeconfig context, encodes it as base64,
 // and writes it to Vault at the specified secret name under the configured base path.
 func (s *VaultKubeconfigService) SaveContextToVault(kubeconfigPath, contextName, secretName string) error {
-	encodedConfig, err := GetEncodedContextConfig(kubeconfigPath, contextName)
+	return s.SaveContextToVaultWithOptions(kubeconfigPath, contextName, secretName, SaveOptions{})
+}
+
+// SaveContextToVaultWithOptions is SaveContextToVault that also sets tags and
+// a description as KV v2 custom metadata.
+func (s *VaultKubeconfigService) SaveContextToVaultWithOptions(kubeconfigPath, contextName, secretName string, opts SaveOptions) error {
+	config, err := Load(kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	singleConfig, err := extractContext(config, contextName)
 	if err != nil {
 		return fmt.Errorf("failed to extract context config: %w", err)
 	}
 
-	dataPath := s.dataBase + "/" + secretName
-	data := map[string]interface{}{
-		s.secretKey: encodedConfig,
+	log.Infof("📝 Saving context '%s' to Vault at %s (key: %s)", contextName, s.dataBase+"/"+secretName, s.secretKey)
+	if err := s.saveConfig(singleConfig, secretName, opts); err != nil {
+		return err
 	}
 
-	log.Infof("📝 Saving context '%s' to Vault at %s (key: %s)", contextName, dataPath, s.secretKey)
+	log.Infof("✅ Context '%s' saved to Vault as '%s'", contextName, secretName)
+	return nil
+}
+
+// saveConfig writes config as a new version of secretName and applies the
+// tags and description of opts.
+func (s *VaultKubeconfigService) saveConfig(config *Config, secretName string, opts SaveOptions) error {
+	encodedConfig, err := encodeConfig(config)
+	if err != nil {
+		return err
+	}
 
-	if err := s.client.WriteSecret(dataPath, data); err != nil {
+	data := map[string]interface{}{
+		s.secretKey: encodedConfig,
+	}
+	if err := s.client.WriteSecret(s.dataBase+"/"+secretName, data); err != nil {
 		return fmt.Errorf("failed to write secret to Vault: %w", err)
 	}
 
-	log.Infof("✅ Context '%s' saved to Vault as '%s'", contextName, secretName)
+	if !opts.IsZero() {
+		if err := s.UpdateMetadata(secretName, opts); err != nil {
+			return fmt.Errorf("failed to tag secret: %w", err)
+		}
+	}
 	return nil
 }
 
 // ListRemoteKubeconfigs lists all kubeconfig secrets stored in Vault under the
 // configured base path. For each secret, it reads the KUBECONFIG field,
-// decodes the base64 content, and extracts the context names.
+// decodes the base64 content, and extracts the context names, along with the
+// tags and description from the custom metadata.
 func (s *VaultKubeconfigService) ListRemoteKubeconfigs() ([]RemoteKubeconfig, error) {
 	if s.client == nil {
 		return nil, fmt.Errorf("failed to list secrets")
@@ -133,12 +187,20 @@ func (s *VaultKubeconfigService) ListRemoteKubeconfigs() ([]RemoteKubeconfig, er
 			log.Warnf("⚠️  Could not parse kubeconfig from %s: %v", cleanKey, err)
 		}
 
+		custom, err := s.readCustomMetadata(cleanKey)
+		if err != nil {
+			log.Warnf("⚠️  Could not read metadata of %s: %v", cleanKey, err)
+		}
+		tags, description := splitDescription(custom)
+
 		// Always include the secret in results, even if parsing failed.
 		// Secrets that failed to parse will have empty ContextNames.
 		results = append(results, RemoteKubeconfig{
 			SecretName:   cleanKey,
 			DataPath:     dataPath,
 			ContextNames: contextNames,
+			Tags:         tags,
+			Description:  description,
 		})
 	}
 
@@ -148,6 +210,12 @@ func (s *VaultKubeconfigService) ListRemoteKubeconfigs() ([]RemoteKubeconfig, er
 // FetchKubeconfigFromVault reads a kubeconfig secret from Vault, decodes it,
 // and merges it into the local kubeconfig file.
 func (s *VaultKubeconfigService) FetchKubeconfigFromVault(dataPath, localKubeconfigPath, resourceName string) error {
+	return s.FetchKubeconfigFromVaultWithOptions(dataPath, localKubeconfigPath, ImportOptions{Name: resourceName})
+}
+
+// FetchKubeconfigFromVaultWithOptions is FetchKubeconfigFromVault with conflict
+// handling and dry-run support.
+func (s *VaultKubeconfigService) FetchKubeconfigFromVaultWithOptions(dataPath, localKubeconfigPath string, opts ImportOptions) error {
 	log.Infof("🔍 Reading kubeconfig from Vault: %s (field: %s)", dataPath, s.secretKey)
 
 	encodedConfig, err := s.readSecretFieldValue(dataPath)
@@ -165,29 +233,14 @@ func (s *VaultKubeconfigService) FetchKubeconfigFromVault(dataPath, localKubecon
 		return fmt.Errorf("failed to parse kubeconfig: %w", err)
 	}
 
-	if resourceName != "" {
-		renameConfigComponents(&newConfig, resourceName)
+	if opts.Name != "" {
+		renameConfigComponents(&newConfig, opts.Name)
 	}
 
-	existingConfig, err := Load(localKubeconfigPath)
-	if err != nil && !isNotExist(err) {
-		return fmt.Errorf("failed to load existing kubeconfig: %w", err)
-	}
-
-	if existingConfig != nil {
-		backupPath, err := Backup(localKubeconfigPath)
-		if err != nil {
-			log.Warnf("⚠️  Failed to create backup: %v", err)
-		} else {
-			log.Infof("📦 Backed up existing kubeconfig to: %s", backupPath)
-		}
-	}
-
-	mergedConfig := Merge(existingConfig, &newConfig)
-
-	if err := Save(localKubeconfigPath, mergedConfig); err != nil {
-		return fmt.Errorf("failed to save kubeconfig: %w", err)
+	if _, err := importConfig(localKubeconfigPath, &newConfig, opts); err != nil || opts.DryRun {
+		return err
 	}
+	warnLint(&newConfig)
 
 	log.Infof("✅ Kubeconfig merged successfully from Vault")
 	return nil
@@ -195,7 +248,8 @@ func (s *VaultKubeconfigService) FetchKubeconfigFromVault(dataPath, localKubecon
 
 // readSecretFieldValue reads a Vault secret and extracts the configured field
 // value as a string. It first tries ReadSecretField (which handles KV v2
-// unwrapping internally), then falls back to ReadSecret with manual unwrapping.
+// unwrapping internally), then falls back to ReadSecret with manual unwrapping
+// and a case-insensitive match on the field name.
 func (s *VaultKubeconfigService) readSecretFieldValue(dataPath string) (string, error) {
 	// Primary: use ReadSecretField which is proven to work in vault_fetch.go
 	val, err := s.client.ReadSecretField(dataPath, s.secretKey)
@@ -217,21 +271,15 @@ func (s *VaultKubeconfigService) readSecretFieldValue(dataPath string) (string,
 		return "", fmt.Errorf("secret not found at %s", dataPath)
 	}
 
-	// Try direct field access first
-	if fieldVal, ok := data[s.secretKey]; ok {
-		if strVal, ok := fieldVal.(string); ok && strVal != "" {
-			return strVal, nil
-		}
+	// Try direct field access first, ignoring the case of the field name
+	if strVal, ok := lookupField(data, s.secretKey); ok {
+		return strVal, nil
 	}
 
 	// KV v2 may return data nested under a "data" key
-	if nested, ok := data["data"]; ok {
-		if nestedMap, ok := nested.(map[string]interface{}); ok {
-			if fieldVal, ok := nestedMap[s.secretKey]; ok {
-				if strVal, ok := fieldVal.(string); ok && strVal != "" {
-					return strVal, nil
-				}
-			}
+	if nestedMap, ok := data["data"].(map[string]interface{}); ok {
+		if strVal, ok := lookupField(nestedMap, s.secretKey); ok {
+			return strVal, nil
 		}
 	}
 
@@ -241,21 +289,11 @@ func (s *VaultKubeconfigService) readSecretFieldValue(dataPath string) (string,
 // extractContextNames reads a Vault secret, decodes the kubeconfig field,
 // and returns the list of context names found in it.
 func (s *VaultKubeconfigService) extractContextNames(dataPath string) ([]string, error) {
-	encodedConfig, err := s.readSecretFieldValue(dataPath)
+	config, err := s.readRemoteConfig(dataPath)
 	if err != nil {
 		return nil, err
 	}
 
-	decodedConfig, err := decodeBase64Config(encodedConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode base64: %w", err)
-	}
-
-	var config Config
-	if err := yaml.Unmarshal(decodedConfig, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
-	}
-
 	names := make([]string, 0, len(config.Contexts))
 	for _, ctx := range config.Contexts {
 		names = append(names, ctx.Name)
@@ -288,6 +326,11 @@ func decodeBase64Config(encoded string) ([]byte, error) {
 // in the config to the given name for consistent naming.
 func renameConfigComponents(config *Config, name string) {
 	log.Infof("🏷️  Renaming configuration components to: %s", name)
+	setComponentNames(config, name)
+}
+
+// setComponentNames is renameConfigComponents without the log line.
+func setComponentNames(config *Config, name string) {
 	for i := range config.Clusters {
 		config.Clusters[i].Name = name
 	}
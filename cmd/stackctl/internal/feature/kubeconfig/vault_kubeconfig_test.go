@@ -2,8 +2,20 @@ package kubeconfig
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/eliasmeireles/envvault"
 )
 
 func TestDecodeBase64Config(t *testing.T) {
@@ -159,3 +171,213 @@ func TestNewVaultKubeconfigService_WithOptions(t *testing.T) {
 		t.Errorf("expected secret key 'MY_KEY', got %q", svc.secretKey)
 	}
 }
+
+// fakeVault is an in-memory KV v2 engine serving one layout that speaks
+// enough of the Vault HTTP API for the envvault client.
+type fakeVault struct {
+	mu      sync.Mutex
+	layout  VaultLayout
+	secrets map[string]*fakeSecret
+	// routes serves other paths, keyed by "METHOD path".
+	routes map[string]http.HandlerFunc
+}
+
+// fakeSecret holds every version of a secret; version n is versions[n-1].
+type fakeSecret struct {
+	versions []fakeVersion
+	custom   map[string]interface{}
+}
+
+type fakeVersion struct {
+	data    map[string]interface{}
+	created time.Time
+	deleted bool
+}
+
+// newFakeVault starts a fakeVault and returns a service authenticated
+// against it with the default paths.
+func newFakeVault(t *testing.T) (*fakeVault, *VaultKubeconfigService) {
+	t.Helper()
+	return newFakeVaultWithLayout(t, DefaultVaultLayout())
+}
+
+// newFakeVaultWithLayout starts a fakeVault serving layout and returns a
+// service configured for it.
+func newFakeVaultWithLayout(t *testing.T, layout VaultLayout) (*fakeVault, *VaultKubeconfigService) {
+	t.Helper()
+	v := &fakeVault{layout: layout, secrets: make(map[string]*fakeSecret), routes: make(map[string]http.HandlerFunc)}
+	server := httptest.NewServer(v)
+	t.Cleanup(server.Close)
+
+	t.Setenv(envvault.EnvVaultToken, "test-token")
+	client := envvault.NewClient(envvault.Config{VaultAddr: server.URL, AuthMethod: envvault.AuthMethodToken})
+	if err := client.Authenticate(); err != nil {
+		t.Fatalf("failed to authenticate: %v", err)
+	}
+	return v, NewVaultKubeconfigService(client, layout.Options()...)
+}
+
+// put stores config as a new version under the layout field as
+// save-to-vault does.
+func (v *fakeVault) put(t *testing.T, name string, config *Config) {
+	t.Helper()
+	v.putField(t, name, v.layout.Field, config)
+}
+
+// putField stores config under an arbitrary field name.
+func (v *fakeVault) putField(t *testing.T, name, field string, config *Config) {
+	t.Helper()
+	encoded, err := encodeConfig(config)
+	if err != nil {
+		t.Fatalf("failed to encode config: %v", err)
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.write(name, map[string]interface{}{field: encoded})
+}
+
+// tag replaces the custom metadata of a stored secret.
+func (v *fakeVault) tag(name string, custom map[string]interface{}) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.secrets[name].custom = custom
+}
+
+// config decodes the latest version of the kubeconfig stored under name.
+func (v *fakeVault) config(t *testing.T, name string) *Config {
+	t.Helper()
+	v.mu.Lock()
+	secret, ok := v.secrets[name]
+	v.mu.Unlock()
+	if !ok {
+		t.Fatalf("secret %s not found", name)
+	}
+	data := secret.versions[len(secret.versions)-1].data
+	encoded, _ := lookupField(data, v.layout.Field)
+	decoded, err := decodeBase64Config(encoded)
+	if err != nil {
+		t.Fatalf("failed to decode secret %s: %v", name, err)
+	}
+	var config Config
+	if err := yaml.Unmarshal(decoded, &config); err != nil {
+		t.Fatalf("failed to parse secret %s: %v", name, err)
+	}
+	return &config
+}
+
+// write appends a version; callers hold the lock.
+func (v *fakeVault) write(name string, data map[string]interface{}) int {
+	secret, ok := v.secrets[name]
+	if !ok {
+		secret = &fakeSecret{}
+		v.secrets[name] = secret
+	}
+	secret.versions = append(secret.versions, fakeVersion{data: data, created: time.Now().UTC()})
+	return len(secret.versions)
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	metadataBase := v.layout.MetadataPath()
+	dataBase := v.layout.DataPath() + "/"
+
+	if route, ok := v.routes[r.Method+" "+path]; ok {
+		route(w, r)
+		return
+	}
+
+	switch {
+	case (r.Method == "LIST" || r.URL.Query().Get("list") == "true") && strings.TrimSuffix(path, "/") == metadataBase:
+		keys := make([]string, 0, len(v.secrets))
+		for name := range v.secrets {
+			keys = append(keys, name)
+		}
+		if len(keys) == 0 {
+			notFound(w)
+			return
+		}
+		sort.Strings(keys)
+		writeJSON(w, map[string]interface{}{"data": map[string]interface{}{"keys": keys}})
+	case r.Method == http.MethodGet && strings.HasPrefix(path, metadataBase+"/"):
+		secret, ok := v.secrets[strings.TrimPrefix(path, metadataBase+"/")]
+		if !ok {
+			notFound(w)
+			return
+		}
+		versions := make(map[string]interface{})
+		for i, version := range secret.versions {
+			deletion := ""
+			if version.deleted {
+				deletion = version.created.Format(time.RFC3339Nano)
+			}
+			versions[strconv.Itoa(i+1)] = map[string]interface{}{
+				"created_time":  version.created.Format(time.RFC3339Nano),
+				"deletion_time": deletion,
+				"destroyed":     false,
+			}
+		}
+		writeJSON(w, map[string]interface{}{"data": map[string]interface{}{
+			"current_version": len(secret.versions),
+			"versions":        versions,
+			"custom_metadata": secret.custom,
+		}})
+	case (r.Method == http.MethodPut || r.Method == http.MethodPost) && strings.HasPrefix(path, metadataBase+"/"):
+		secret, ok := v.secrets[strings.TrimPrefix(path, metadataBase+"/")]
+		if !ok {
+			notFound(w)
+			return
+		}
+		var body struct {
+			CustomMetadata map[string]interface{} `json:"custom_metadata"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		secret.custom = body.CustomMetadata
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, dataBase):
+		secret, ok := v.secrets[strings.TrimPrefix(path, dataBase)]
+		if !ok {
+			notFound(w)
+			return
+		}
+		n := len(secret.versions)
+		if value := r.URL.Query().Get("version"); value != "" {
+			n, _ = strconv.Atoi(value)
+		}
+		if n < 1 || n > len(secret.versions) || secret.versions[n-1].deleted {
+			notFound(w)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"data": map[string]interface{}{
+			"data":     secret.versions[n-1].data,
+			"metadata": map[string]interface{}{"version": n},
+		}})
+	case (r.Method == http.MethodPut || r.Method == http.MethodPost) && strings.HasPrefix(path, dataBase):
+		var body struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		version := v.write(strings.TrimPrefix(path, dataBase), body.Data)
+		writeJSON(w, map[string]interface{}{"data": map[string]interface{}{"version": version}})
+	default:
+		http.Error(w, `{"errors":["unsupported path"]}`, http.StatusNotFound)
+	}
+}
+
+func notFound(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNotFound)
+	_, _ = w.Write([]byte(`{"errors":[]}`))
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,149 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/eliasmeireles/stackctl/cmd/stackctl/internal/env"
+)
+
+const (
+	// VaultMountEnvVar is the KV v2 mount holding the kubeconfig secrets.
+	VaultMountEnvVar = "STACK_CTL_KUBECONFIG_VAULT_MOUNT"
+	// VaultBasePathEnvVar is the path of the kubeconfig secrets inside the mount.
+	VaultBasePathEnvVar = "STACK_CTL_KUBECONFIG_VAULT_BASE_PATH"
+	// VaultFieldEnvVar is the secret field holding the base64 kubeconfig.
+	VaultFieldEnvVar = "STACK_CTL_KUBECONFIG_VAULT_FIELD"
+	// ConfigFileEnvVar overrides the location of the stackctl config file.
+	ConfigFileEnvVar = "STACK_CTL_CONFIG"
+
+	// DefaultVaultMount and DefaultVaultBasePath make up the default base paths.
+	DefaultVaultMount    = "secret"
+	DefaultVaultBasePath = "resources/kubeconfig"
+)
+
+// VaultLayout is where kubeconfigs are stored in Vault: the secrets live at
+// <Mount>/data/<BasePath>/<name> and hold the kubeconfig in Field.
+type VaultLayout struct {
+	Mount    string `yaml:"mount"`
+	BasePath string `yaml:"base_path"`
+	Field    string `yaml:"field"`
+}
+
+// DefaultVaultLayout returns the layout used when nothing is configured.
+func DefaultVaultLayout() VaultLayout {
+	return VaultLayout{Mount: DefaultVaultMount, BasePath: DefaultVaultBasePath, Field: DefaultKubeconfigSecretKey}
+}
+
+// Merge returns l with the non-empty fields of other applied.
+func (l VaultLayout) Merge(other VaultLayout) VaultLayout {
+	if other.Mount != "" {
+		l.Mount = other.Mount
+	}
+	if other.BasePath != "" {
+		l.BasePath = other.BasePath
+	}
+	if other.Field != "" {
+		l.Field = other.Field
+	}
+	return l
+}
+
+// DataPath is the KV v2 data base path, e.g. "secret/data/resources/kubeconfig".
+func (l VaultLayout) DataPath() string {
+	return l.join("data")
+}
+
+// MetadataPath is the KV v2 metadata base path.
+func (l VaultLayout) MetadataPath() string {
+	return l.join("metadata")
+}
+
+func (l VaultLayout) join(kind string) string {
+	mount := strings.Trim(l.Mount, "/")
+	path := strings.Trim(l.BasePath, "/")
+	if path == "" {
+		return mount + "/" + kind
+	}
+	return mount + "/" + kind + "/" + path
+}
+
+// Options returns the service options for the layout.
+func (l VaultLayout) Options() []VaultKubeconfigOption {
+	return []VaultKubeconfigOption{
+		WithMetadataBasePath(l.MetadataPath()),
+		WithDataBasePath(l.DataPath()),
+		WithSecretKey(l.Field),
+	}
+}
+
+// stackctlConfig is the part of the stackctl config file read here:
+//
+//	kubeconfig:
+//	  vault:
+//	    mount: secret
+//	    base_path: resources/kubeconfig
+//	    field: KUBECONFIG
+type stackctlConfig struct {
+	Kubeconfig struct {
+		Vault VaultLayout `yaml:"vault"`
+	} `yaml:"kubeconfig"`
+}
+
+// ConfigFilePath returns the stackctl config file: $STACK_CTL_CONFIG or
+// <user config dir>/stackctl/config.yaml.
+func ConfigFilePath() string {
+	if path, ok := env.Get(ConfigFileEnvVar); ok {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "stackctl", "config.yaml")
+}
+
+// LoadVaultLayout resolves the layout from the defaults, the config file and
+// the environment, in increasing order of precedence. Flags are merged on
+// top by the caller.
+func LoadVaultLayout() (VaultLayout, error) {
+	layout := DefaultVaultLayout()
+
+	if path := ConfigFilePath(); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return layout, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		if err == nil {
+			var config stackctlConfig
+			if err := yaml.Unmarshal(data, &config); err != nil {
+				return layout, fmt.Errorf("invalid config file %s: %w", path, err)
+			}
+			layout = layout.Merge(config.Kubeconfig.Vault)
+		}
+	}
+
+	var fromEnv VaultLayout
+	fromEnv.Mount, _ = env.Get(VaultMountEnvVar)
+	fromEnv.BasePath, _ = env.Get(VaultBasePathEnvVar)
+	fromEnv.Field, _ = env.Get(VaultFieldEnvVar)
+	return layout.Merge(fromEnv), nil
+}
+
+// lookupField returns the value of field in data, falling back to a
+// case-insensitive match so "kubeconfig" and "KUBECONFIG" both work.
+func lookupField(data map[string]interface{}, field string) (string, bool) {
+	if value, ok := data[field].(string); ok && value != "" {
+		return value, true
+	}
+	for key, raw := range data {
+		if value, ok := raw.(string); ok && value != "" && strings.EqualFold(key, field) {
+			return value, true
+		}
+	}
+	return "", false
+}
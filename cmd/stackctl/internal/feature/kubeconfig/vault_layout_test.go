@@ -0,0 +1,144 @@
+package kubeconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVaultLayout_Paths(t *testing.T) {
+	layout := DefaultVaultLayout()
+	if layout.MetadataPath() != DefaultVaultKubeconfigBasePath {
+		t.Errorf("expected %s, got %s", DefaultVaultKubeconfigBasePath, layout.MetadataPath())
+	}
+	if layout.DataPath() != DefaultVaultKubeconfigDataBasePath {
+		t.Errorf("expected %s, got %s", DefaultVaultKubeconfigDataBasePath, layout.DataPath())
+	}
+
+	custom := VaultLayout{Mount: "/kv/", BasePath: "/teams/infra/", Field: "config"}
+	if custom.DataPath() != "kv/data/teams/infra" {
+		t.Errorf("unexpected data path %s", custom.DataPath())
+	}
+	if (VaultLayout{Mount: "kv"}).MetadataPath() != "kv/metadata" {
+		t.Error("expected an empty path to store secrets at the mount root")
+	}
+}
+
+func TestLoadVaultLayout(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(ConfigFileEnvVar, config)
+	t.Setenv(VaultMountEnvVar, "")
+	t.Setenv(VaultBasePathEnvVar, "")
+	t.Setenv(VaultFieldEnvVar, "")
+
+	layout, err := LoadVaultLayout()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if layout != DefaultVaultLayout() {
+		t.Errorf("expected the defaults without a config file, got %+v", layout)
+	}
+
+	content := "kubeconfig:\n  vault:\n    mount: kv\n    base_path: clusters\n"
+	if err := os.WriteFile(config, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	layout, err = LoadVaultLayout()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if layout != (VaultLayout{Mount: "kv", BasePath: "clusters", Field: DefaultKubeconfigSecretKey}) {
+		t.Errorf("unexpected layout from config file: %+v", layout)
+	}
+
+	t.Setenv(VaultBasePathEnvVar, "override")
+	t.Setenv(VaultFieldEnvVar, "kubeconfig")
+	layout, err = LoadVaultLayout()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if layout != (VaultLayout{Mount: "kv", BasePath: "override", Field: "kubeconfig"}) {
+		t.Errorf("expected the environment to win over the config file, got %+v", layout)
+	}
+
+	if err := os.WriteFile(config, []byte("kubeconfig: ["), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadVaultLayout(); err == nil {
+		t.Error("expected an error for an invalid config file")
+	}
+}
+
+func TestCustomLayout(t *testing.T) {
+	layout := VaultLayout{Mount: "kv", BasePath: "teams/infra", Field: "config"}
+	vault, svc := newFakeVaultWithLayout(t, layout)
+	vault.put(t, "prod", syncContext("prod", "https://prod:6443"))
+
+	remotes, err := svc.ListRemoteKubeconfigs()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(remotes) != 1 || remotes[0].DataPath != "kv/data/teams/infra/prod" {
+		t.Fatalf("unexpected remotes: %+v", remotes)
+	}
+	if svc.SecretPath("prod") != "kv/data/teams/infra/prod" {
+		t.Errorf("unexpected secret path %s", svc.SecretPath("prod"))
+	}
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := svc.SaveContextToVault(writeConfig(t, path), "prod", "copy"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := vault.secrets["copy"].versions[0].data["config"]; !ok {
+		t.Error("expected the kubeconfig to be stored under the configured field")
+	}
+}
+
+func TestSecretPath_NameWithSlash(t *testing.T) {
+	vault, svc := newFakeVault(t)
+	name := "arn:aws:eks:us-east-1:123456789012:cluster/prod"
+	vault.put(t, name, syncContext("prod", "https://prod:6443"))
+
+	if got := svc.SecretPath(name); got != DefaultVaultKubeconfigDataBasePath+"/"+name {
+		t.Errorf("expected the name under the base path, got %s", got)
+	}
+	if got := svc.SecretPath("secret/data/teams/prod"); got != "secret/data/teams/prod" {
+		t.Errorf("expected a full path to be kept, got %s", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := svc.FetchKubeconfigFromVault(svc.SecretPath(name), path, "prod"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Credential(CredentialOptions{SecretName: svc.SecretPath(name), NoCache: true}); err != nil {
+		t.Errorf("expected the exec plugin path to resolve: %v", err)
+	}
+}
+
+func TestFieldLookupIgnoresCase(t *testing.T) {
+	vault, svc := newFakeVault(t)
+	// Secrets written by `vault fetch` users under a lowercase field.
+	vault.putField(t, "legacy", "kubeconfig", syncContext("legacy", "https://legacy:6443"))
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := svc.FetchKubeconfigFromVault(svc.SecretPath("legacy"), path, "legacy"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	names, err := GetContextNames(path)
+	if err != nil || len(names) != 1 || names[0] != "legacy" {
+		t.Errorf("expected the legacy context to be imported, got %v (%v)", names, err)
+	}
+
+	if _, err := svc.DiffVersions("legacy", 1, 1); err != nil {
+		t.Errorf("expected history to read the lowercase field: %v", err)
+	}
+}
+
+// writeConfig saves a kubeconfig holding a "prod" context and returns its path.
+func writeConfig(t *testing.T, path string) string {
+	t.Helper()
+	if err := Save(path, syncContext("prod", "https://prod:6443")); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	return path
+}
@@ -0,0 +1,129 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DescriptionMetadataKey is the KV v2 custom_metadata key that holds the
+// description of a stored kubeconfig; every other key is a tag.
+const DescriptionMetadataKey = "description"
+
+// SaveOptions sets KV v2 custom metadata on a saved kubeconfig. Tags not
+// mentioned are kept.
+type SaveOptions struct {
+	Tags        map[string]string
+	RemoveTags  []string
+	Description string
+}
+
+// IsZero reports whether the options leave the metadata unchanged.
+func (o SaveOptions) IsZero() bool {
+	return len(o.Tags) == 0 && len(o.RemoveTags) == 0 && o.Description == ""
+}
+
+// ParseTags parses "key=value" tags; "key-" removes the tag.
+func ParseTags(values []string) (map[string]string, []string, error) {
+	tags := make(map[string]string)
+	var remove []string
+	for _, value := range values {
+		if key, ok := strings.CutSuffix(value, "-"); ok && !strings.Contains(value, "=") {
+			if err := validateTagKey(key); err != nil {
+				return nil, nil, err
+			}
+			remove = append(remove, key)
+			continue
+		}
+		key, val, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid tag %q (expected key=value or key- to remove)", value)
+		}
+		if err := validateTagKey(key); err != nil {
+			return nil, nil, err
+		}
+		tags[key] = val
+	}
+	return tags, remove, nil
+}
+
+func validateTagKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("tag key must not be empty")
+	}
+	if key == DescriptionMetadataKey {
+		return fmt.Errorf("tag key '%s' is reserved; use --description", DescriptionMetadataKey)
+	}
+	return nil
+}
+
+// FormatTags renders tags as "k1=v1,k2=v2" sorted by key.
+func FormatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+tags[k])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// UpdateMetadata merges opts into the custom metadata of a secret.
+func (s *VaultKubeconfigService) UpdateMetadata(secretName string, opts SaveOptions) error {
+	custom, err := s.readCustomMetadata(secretName)
+	if err != nil {
+		return err
+	}
+	for k, v := range opts.Tags {
+		custom[k] = v
+	}
+	for _, k := range opts.RemoveTags {
+		delete(custom, k)
+	}
+	if opts.Description != "" {
+		custom[DescriptionMetadataKey] = opts.Description
+	}
+
+	client, err := s.client.VaultClient()
+	if err != nil {
+		return err
+	}
+	metadataPath := s.metadataBase + "/" + secretName
+	if _, err := client.Logical().Write(metadataPath, map[string]interface{}{"custom_metadata": custom}); err != nil {
+		return fmt.Errorf("failed to write metadata at %s: %w", metadataPath, err)
+	}
+	log.Infof("🏷️  Metadata of '%s' updated: %s", secretName, FormatTags(custom))
+	return nil
+}
+
+// readCustomMetadata returns the custom metadata of a secret.
+func (s *VaultKubeconfigService) readCustomMetadata(secretName string) (map[string]string, error) {
+	metadata, err := s.readMetadata(secretName)
+	if err != nil {
+		return nil, err
+	}
+	custom := make(map[string]string)
+	raw, _ := metadata["custom_metadata"].(map[string]interface{})
+	for k, v := range raw {
+		if str, ok := v.(string); ok {
+			custom[k] = str
+		}
+	}
+	return custom, nil
+}
+
+// splitDescription separates the description from the tags.
+func splitDescription(custom map[string]string) (map[string]string, string) {
+	tags := make(map[string]string, len(custom))
+	for k, v := range custom {
+		if k != DescriptionMetadataKey {
+			tags[k] = v
+		}
+	}
+	return tags, custom[DescriptionMetadataKey]
+}
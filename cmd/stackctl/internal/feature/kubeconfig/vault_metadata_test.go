@@ -0,0 +1,118 @@
+package kubeconfig
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tags, remove, err := ParseTags([]string{"env=prod", "owner=platform", "note=a=b", "legacy-"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"env": "prod", "owner": "platform", "note": "a=b"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("expected %v, got %v", want, tags)
+	}
+	if len(remove) != 1 || remove[0] != "legacy" {
+		t.Errorf("expected legacy to be removed, got %v", remove)
+	}
+
+	for _, value := range []string{"env", "=prod", "description=x", "-"} {
+		if _, _, err := ParseTags([]string{value}); err == nil {
+			t.Errorf("ParseTags(%q): expected an error", value)
+		}
+	}
+}
+
+func TestSaveContextToVaultWithOptions_Tags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := Save(path, syncContext("prod", "https://prod:6443")); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	vault, svc := newFakeVault(t)
+
+	opts := SaveOptions{Tags: map[string]string{"env": "prod", "owner": "platform"}, Description: "Production cluster"}
+	if err := svc.SaveContextToVaultWithOptions(path, "prod", "prod", opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Saving again keeps the tags that are not mentioned.
+	opts = SaveOptions{Tags: map[string]string{"env": "production"}, RemoveTags: []string{"owner"}}
+	if err := svc.SaveContextToVaultWithOptions(path, "prod", "prod", opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.SaveContextToVault(path, "prod", "prod"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(vault.secrets["prod"].versions); got != 3 {
+		t.Errorf("expected 3 versions, got %d", got)
+	}
+
+	remotes, err := svc.ListRemoteKubeconfigs()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(remotes) != 1 {
+		t.Fatalf("expected one remote, got %+v", remotes)
+	}
+	if !reflect.DeepEqual(remotes[0].Tags, map[string]string{"env": "production"}) {
+		t.Errorf("unexpected tags: %v", remotes[0].Tags)
+	}
+	if remotes[0].Description != "Production cluster" {
+		t.Errorf("unexpected description: %q", remotes[0].Description)
+	}
+}
+
+func TestSelector(t *testing.T) {
+	tags := map[string]string{"env": "prod", "owner": "platform"}
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"", true},
+		{"env=prod", true},
+		{"env==prod", true},
+		{"env=prod,owner=platform", true},
+		{"env=prod, owner=qa", false},
+		{"env!=staging", true},
+		{"env!=prod", false},
+		{"team!=core", true},
+		{"owner", true},
+		{"team", false},
+		{"team=", false},
+	}
+	for _, tt := range tests {
+		selector, err := ParseSelector(tt.selector)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.selector, err)
+		}
+		if got := selector.Matches(tags); got != tt.want {
+			t.Errorf("%q: expected %v, got %v", tt.selector, tt.want, got)
+		}
+	}
+
+	if _, err := ParseSelector("=prod"); err == nil {
+		t.Error("expected an error for a term without key")
+	}
+}
+
+func TestSync_Selector(t *testing.T) {
+	path, vault, svc := syncFixture(t)
+	vault.tag("drift", map[string]interface{}{"env": "prod"})
+	vault.tag("remote", map[string]interface{}{"env": "staging"})
+
+	selector, _ := ParseSelector("env=prod")
+	report, err := svc.Sync(path, SyncOptions{Direction: SyncPull, Selector: selector})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertStatuses(t, report, map[string]string{"drift": "diverged/pulled"})
+
+	names, _ := GetContextNames(path)
+	if strings.Contains(strings.Join(names, ","), "remote") {
+		t.Errorf("secrets outside the selector must not be pulled, got %v", names)
+	}
+}
@@ -0,0 +1,116 @@
+package sshclient
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// hostKeys verifies server keys against a known_hosts file.
+type hostKeys struct {
+	callback ssh.HostKeyCallback
+	data     []byte
+}
+
+// hostKeyCallback loads path, defaulting to ~/.ssh/known_hosts.
+func hostKeyCallback(path string) (*hostKeys, error) {
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate known_hosts: %w", err)
+		}
+		path = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("known_hosts file %s not found; add the host with ssh-keyscan first", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known_hosts: %w", err)
+	}
+
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid known_hosts file %s: %w", path, err)
+	}
+	return &hostKeys{callback: callback, data: data}, nil
+}
+
+// algorithms returns the host key algorithms known for addr, so the server
+// presents the key type that is on record rather than its preferred one.
+// It returns nil, meaning the defaults, when the host is not listed.
+func (h *hostKeys) algorithms(addr string) []string {
+	host := knownhosts.Normalize(addr)
+	seen := make(map[string]bool)
+	var algos []string
+
+	rest := h.data
+	for len(rest) > 0 {
+		marker, hosts, key, _, next, err := ssh.ParseKnownHosts(rest)
+		if err != nil {
+			break
+		}
+		rest = next
+		if marker != "" || !matchesHost(hosts, host) {
+			continue
+		}
+		for _, algo := range keyAlgorithms(key.Type()) {
+			if !seen[algo] {
+				seen[algo] = true
+				algos = append(algos, algo)
+			}
+		}
+	}
+	return algos
+}
+
+// matchesHost reports whether one of the plain or hashed host patterns of a
+// known_hosts line is host.
+func matchesHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		if pattern == host {
+			return true
+		}
+		if strings.HasPrefix(pattern, "|1|") && matchesHashed(pattern, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesHashed checks a "|1|salt|hash" entry written by HashKnownHosts.
+func matchesHashed(pattern, host string) bool {
+	parts := strings.Split(pattern, "|")
+	if len(parts) != 4 {
+		return false
+	}
+	salt, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha1.New, salt)
+	mac.Write([]byte(host))
+	return bytes.Equal(mac.Sum(nil), want)
+}
+
+// keyAlgorithms maps a public key type to the signature algorithms that can
+// present it.
+func keyAlgorithms(keyType string) []string {
+	if keyType == ssh.KeyAlgoRSA {
+		return []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+	}
+	return []string{keyType}
+}
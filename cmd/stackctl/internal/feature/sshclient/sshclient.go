@@ -0,0 +1,390 @@
+package sshclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/term"
+)
+
+const (
+	// DefaultPort is the standard SSH port.
+	DefaultPort = 22
+	// DefaultTimeout bounds the TCP connect and SSH handshake.
+	DefaultTimeout = 15 * time.Second
+)
+
+// Config describes how to reach a remote host.
+type Config struct {
+	// Host is the target, optionally as "user@host" or "host:port".
+	Host string
+	// User defaults to the user in Host, then to the local user.
+	User string
+	// Port defaults to the port in Host, then to DefaultPort.
+	Port int
+	// IdentityFile is a private key tried before the agent keys.
+	IdentityFile string
+	// KnownHostsFile defaults to ~/.ssh/known_hosts. Hosts must be listed;
+	// unknown hosts and changed keys are rejected.
+	KnownHostsFile string
+	// JumpHost is an optional bastion as "[user@]host[:port]", reached with
+	// the same credentials.
+	JumpHost string
+	// Sudo runs commands through "sudo -n", e.g. to read root-only files.
+	Sudo bool
+	// Timeout defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Target is a parsed "[user@]host[:port]" address.
+type Target struct {
+	User string
+	Host string
+	Port int
+}
+
+// Addr returns the host:port of the target.
+func (t Target) Addr() string {
+	return net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+}
+
+// ParseTarget parses "[user@]host[:port]", filling in defaultUser and
+// defaultPort when they are missing.
+func ParseTarget(spec, defaultUser string, defaultPort int) (Target, error) {
+	t := Target{User: defaultUser, Port: defaultPort}
+	if i := strings.LastIndex(spec, "@"); i >= 0 {
+		t.User, spec = spec[:i], spec[i+1:]
+	}
+
+	t.Host = spec
+	if host, port, err := net.SplitHostPort(spec); err == nil {
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return t, fmt.Errorf("invalid port in '%s'", spec)
+		}
+		t.Host, t.Port = host, p
+	} else {
+		t.Host = strings.Trim(spec, "[]")
+	}
+
+	if t.Host == "" {
+		return t, fmt.Errorf("host is required")
+	}
+	if t.Port == 0 {
+		t.Port = DefaultPort
+	}
+	return t, nil
+}
+
+// Client is an open connection to a remote host.
+type Client struct {
+	client  *ssh.Client
+	closers []func() error
+	sudo    bool
+}
+
+// Target resolves the user, host and port Dial connects to.
+func (cfg Config) Target() (Target, error) {
+	localUser := ""
+	if u, err := user.Current(); err == nil {
+		localUser = u.Username
+	}
+
+	target, err := ParseTarget(cfg.Host, localUser, cfg.Port)
+	if err != nil {
+		return target, err
+	}
+	if cfg.User != "" {
+		target.User = cfg.User
+	}
+	return target, nil
+}
+
+// Dial connects to cfg.Host, through cfg.JumpHost when set.
+func Dial(cfg Config) (*Client, error) {
+	target, err := cfg.Target()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{sudo: cfg.Sudo}
+	auth, closeAuth, err := authMethods(cfg.IdentityFile)
+	if err != nil {
+		return nil, err
+	}
+	c.closers = append(c.closers, closeAuth)
+
+	hostKeys, err := hostKeyCallback(cfg.KnownHostsFile)
+	if err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	clientConfig := func(t Target) *ssh.ClientConfig {
+		return &ssh.ClientConfig{
+			User:              t.User,
+			Auth:              auth,
+			HostKeyCallback:   hostKeys.callback,
+			HostKeyAlgorithms: hostKeys.algorithms(t.Addr()),
+			Timeout:           timeout,
+		}
+	}
+
+	if cfg.JumpHost == "" {
+		client, err := ssh.Dial("tcp", target.Addr(), clientConfig(target))
+		if err != nil {
+			_ = c.Close()
+			return nil, describeDialError(target, err)
+		}
+		c.client = client
+		return c, nil
+	}
+
+	jump, err := ParseTarget(cfg.JumpHost, target.User, DefaultPort)
+	if err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("invalid jump host: %w", err)
+	}
+	log.Infof("🔀 Connecting through jump host %s", jump.Addr())
+	jumpClient, err := ssh.Dial("tcp", jump.Addr(), clientConfig(jump))
+	if err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("jump host: %w", describeDialError(jump, err))
+	}
+	c.closers = append(c.closers, jumpClient.Close)
+
+	conn, err := jumpClient.Dial("tcp", target.Addr())
+	if err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("jump host %s could not reach %s: %w", jump.Addr(), target.Addr(), err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, target.Addr(), clientConfig(target))
+	if err != nil {
+		_ = conn.Close()
+		_ = c.Close()
+		return nil, describeDialError(target, err)
+	}
+	c.client = ssh.NewClient(sshConn, chans, reqs)
+	return c, nil
+}
+
+// ReadFile returns the content of a remote file.
+func (c *Client) ReadFile(path string) ([]byte, error) {
+	out, err := c.Output("cat -- " + shellQuote(path))
+	if err != nil {
+		return nil, describeRunError(path, c.sudo, err)
+	}
+	return out, nil
+}
+
+// Output runs command on the remote host, through sudo when configured, and
+// returns its standard output.
+func (c *Client) Output(command string) ([]byte, error) {
+	session, err := c.client.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session: %w", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	if c.sudo {
+		command = "sudo -n " + command
+	}
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+	if err := session.Run(command); err != nil {
+		return nil, &RunError{Command: command, Stderr: strings.TrimSpace(stderr.String()), Err: err}
+	}
+	return stdout.Bytes(), nil
+}
+
+// RunError is returned when a remote command fails.
+type RunError struct {
+	Command string
+	Stderr  string
+	Err     error
+}
+
+func (e *RunError) Error() string {
+	if strings.Contains(e.Stderr, "password is required") || strings.Contains(e.Stderr, "a terminal is required") {
+		return "sudo requires a password on the remote host; allow passwordless sudo or connect as root"
+	}
+	if e.Stderr != "" {
+		return fmt.Sprintf("'%s' failed: %s", e.Command, e.Stderr)
+	}
+	return fmt.Sprintf("'%s' failed: %v", e.Command, e.Err)
+}
+
+func (e *RunError) Unwrap() error {
+	return e.Err
+}
+
+// Close closes the connection and any jump host or agent connection.
+func (c *Client) Close() error {
+	var errs []error
+	if c.client != nil {
+		errs = append(errs, c.client.Close())
+	}
+	for i := len(c.closers) - 1; i >= 0; i-- {
+		errs = append(errs, c.closers[i]())
+	}
+	return errors.Join(errs...)
+}
+
+// ReadFile connects with cfg, reads path and disconnects.
+func ReadFile(cfg Config, path string) ([]byte, error) {
+	client, err := Dial(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = client.Close() }()
+	return client.ReadFile(path)
+}
+
+// Output connects with cfg, runs command and disconnects.
+func Output(cfg Config, command string) ([]byte, error) {
+	client, err := Dial(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = client.Close() }()
+	return client.Output(command)
+}
+
+// authMethods offers the identity file and the ssh-agent keys. Without an
+// identity file the default unencrypted keys in ~/.ssh are tried as well.
+func authMethods(identityFile string) ([]ssh.AuthMethod, func() error, error) {
+	var signers []ssh.Signer
+	closeAuth := func() error { return nil }
+
+	if identityFile != "" {
+		signer, err := loadIdentity(identityFile, true)
+		if err != nil {
+			return nil, closeAuth, err
+		}
+		signers = append(signers, signer)
+	}
+
+	var agentSigners func() ([]ssh.Signer, error)
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		conn, err := net.Dial("unix", sock)
+		if err != nil {
+			log.Warnf("⚠️  Failed to connect to ssh-agent: %v", err)
+		} else {
+			closeAuth = conn.Close
+			agentSigners = agent.NewClient(conn).Signers
+		}
+	}
+
+	if identityFile == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+				if signer, err := loadIdentity(filepath.Join(home, ".ssh", name), false); err == nil {
+					signers = append(signers, signer)
+				}
+			}
+		}
+	}
+
+	if len(signers) == 0 && agentSigners == nil {
+		return nil, closeAuth, fmt.Errorf("no SSH credentials found: start ssh-agent or pass --identity-file")
+	}
+
+	return []ssh.AuthMethod{ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+		all := append([]ssh.Signer{}, signers...)
+		if agentSigners != nil {
+			if fromAgent, err := agentSigners(); err == nil {
+				all = append(all, fromAgent...)
+			}
+		}
+		return all, nil
+	})}, closeAuth, nil
+}
+
+// loadIdentity parses a private key. Passphrase-protected keys are only
+// unlocked when prompt is set and stdin is a terminal.
+func loadIdentity(path string, prompt bool) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity file: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if !errors.As(err, &missing) {
+		if err != nil {
+			return nil, fmt.Errorf("invalid identity file %s: %w", path, err)
+		}
+		return signer, nil
+	}
+
+	if !prompt || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("identity file %s is passphrase-protected: add it to ssh-agent", path)
+	}
+	fmt.Fprintf(os.Stderr, "Enter passphrase for %s: ", path)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	signer, err = ssh.ParsePrivateKeyWithPassphrase(data, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt identity file %s: %w", path, err)
+	}
+	return signer, nil
+}
+
+// describeDialError turns handshake failures into actionable messages.
+func describeDialError(target Target, err error) error {
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) {
+		if len(keyErr.Want) == 0 {
+			return fmt.Errorf("host %s is not in known_hosts; verify its fingerprint and add it (ssh-keyscan -p %d %s >> ~/.ssh/known_hosts)",
+				target.Host, target.Port, target.Host)
+		}
+		want := keyErr.Want[0]
+		return fmt.Errorf("host key for %s does not match %s:%d; the host may have been reinstalled or the connection intercepted",
+			target.Addr(), want.Filename, want.Line)
+	}
+	if strings.Contains(err.Error(), "unable to authenticate") {
+		return fmt.Errorf("authentication as '%s' to %s failed: check --ssh-user, --identity-file or the keys loaded in ssh-agent", target.User, target.Addr())
+	}
+	return fmt.Errorf("failed to connect to %s: %w", target.Addr(), err)
+}
+
+// describeRunError explains why reading a remote file failed.
+func describeRunError(path string, sudo bool, err error) error {
+	var runErr *RunError
+	if !errors.As(err, &runErr) {
+		return err
+	}
+	switch {
+	case strings.Contains(runErr.Stderr, "Permission denied") && !sudo:
+		return fmt.Errorf("permission denied reading %s: retry with --sudo", path)
+	case strings.Contains(runErr.Stderr, "No such file"):
+		return fmt.Errorf("remote file %s does not exist", path)
+	}
+	return err
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
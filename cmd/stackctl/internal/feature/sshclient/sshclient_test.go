@@ -0,0 +1,323 @@
+package sshclient
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// testServer is an in-process SSH server that serves "cat" and "sudo -n cat"
+// from files and forwards direct-tcpip channels, so it can act as a jump host.
+type testServer struct {
+	addr    string
+	hostKey ssh.Signer
+	// files maps paths to content; paths in rootOnly need sudo.
+	files    map[string]string
+	rootOnly map[string]bool
+}
+
+func newSigner(t *testing.T) (ssh.Signer, ed25519.PrivateKey) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	return signer, key
+}
+
+func startServer(t *testing.T, authorized ssh.PublicKey) *testServer {
+	t.Helper()
+	hostKey, _ := newSigner(t)
+	s := &testServer{
+		hostKey:  hostKey,
+		files:    map[string]string{"/etc/rancher/k3s/k3s.yaml": "kind: Config\n", "/home/dev/config": "user-config\n"},
+		rootOnly: map[string]bool{"/etc/rancher/k3s/k3s.yaml": true},
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown key")
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	s.addr = listener.Addr().String()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, config)
+		}
+	}()
+	return s
+}
+
+func (s *testServer) serve(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		switch newChannel.ChannelType() {
+		case "session":
+			go s.session(newChannel)
+		case "direct-tcpip":
+			go forward(newChannel)
+		default:
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported")
+		}
+	}
+}
+
+func (s *testServer) session(newChannel ssh.NewChannel) {
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { _ = channel.Close() }()
+
+	for req := range requests {
+		if req.Type != "exec" {
+			_ = req.Reply(false, nil)
+			continue
+		}
+		_ = req.Reply(true, nil)
+		command := string(req.Payload[4:])
+
+		sudo := strings.HasPrefix(command, "sudo -n ")
+		path := strings.Trim(strings.TrimPrefix(strings.TrimPrefix(command, "sudo -n "), "cat -- "), "'")
+
+		status := uint32(0)
+		content, ok := s.files[path]
+		switch {
+		case !ok:
+			_, _ = fmt.Fprintf(channel.Stderr(), "cat: %s: No such file or directory\n", path)
+			status = 1
+		case s.rootOnly[path] && !sudo:
+			_, _ = fmt.Fprintf(channel.Stderr(), "cat: %s: Permission denied\n", path)
+			status = 1
+		default:
+			_, _ = io.WriteString(channel, content)
+		}
+		_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+		return
+	}
+}
+
+// forward serves a direct-tcpip channel by dialing the requested address.
+func forward(newChannel ssh.NewChannel) {
+	var payload struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	target, err := net.Dial("tcp", net.JoinHostPort(payload.Host, fmt.Sprint(payload.Port)))
+	if err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		_ = target.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	go func() {
+		_, _ = io.Copy(channel, target)
+		_ = channel.Close()
+	}()
+	_, _ = io.Copy(target, channel)
+	_ = target.Close()
+}
+
+// clientFixture isolates HOME and the agent, writes the client key and
+// returns its path.
+func clientFixture(t *testing.T) (string, ssh.PublicKey) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	signer, key := newSigner(t)
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	keyPath := filepath.Join(home, "id_test")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return keyPath, signer.PublicKey()
+}
+
+func writeKnownHosts(t *testing.T, servers ...*testServer) string {
+	t.Helper()
+	var lines []string
+	for _, s := range servers {
+		lines = append(lines, knownhosts.Line([]string{knownhosts.Normalize(s.addr)}, s.hostKey.PublicKey()))
+	}
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write known_hosts: %v", err)
+	}
+	return path
+}
+
+func TestReadFile(t *testing.T) {
+	keyPath, pub := clientFixture(t)
+	server := startServer(t, pub)
+	cfg := Config{Host: "dev@" + server.addr, IdentityFile: keyPath, KnownHostsFile: writeKnownHosts(t, server)}
+
+	data, err := ReadFile(cfg, "/home/dev/config")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "user-config\n" {
+		t.Errorf("unexpected content: %q", data)
+	}
+
+	_, err = ReadFile(cfg, "/missing")
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected missing file error, got %v", err)
+	}
+}
+
+func TestReadFile_Sudo(t *testing.T) {
+	keyPath, pub := clientFixture(t)
+	server := startServer(t, pub)
+	cfg := Config{Host: server.addr, User: "dev", IdentityFile: keyPath, KnownHostsFile: writeKnownHosts(t, server)}
+
+	_, err := ReadFile(cfg, "/etc/rancher/k3s/k3s.yaml")
+	if err == nil || !strings.Contains(err.Error(), "--sudo") {
+		t.Fatalf("expected a hint to use --sudo, got %v", err)
+	}
+
+	cfg.Sudo = true
+	data, err := ReadFile(cfg, "/etc/rancher/k3s/k3s.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "kind: Config\n" {
+		t.Errorf("unexpected content: %q", data)
+	}
+}
+
+func TestReadFile_JumpHost(t *testing.T) {
+	keyPath, pub := clientFixture(t)
+	target := startServer(t, pub)
+	bastion := startServer(t, pub)
+	cfg := Config{
+		Host:           "dev@" + target.addr,
+		JumpHost:       bastion.addr,
+		IdentityFile:   keyPath,
+		KnownHostsFile: writeKnownHosts(t, target, bastion),
+	}
+
+	data, err := ReadFile(cfg, "/home/dev/config")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "user-config\n" {
+		t.Errorf("unexpected content: %q", data)
+	}
+}
+
+func TestDial_RejectsUnknownAndChangedHostKeys(t *testing.T) {
+	keyPath, pub := clientFixture(t)
+	server := startServer(t, pub)
+	other := startServer(t, pub)
+
+	unknown := writeKnownHosts(t, other)
+	_, err := Dial(Config{Host: "dev@" + server.addr, IdentityFile: keyPath, KnownHostsFile: unknown})
+	if err == nil || !strings.Contains(err.Error(), "not in known_hosts") {
+		t.Errorf("expected unknown host error, got %v", err)
+	}
+
+	// Record the other server's key under this server's address.
+	changed := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(server.addr)}, other.hostKey.PublicKey())
+	if err := os.WriteFile(changed, []byte(line+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write known_hosts: %v", err)
+	}
+	_, err = Dial(Config{Host: "dev@" + server.addr, IdentityFile: keyPath, KnownHostsFile: changed})
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected host key mismatch error, got %v", err)
+	}
+}
+
+func TestDial_AuthenticationFailure(t *testing.T) {
+	keyPath, _ := clientFixture(t)
+	otherKey, _ := newSigner(t)
+	server := startServer(t, otherKey.PublicKey())
+
+	_, err := Dial(Config{Host: "dev@" + server.addr, IdentityFile: keyPath, KnownHostsFile: writeKnownHosts(t, server)})
+	if err == nil || !strings.Contains(err.Error(), "authentication as 'dev'") {
+		t.Errorf("expected authentication error, got %v", err)
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Target
+	}{
+		{"1.2.3.4", Target{User: "me", Host: "1.2.3.4", Port: 22}},
+		{"root@1.2.3.4", Target{User: "root", Host: "1.2.3.4", Port: 22}},
+		{"root@bastion:2222", Target{User: "root", Host: "bastion", Port: 2222}},
+		{"[fd00::1]:2200", Target{User: "me", Host: "fd00::1", Port: 2200}},
+		{"fd00::1", Target{User: "me", Host: "fd00::1", Port: 22}},
+	}
+	for _, tt := range tests {
+		got, err := ParseTarget(tt.spec, "me", 0)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.spec, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %+v, got %+v", tt.spec, tt.want, got)
+		}
+	}
+}
+
+func TestHostKeys_AlgorithmsFromHashedEntry(t *testing.T) {
+	signer, _ := newSigner(t)
+	line := knownhosts.Line([]string{knownhosts.HashHostname("[10.0.0.1]:2222")}, signer.PublicKey())
+	keys := &hostKeys{data: []byte(line + "\n")}
+
+	if algos := keys.algorithms("10.0.0.1:2222"); len(algos) != 1 || algos[0] != ssh.KeyAlgoED25519 {
+		t.Errorf("expected ed25519, got %v", algos)
+	}
+	if algos := keys.algorithms("10.0.0.2:22"); algos != nil {
+		t.Errorf("expected defaults for an unknown host, got %v", algos)
+	}
+}
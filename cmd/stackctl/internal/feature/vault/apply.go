@@ -61,7 +61,7 @@ func NewApplierFromInterfaces(
 }
 
 // Apply executes all operations in the config in the correct order:
-// engines -> auth -> policies -> roles -> secrets.
+// engines -> auth -> policies -> roles -> kubernetes -> secrets.
 func (a *Applier) Apply(cfg *ApplyConfig) error {
 	if cfg.Engines != nil {
 		if err := a.applyEngines(cfg.Engines); err != nil {
@@ -83,6 +83,11 @@ func (a *Applier) Apply(cfg *ApplyConfig) error {
 			return fmt.Errorf("roles: %w", err)
 		}
 	}
+	if cfg.Kubernetes != nil {
+		if err := a.applyKubernetes(cfg.Kubernetes); err != nil {
+			return fmt.Errorf("kubernetes: %w", err)
+		}
+	}
 	if cfg.Secrets != nil {
 		if err := a.applySecrets(cfg.Secrets); err != nil {
 			return fmt.Errorf("secrets: %w", err)
@@ -138,6 +143,12 @@ func EnsureKVEngine(mountPath string) error {
 
 // ensureKVEngine is the internal method that uses the Applier's engine manager.
 func (a *Applier) ensureKVEngine(mountPath string) error {
+	return a.ensureEngine(mountPath, "kv", map[string]string{"version": "2"})
+}
+
+// ensureEngine mounts an engine of engineType at mountPath unless one is
+// already mounted there.
+func (a *Applier) ensureEngine(mountPath, engineType string, options map[string]string) error {
 	mounts, err := a.engines.ListEngines()
 	if err == nil {
 		normalised := strings.TrimRight(mountPath, "/") + "/"
@@ -145,7 +156,7 @@ func (a *Applier) ensureKVEngine(mountPath string) error {
 			return nil
 		}
 	}
-	mountErr := a.engines.MountEngine(mountPath, "kv", "", map[string]string{"version": "2"})
+	mountErr := a.engines.MountEngine(mountPath, engineType, "", options)
 	if mountErr != nil {
 		msg := strings.ToLower(mountErr.Error())
 		if strings.Contains(msg, "path is already in use") ||
@@ -153,7 +164,7 @@ func (a *Applier) ensureKVEngine(mountPath string) error {
 			strings.Contains(msg, "already mounted") {
 			return nil
 		}
-		return fmt.Errorf("ensure kv engine at %q: %w", mountPath, mountErr)
+		return fmt.Errorf("ensure %s engine at %q: %w", engineType, mountPath, mountErr)
 	}
 	return nil
 }
@@ -1,13 +1,14 @@
 package vault
 
 // ApplyConfig represents the full YAML configuration for declarative Vault operations.
-// Execution order: Engines -> Auth -> Policies -> Roles -> Secrets.
+// Execution order: Engines -> Auth -> Policies -> Roles -> Kubernetes -> Secrets.
 type ApplyConfig struct {
-	Secrets  *SecretsConfig  `yaml:"secrets"`
-	Policies *PoliciesConfig `yaml:"policies"`
-	Auth     *AuthConfig     `yaml:"auth"`
-	Engines  *EnginesConfig  `yaml:"engines"`
-	Roles    []RoleConfig    `yaml:"roles"`
+	Secrets    *SecretsConfig          `yaml:"secrets"`
+	Policies   *PoliciesConfig         `yaml:"policies"`
+	Auth       *AuthConfig             `yaml:"auth"`
+	Engines    *EnginesConfig          `yaml:"engines"`
+	Roles      []RoleConfig            `yaml:"roles"`
+	Kubernetes *KubernetesEngineConfig `yaml:"kubernetes"`
 }
 
 // SecretsConfig defines KV v2 secret operations.
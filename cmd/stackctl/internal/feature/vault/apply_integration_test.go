@@ -294,6 +294,56 @@ func TestApplyFullPermission(t *testing.T) {
 		}
 	})
 
+	t.Run("given kubernetes engine roles then mounts engine and manages roles", func(t *testing.T) {
+		mock, applier := newFullApplier()
+
+		requireNoError(t, applier.Apply(&ApplyConfig{
+			Kubernetes: &KubernetesEngineConfig{
+				Config: &KubernetesConnection{KubernetesHost: "https://k8s.example.com:6443"},
+				Roles: []KubernetesEngineRole{
+					{Name: "dev", AllowedKubernetesNamespaces: "team-a,team-b", KubernetesRoleName: "edit", TokenDefaultTTL: "1h"},
+				},
+			},
+		}))
+		eng, ok := mock.GetEngines()[DefaultKubernetesMount]
+		if !ok {
+			t.Fatal("expected the kubernetes engine to be mounted")
+		}
+		if eng.Type != "kubernetes" {
+			t.Errorf("expected type 'kubernetes', got %q", eng.Type)
+		}
+		if config := mock.GetLogical("kubernetes/config"); config == nil || config["kubernetes_host"] != "https://k8s.example.com:6443" {
+			t.Errorf("unexpected engine config: %v", config)
+		}
+		roleData := mock.GetLogical("kubernetes/roles/dev")
+		if roleData == nil {
+			t.Fatal("expected role to exist")
+		}
+		if roleData["kubernetes_role_name"] != "edit" || roleData["allowed_kubernetes_namespaces"] != "team-a,team-b" {
+			t.Errorf("unexpected role data: %v", roleData)
+		}
+
+		requireNoError(t, applier.Apply(&ApplyConfig{
+			Kubernetes: &KubernetesEngineConfig{
+				Roles: []KubernetesEngineRole{{Name: "dev", Action: "delete"}},
+			},
+		}))
+		if mock.GetLogical("kubernetes/roles/dev") != nil {
+			t.Error("expected role to be deleted")
+		}
+	})
+
+	t.Run("given kubernetes role with no parameters then returns error", func(t *testing.T) {
+		_, applier := newFullApplier()
+
+		err := applier.Apply(&ApplyConfig{
+			Kubernetes: &KubernetesEngineConfig{Mount: "k8s", Roles: []KubernetesEngineRole{{Name: "empty"}}},
+		})
+		if err == nil {
+			t.Fatal("expected error for kubernetes role with no parameters")
+		}
+	})
+
 	t.Run("given auth enable with default path then uses type as path", func(t *testing.T) {
 		mock, applier := newFullApplier()
 
@@ -0,0 +1,123 @@
+package vault
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultKubernetesMount is the default mount of the kubernetes secrets engine.
+const DefaultKubernetesMount = "kubernetes"
+
+// KubernetesEngineConfig configures the Vault kubernetes secrets engine,
+// which issues short-lived service-account tokens.
+type KubernetesEngineConfig struct {
+	Mount  string                 `yaml:"mount"`
+	Config *KubernetesConnection  `yaml:"config"`
+	Roles  []KubernetesEngineRole `yaml:"roles"`
+}
+
+// KubernetesConnection is written to <mount>/config.
+type KubernetesConnection struct {
+	KubernetesHost    string `yaml:"kubernetes_host"`
+	KubernetesCACert  string `yaml:"kubernetes_ca_cert"`
+	ServiceAccountJWT string `yaml:"service_account_jwt"`
+	DisableLocalCAJWT bool   `yaml:"disable_local_ca_jwt"`
+}
+
+// KubernetesEngineRole is written to <mount>/roles/<name>. Exactly one of
+// ServiceAccountName, KubernetesRoleName or GeneratedRoleRules decides what
+// the issued token may do.
+type KubernetesEngineRole struct {
+	Name                        string `yaml:"name"`
+	Action                      string `yaml:"action"`
+	AllowedKubernetesNamespaces string `yaml:"allowed_kubernetes_namespaces"`
+	ServiceAccountName          string `yaml:"service_account_name"`
+	KubernetesRoleName          string `yaml:"kubernetes_role_name"`
+	KubernetesRoleType          string `yaml:"kubernetes_role_type"`
+	GeneratedRoleRules          string `yaml:"generated_role_rules"`
+	NameTemplate                string `yaml:"name_template"`
+	TokenDefaultTTL             string `yaml:"token_default_ttl"`
+	TokenMaxTTL                 string `yaml:"token_max_ttl"`
+}
+
+// ---------- kubernetes secrets engine ----------
+
+func (a *Applier) applyKubernetes(k *KubernetesEngineConfig) error {
+	mount := strings.Trim(k.Mount, "/")
+	if mount == "" {
+		mount = DefaultKubernetesMount
+	}
+	if err := a.ensureEngine(mount, "kubernetes", nil); err != nil {
+		return fmt.Errorf("ensure engine: %w", err)
+	}
+
+	if k.Config != nil {
+		data := BuildKubernetesConfigData(*k.Config)
+		if err := a.logical.Write(mount+"/config", data); err != nil {
+			return fmt.Errorf("write config: %w", err)
+		}
+	}
+
+	for _, r := range k.Roles {
+		rolePath := fmt.Sprintf("%s/roles/%s", mount, r.Name)
+
+		action := strings.ToLower(r.Action)
+		if action == "" {
+			action = "add"
+		}
+
+		switch action {
+		case "add", "update":
+			data := BuildKubernetesRoleData(r)
+			if len(data) == 0 {
+				return fmt.Errorf("no parameters for role %q", r.Name)
+			}
+			if err := a.logical.Write(rolePath, data); err != nil {
+				return fmt.Errorf("write role %q: %w", r.Name, err)
+			}
+		case "delete":
+			if err := a.logical.Delete(rolePath); err != nil {
+				return fmt.Errorf("delete role %q: %w", r.Name, err)
+			}
+		default:
+			return fmt.Errorf("unknown action %q for role %q", r.Action, r.Name)
+		}
+	}
+	return nil
+}
+
+// BuildKubernetesConfigData converts a KubernetesConnection into a Vault API data map.
+func BuildKubernetesConfigData(c KubernetesConnection) map[string]interface{} {
+	data := map[string]interface{}{
+		"disable_local_ca_jwt": c.DisableLocalCAJWT,
+	}
+	if c.KubernetesHost != "" {
+		data["kubernetes_host"] = c.KubernetesHost
+	}
+	if c.KubernetesCACert != "" {
+		data["kubernetes_ca_cert"] = c.KubernetesCACert
+	}
+	if c.ServiceAccountJWT != "" {
+		data["service_account_jwt"] = c.ServiceAccountJWT
+	}
+	return data
+}
+
+// BuildKubernetesRoleData converts a KubernetesEngineRole into a Vault API data map.
+func BuildKubernetesRoleData(r KubernetesEngineRole) map[string]interface{} {
+	data := make(map[string]interface{})
+	set := func(key, value string) {
+		if value != "" {
+			data[key] = value
+		}
+	}
+	set("allowed_kubernetes_namespaces", r.AllowedKubernetesNamespaces)
+	set("service_account_name", r.ServiceAccountName)
+	set("kubernetes_role_name", r.KubernetesRoleName)
+	set("kubernetes_role_type", r.KubernetesRoleType)
+	set("generated_role_rules", r.GeneratedRoleRules)
+	set("name_template", r.NameTemplate)
+	set("token_default_ttl", r.TokenDefaultTTL)
+	set("token_max_ttl", r.TokenMaxTTL)
+	return data
+}
@@ -0,0 +1,140 @@
+// Package output prints command results in the format selected with
+// -o/--output, so scripts can consume stackctl without parsing the human
+// output.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats accepted by -o/--output.
+const (
+	JSON       = "json"
+	YAML       = "yaml"
+	Table      = "table"
+	Name       = "name"
+	GoTemplate = "go-template"
+)
+
+// Formats lists the accepted -o values.
+var Formats = []string{JSON, YAML, Table, Name, GoTemplate}
+
+// Options holds the -o/--output and --template flags.
+type Options struct {
+	// Format is empty for the command's human output.
+	Format string
+	// Template is the go-template, from --template or -o go-template=<tmpl>.
+	Template string
+}
+
+// AddFlags registers -o/--output and --template on cmd.
+func AddFlags(cmd *cobra.Command, o *Options) {
+	cmd.Flags().StringVarP(&o.Format, "output", "o", "",
+		fmt.Sprintf("Output format: %s (default: human readable)", strings.Join(Formats, "|")))
+	cmd.Flags().StringVar(&o.Template, "template", "", "Template for -o go-template, applied to the JSON output")
+	_ = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return Formats, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// Validate checks the format and splits "go-template=<tmpl>". Commands call
+// it before doing any work.
+func (o *Options) Validate() error {
+	if tmpl, ok := strings.CutPrefix(o.Format, GoTemplate+"="); ok {
+		o.Format, o.Template = GoTemplate, tmpl
+	}
+	switch o.Format {
+	case "", JSON, YAML, Table, Name:
+		return nil
+	case GoTemplate:
+		if o.Template == "" {
+			return fmt.Errorf("-o go-template requires a template (-o go-template=<tmpl> or --template)")
+		}
+		_, err := template.New("output").Parse(o.Template)
+		return err
+	}
+	return fmt.Errorf("invalid output format %q (expected one of: %s)", o.Format, strings.Join(Formats, ", "))
+}
+
+// Structured reports whether a format was selected. Without one, commands
+// print their human output.
+func (o Options) Structured() bool {
+	return o.Format != ""
+}
+
+// Listing is what a command prints. Items is the stable schema used by json,
+// yaml and go-template; Columns and Rows make the table; Names are printed
+// one per line by -o name.
+type Listing struct {
+	Items   interface{}
+	Columns []string
+	Rows    [][]string
+	Names   []string
+}
+
+// Print writes l to w in the selected format.
+func (o Options) Print(w io.Writer, l Listing) error {
+	switch o.Format {
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(l.Items)
+	case YAML:
+		generic, err := toGeneric(l.Items)
+		if err != nil {
+			return err
+		}
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(generic); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case GoTemplate:
+		tmpl, err := template.New("output").Parse(o.Template)
+		if err != nil {
+			return err
+		}
+		generic, err := toGeneric(l.Items)
+		if err != nil {
+			return err
+		}
+		return tmpl.Execute(w, generic)
+	case Name:
+		for _, name := range l.Names {
+			if _, err := fmt.Fprintln(w, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, strings.Join(l.Columns, "\t"))
+		for _, row := range l.Rows {
+			_, _ = fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	}
+}
+
+// toGeneric converts v to maps and slices keyed by its JSON field names, so
+// yaml and go-template see the same schema as json.
+func toGeneric(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode output: %w", err)
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("failed to encode output: %w", err)
+	}
+	return generic, nil
+}
@@ -0,0 +1,82 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type item struct {
+	Name    string            `json:"name"`
+	Current bool              `json:"current"`
+	Tags    map[string]string `json:"tags,omitempty"`
+}
+
+func testListing() Listing {
+	items := []item{{Name: "dev", Current: true}, {Name: "prod", Tags: map[string]string{"env": "prod"}}}
+	return Listing{
+		Items:   items,
+		Columns: []string{"NAME", "CURRENT"},
+		Rows:    [][]string{{"dev", "true"}, {"prod", "false"}},
+		Names:   []string{"dev", "prod"},
+	}
+}
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		format   string
+		template string
+		expected string
+	}{
+		{JSON, "", "[\n  {\n    \"name\": \"dev\",\n    \"current\": true\n  },\n  {\n    \"name\": \"prod\",\n    \"current\": false,\n    \"tags\": {\n      \"env\": \"prod\"\n    }\n  }\n]\n"},
+		{YAML, "", "- current: true\n  name: dev\n- current: false\n  name: prod\n  tags:\n    env: prod\n"},
+		{Table, "", "NAME  CURRENT\ndev   true\nprod  false\n"},
+		{Name, "", "dev\nprod\n"},
+		{GoTemplate, "{{range .}}{{.name}};{{end}}", "dev;prod;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			opts := Options{Format: tt.format, Template: tt.template}
+			if err := opts.Validate(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := opts.Print(&out, testListing()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf("unexpected output:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	opts := Options{Format: "go-template={{len .}}"}
+	if err := opts.Validate(); err != nil || opts.Format != GoTemplate || opts.Template != "{{len .}}" {
+		t.Errorf("expected the inline template to be split, got %+v (%v)", opts, err)
+	}
+	for _, invalid := range []Options{{Format: "xml"}, {Format: GoTemplate}, {Format: GoTemplate, Template: "{{"}} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("expected an error for %+v", invalid)
+		}
+	}
+	if (Options{}).Structured() {
+		t.Error("expected no format to keep the human output")
+	}
+}
+
+func TestAddFlags(t *testing.T) {
+	var opts Options
+	cmd := &cobra.Command{Use: "list", RunE: func(*cobra.Command, []string) error { return opts.Validate() }}
+	AddFlags(cmd, &opts)
+	cmd.SetArgs([]string{"-o", "go-template", "--template", "{{.}}"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.EqualFold(opts.Format, GoTemplate) || opts.Template != "{{.}}" {
+		t.Errorf("unexpected options %+v", opts)
+	}
+}